@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Adaptive polling defaults
+const (
+	// defaultThrottleFactor is how much slower a target with every
+	// recent poll failing is polled, once adaptive polling is on
+	defaultThrottleFactor = 4.0
+	// failureRateAlpha is the EWMA smoothing factor for the failure rate
+	failureRateAlpha = 0.2
+)
+
+// AdaptiveConfig tightens a target's interval in its expected release
+// windows and relaxes it outside them, slowing down as polls fail
+type AdaptiveConfig struct {
+	// HotWindows are when releases are expected, polled every
+	// MinInterval
+	HotWindows  ScheduleConfig `mapstructure:"hot_windows"`
+	MinInterval time.Duration  `mapstructure:"min_interval"`
+	// MaxInterval applies outside the windows (0 = the normal,
+	// release-model adjusted interval)
+	MaxInterval time.Duration `mapstructure:"max_interval"`
+	// ThrottleFactor stretches the interval by up to this factor as the
+	// recent failure rate (429s, blocks, an open breaker's failed
+	// probes) climbs to 100%. Default 4 with hot windows, else off.
+	ThrottleFactor float64 `mapstructure:"throttle_factor"`
+}
+
+// compile validates the config and returns its hot windows, nil when
+// none are configured
+func (c AdaptiveConfig) compile() (*Schedule, error) {
+	windows, err := c.HotWindows.Compile()
+	if err != nil {
+		return nil, fmt.Errorf("adaptive hot_windows: %w", err)
+	}
+	if windows != nil && c.MinInterval <= 0 {
+		return nil, fmt.Errorf("adaptive: hot_windows need a min_interval")
+	}
+	if c.MaxInterval > 0 && c.MaxInterval < c.MinInterval {
+		return nil, fmt.Errorf("adaptive: max_interval %v is below min_interval %v", c.MaxInterval, c.MinInterval)
+	}
+	return windows, nil
+}
+
+// throttleFactor returns ThrottleFactor or its default
+func (c AdaptiveConfig) throttleFactor() float64 {
+	switch {
+	case c.ThrottleFactor > 0:
+		return max(c.ThrottleFactor, 1)
+	case len(c.HotWindows.Windows) > 0:
+		return defaultThrottleFactor
+	default:
+		return 1
+	}
+}
+
+// windowInterval returns the interval the hot windows set at now, if
+// they set one
+func (t Target) windowInterval(now time.Time) (time.Duration, bool) {
+	if t.hotWindows == nil {
+		return 0, false
+	}
+	if t.hotWindows.Active(now) {
+		return t.Adaptive.MinInterval, true
+	}
+	if t.Adaptive.MaxInterval > 0 {
+		return t.Adaptive.MaxInterval, true
+	}
+	return 0, false
+}
+
+// throttled stretches interval by the target's recent failure rate
+func (t Target) throttled(interval time.Duration, state *TargetState) time.Duration {
+	factor := t.Adaptive.throttleFactor()
+	if factor <= 1 {
+		return interval
+	}
+	return time.Duration(float64(interval) * (1 + state.FailureRate()*(factor-1)))
+}
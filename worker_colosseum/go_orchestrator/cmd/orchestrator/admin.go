@@ -0,0 +1,404 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"colosseo-orchestrator/internal/notify"
+	"colosseo-orchestrator/internal/proxy"
+)
+
+// defaultAcquiredSuppression mutes availability alerts after an acquisition
+const defaultAcquiredSuppression = time.Hour
+
+// AdminConfig for the admin/control HTTP endpoints
+type AdminConfig struct {
+	// Address is the interface/port the admin server binds to, kept
+	// apart from the scrape-facing metrics port
+	Address    string `mapstructure:"address"`
+	Token      string `mapstructure:"token"`
+	HMACSecret string `mapstructure:"hmac_secret"`
+	// MaxSkew is how far a signed request's timestamp may be from now,
+	// either way, before it is rejected as a replay (default 5m)
+	MaxSkew time.Duration `mapstructure:"max_skew"`
+}
+
+// envPlaceholder matches an unexpanded "${VAR}" left in a credential:
+// viper doesn't expand them, so it would be a guessable literal
+var envPlaceholder = regexp.MustCompile(`^\$\{.*\}$`)
+
+// validate rejects credentials still holding an env placeholder; set
+// them through ADMIN_TOKEN and ADMIN_HMAC_SECRET instead
+func (c AdminConfig) validate() error {
+	if envPlaceholder.MatchString(c.Token) {
+		return fmt.Errorf("admin.token is the unexpanded placeholder %q; set ADMIN_TOKEN or leave it empty", c.Token)
+	}
+	if envPlaceholder.MatchString(c.HMACSecret) {
+		return fmt.Errorf("admin.hmac_secret is the unexpanded placeholder %q; set ADMIN_HMAC_SECRET or leave it empty", c.HMACSecret)
+	}
+	return nil
+}
+
+// Headers used for HMAC-signed admin requests
+const (
+	adminTimestampHeader = "X-Timestamp"
+	adminSignatureHeader = "X-Signature"
+)
+
+// Limits on /alerts results
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// maxProbeProxies caps the proxies one /proxies/test request may check
+const maxProbeProxies = 50
+
+// proxyTestRequest is the body of POST /proxies/test
+type proxyTestRequest struct {
+	Proxies []string `json:"proxies"`
+	// Target is requested through each proxy (default: the first
+	// configured target's URL)
+	Target string `json:"target"`
+}
+
+// defaultMaxSkew bounds signed request timestamps when max_skew is unset
+const defaultMaxSkew = 5 * time.Minute
+
+// errClockSkew rejects signed requests with a stale or future timestamp.
+// Unlike other signature failures its reason is returned to the client.
+var errClockSkew = errors.New("request timestamp outside allowed clock skew")
+
+// newAdminMux builds the mux carrying the control and config endpoints
+func newAdminMux(live *liveConfig, registry *StateRegistry, dispatcher *notify.Dispatcher, proxies *proxy.Manager) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/config", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, redactConfig(live.Load()))
+	})
+
+	control := func(action func(*TargetState) error) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			name := r.URL.Query().Get("target")
+			state, ok := registry.Lookup(name)
+			if !ok {
+				http.Error(w, fmt.Sprintf("unknown target: %q", name), http.StatusNotFound)
+				return
+			}
+			if err := action(state); err != nil {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			writeJSON(w, http.StatusOK, state.Status())
+		}
+	}
+
+	mux.HandleFunc("/control/pause", control(func(s *TargetState) error {
+		s.SetPaused(true)
+		return nil
+	}))
+	mux.HandleFunc("/control/resume", control(func(s *TargetState) error {
+		s.SetPaused(false)
+		return nil
+	}))
+	mux.HandleFunc("/control/poll", control(func(s *TargetState) error {
+		if !s.RequestPoll() {
+			return fmt.Errorf("poll already pending")
+		}
+		return nil
+	}))
+
+	// Acquisition outcomes: success mutes availability alerts for the target
+	mux.HandleFunc("/control/acquired", control(func(s *TargetState) error {
+		target, err := findTarget(live.Load().Targets, s.name)
+		if err != nil {
+			return err
+		}
+		acquisitions.WithLabelValues("success").Inc()
+		dur := target.AcquiredSuppression
+		if dur <= 0 {
+			dur = defaultAcquiredSuppression
+		}
+		until := dispatcher.Suppress(s.name, dur)
+		slog.Info("Acquisition reported, availability alerts muted", "target", s.name, "until", until)
+		return nil
+	}))
+	mux.HandleFunc("/control/acquisition-failed", control(func(s *TargetState) error {
+		acquisitions.WithLabelValues("failure").Inc()
+		return nil
+	}))
+	mux.HandleFunc("/control/unsuppress", control(func(s *TargetState) error {
+		if !dispatcher.Unsuppress(s.name) {
+			return fmt.Errorf("no active suppression")
+		}
+		slog.Info("Post-acquisition suppression cleared", "target", s.name)
+		return nil
+	}))
+
+	// Alert audit: /alerts?target=<name>&since=<RFC3339 or duration>&limit=<n>
+	mux.HandleFunc("/alerts", func(w http.ResponseWriter, r *http.Request) {
+		q, err := parseAuditQuery(r.URL.Query(), time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		entries, err := dispatcher.QueryAudit(r.Context(), q)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if entries == nil {
+			entries = []notify.AuditEntry{}
+		}
+		writeJSON(w, http.StatusOK, entries)
+	})
+
+	// Per-proxy health, bans and latency; credentials are redacted, but
+	// hosts and pools still map out the pool, so it stays behind auth
+	mux.HandleFunc("/proxies", func(w http.ResponseWriter, r *http.Request) {
+		stats := []proxy.ProxyHealth{}
+		if proxies != nil {
+			stats = proxies.GetHealthStats()
+		}
+		writeJSON(w, http.StatusOK, stats)
+	})
+
+	// Test-drive candidate proxies against a target without pooling them
+	mux.HandleFunc("/proxies/test", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req proxyTestRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid body: %v", err), http.StatusBadRequest)
+			return
+		}
+		if len(req.Proxies) == 0 || len(req.Proxies) > maxProbeProxies {
+			http.Error(w, fmt.Sprintf("want 1 to %d proxies, got %d", maxProbeProxies, len(req.Proxies)), http.StatusBadRequest)
+			return
+		}
+		if targets := live.Load().Targets; req.Target == "" && len(targets) > 0 {
+			req.Target = targets[0].URL
+		}
+		if u, err := url.Parse(req.Target); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			http.Error(w, fmt.Sprintf("invalid target URL %q", req.Target), http.StatusBadRequest)
+			return
+		}
+
+		slog.Info("Testing proxies", "proxies", len(req.Proxies), "target", req.Target)
+		results := proxy.Probe(r.Context(), req.Proxies, proxy.ProbeOptions{Target: req.Target})
+		writeJSON(w, http.StatusOK, results)
+	})
+
+	return mux
+}
+
+// parseAuditQuery reads the /alerts filters. since is a timestamp or a
+// duration back from now, e.g. "2h".
+func parseAuditQuery(v url.Values, now time.Time) (notify.AuditQuery, error) {
+	q := notify.AuditQuery{Target: v.Get("target"), Limit: defaultAuditLimit}
+	if s := v.Get("since"); s != "" {
+		if d, err := time.ParseDuration(s); err == nil {
+			q.Since = now.Add(-d)
+		} else if t, err := time.Parse(time.RFC3339, s); err == nil {
+			q.Since = t
+		} else {
+			return q, fmt.Errorf("invalid since %q: want RFC3339 or a duration", s)
+		}
+	}
+	if s := v.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return q, fmt.Errorf("invalid limit %q", s)
+		}
+		q.Limit = min(n, maxAuditLimit)
+	}
+	return q, nil
+}
+
+// requireAdminAuth rejects requests that carry neither a valid bearer
+// token nor a valid HMAC signature. With no credentials configured the
+// admin endpoints are locked entirely; an unexpanded "${VAR}" counts as
+// none.
+func requireAdminAuth(cfg AdminConfig, next http.Handler) http.Handler {
+	if envPlaceholder.MatchString(cfg.Token) {
+		cfg.Token = ""
+	}
+	if envPlaceholder.MatchString(cfg.HMACSecret) {
+		cfg.HMACSecret = ""
+	}
+	if cfg.Token == "" && cfg.HMACSecret == "" {
+		slog.Warn("No admin token or HMAC secret configured, admin endpoints are locked")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.Token != "" && validBearer(r, cfg.Token) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if cfg.HMACSecret != "" && r.Header.Get(adminSignatureHeader) != "" {
+			if err := verifyAdminSignature(r, cfg.HMACSecret, cfg.MaxSkew, time.Now()); err != nil {
+				slog.Warn("Admin auth rejected", "method", r.Method, "path", r.URL.Path, "err", err)
+				if errors.Is(err, errClockSkew) {
+					http.Error(w, "unauthorized: "+errClockSkew.Error(), http.StatusUnauthorized)
+					return
+				}
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	})
+}
+
+func validBearer(r *http.Request, token string) bool {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	got := strings.TrimPrefix(auth, "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// verifyAdminSignature checks the X-Signature header, a hex HMAC-SHA256
+// over "<timestamp>.<method>.<request URI>.<body>", where the timestamp
+// is Unix seconds no more than maxSkew from now. The body is restored
+// so handlers can still read it.
+func verifyAdminSignature(r *http.Request, secret string, maxSkew time.Duration, now time.Time) error {
+	ts := r.Header.Get(adminTimestampHeader)
+	if ts == "" {
+		return fmt.Errorf("missing %s header", adminTimestampHeader)
+	}
+	if err := checkClockSkew(ts, maxSkew, now); err != nil {
+		return err
+	}
+
+	sig, err := hex.DecodeString(r.Header.Get(adminSignatureHeader))
+	if err != nil {
+		return fmt.Errorf("malformed signature")
+	}
+
+	var body []byte
+	if r.Body != nil {
+		body, err = io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			return fmt.Errorf("read body: %w", err)
+		}
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if !hmac.Equal(sig, signAdminRequest(secret, ts, r.Method, r.URL.RequestURI(), body)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// checkClockSkew rejects a Unix-seconds timestamp more than maxSkew
+// before or after now. A skew of exactly maxSkew is accepted.
+func checkClockSkew(ts string, maxSkew time.Duration, now time.Time) error {
+	secs, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("malformed %s header", adminTimestampHeader)
+	}
+	if maxSkew <= 0 {
+		maxSkew = defaultMaxSkew
+	}
+
+	// Both sides are wall-clock readings: drop the monotonic one so the
+	// difference isn't mixing clocks
+	skew := now.Round(0).Sub(time.Unix(secs, 0))
+	if skew > maxSkew {
+		return fmt.Errorf("%w: %s old, limit %s", errClockSkew, skew, maxSkew)
+	}
+	if skew < -maxSkew {
+		return fmt.Errorf("%w: %s in the future, limit %s", errClockSkew, -skew, maxSkew)
+	}
+	return nil
+}
+
+// signAdminRequest computes the HMAC a client must send in X-Signature
+func signAdminRequest(secret, timestamp, method, requestURI string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s.%s.%s.", timestamp, method, requestURI)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// redactConfig strips credentials before the config is served
+func redactConfig(cfg MonitorConfig) MonitorConfig {
+	const mask = "REDACTED"
+	if cfg.Telegram.BotToken != "" {
+		cfg.Telegram.BotToken = mask
+	}
+	if cfg.Redis.Password != "" {
+		cfg.Redis.Password = mask
+	}
+	if cfg.Admin.Token != "" {
+		cfg.Admin.Token = mask
+	}
+	if cfg.Admin.HMACSecret != "" {
+		cfg.Admin.HMACSecret = mask
+	}
+	if cfg.Kafka.Password != "" {
+		cfg.Kafka.Password = mask
+	}
+	if cfg.Email.Password != "" {
+		cfg.Email.Password = mask
+	}
+	if cfg.Alerts.WebhookSecret != "" {
+		cfg.Alerts.WebhookSecret = mask
+	}
+	if cfg.Alerts.DiscordWebhookURL != "" {
+		cfg.Alerts.DiscordWebhookURL = mask // the URL embeds the webhook token
+	}
+
+	cfg.ProxyPool.URLs = redactURLs(cfg.ProxyPool.URLs)
+	pools := make(map[string][]string, len(cfg.ProxyPool.Pools))
+	for name, urls := range cfg.ProxyPool.Pools {
+		pools[name] = redactURLs(urls)
+	}
+	cfg.ProxyPool.Pools = pools
+
+	return cfg
+}
+
+// redactURLs masks the credentials in every hop of proxy specs, so a
+// chain doesn't leak the hops after its first
+func redactURLs(raw []string) []string {
+	urls := make([]string, len(raw))
+	for i, r := range raw {
+		urls[i] = proxy.RedactSpec(r)
+	}
+	return urls
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
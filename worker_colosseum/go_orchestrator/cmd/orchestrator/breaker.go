@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"colosseo-orchestrator/internal/notify"
+)
+
+// defaultBreakerCooldown is how long an open breaker holds polls back
+const defaultBreakerCooldown = 10 * time.Minute
+
+// BreakerConfig stops polling a target that keeps failing, instead of
+// burning proxies and deepening a ban. Once Failures polls in a row
+// fail the breaker opens for Cooldown; then a single probe poll decides
+// whether it closes or stays open for another cooldown.
+type BreakerConfig struct {
+	Failures int           `mapstructure:"failures"` // consecutive failures to open (0 disables)
+	Cooldown time.Duration `mapstructure:"cooldown"` // default 10m
+}
+
+func (c BreakerConfig) cooldown() time.Duration {
+	if c.Cooldown <= 0 {
+		return defaultBreakerCooldown
+	}
+	return c.Cooldown
+}
+
+// breakerState is a target's circuit breaker position, exported as the
+// colosseo_breaker_state gauge value
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerHalfOpen
+	breakerOpen
+)
+
+func (b breakerState) String() string {
+	switch b {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// breakerAllows reports whether the target's breaker lets a poll
+// through, logging the probe when its cooldown has just run out
+func breakerAllows(target Target, state *TargetState) bool {
+	if target.Breaker.Failures <= 0 {
+		return true
+	}
+	wait, probe := state.BreakerWait(time.Now())
+	if wait > 0 {
+		return false
+	}
+	if probe {
+		breakerGauge.WithLabelValues(target.Name).Set(float64(breakerHalfOpen))
+		slog.Info("Breaker cooldown over, sending a probe poll", "target", target.Name)
+	}
+	return true
+}
+
+// checkBreaker moves the target's breaker after a poll and warns when
+// it opens or closes. A failed probe reopens it without a new alert.
+func checkBreaker(target Target, state *TargetState, dispatcher *notify.Dispatcher) {
+	cfg := target.Breaker
+	if cfg.Failures <= 0 {
+		return
+	}
+	now := time.Now()
+	from, to := state.UpdateBreaker(cfg.Failures, cfg.cooldown(), now)
+	if from == to {
+		return
+	}
+	breakerGauge.WithLabelValues(target.Name).Set(float64(to))
+	breakerTransitions.WithLabelValues(target.Name, to.String()).Inc()
+
+	var msg string
+	switch {
+	case from == breakerHalfOpen && to == breakerOpen:
+		slog.Warn("Breaker probe failed, reopening", "target", target.Name, "cooldown", cfg.cooldown())
+		return
+	case to == breakerOpen:
+		slog.Warn("Breaker opened, pausing polls", "target", target.Name, "failures", cfg.Failures, "cooldown", cfg.cooldown())
+		msg = fmt.Sprintf("%s failed %d polls in a row; polling paused for %s, then a single probe decides whether it resumes",
+			target.Name, cfg.Failures, cfg.cooldown())
+	default:
+		slog.Info("Breaker closed, polls are getting through again", "target", target.Name)
+		msg = fmt.Sprintf("%s probe poll succeeded; polling resumed", target.Name)
+	}
+
+	if dispatcher == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := dispatcher.Dispatch(ctx, notify.Alert{
+		Level:     notify.Warning,
+		Timestamp: now,
+		Target:    target.Name,
+		Message:   msg,
+		Metadata:  map[string]interface{}{"breaker": to.String()},
+	})
+	if err != nil {
+		slog.Error("Failed to send breaker warning", "target", target.Name, "err", err)
+	}
+}
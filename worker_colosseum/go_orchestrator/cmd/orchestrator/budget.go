@@ -0,0 +1,45 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+)
+
+// BudgetConfig caps the combined poll rate of every target. Each target
+// gets a share of the budget proportional to its priority and never
+// polls faster than that share allows, whatever its own interval,
+// release model or hot mode would choose.
+type BudgetConfig struct {
+	// RequestsPerMinute is the total polls per minute across all
+	// targets (0 = no budget, targets keep their own intervals)
+	RequestsPerMinute float64 `mapstructure:"requests_per_minute"`
+}
+
+// budgetWeight is the target's claim on the budget; targets without a
+// priority weigh as 1
+func budgetWeight(t Target) int {
+	return max(t.Priority, 1)
+}
+
+// allocateBudget divides the budget between targets by priority and
+// records each target's share and the minimum interval it implies.
+// Called at startup and on every config reload.
+func allocateBudget(cfg BudgetConfig, targets []Target, registry *StateRegistry) {
+	total := 0
+	for _, t := range targets {
+		total += budgetWeight(t)
+	}
+
+	for _, t := range targets {
+		state := registry.Get(t.Name)
+		if cfg.RequestsPerMinute <= 0 {
+			state.SetBudget(0, 0)
+			continue
+		}
+		share := float64(budgetWeight(t)) / float64(total)
+		interval := time.Duration(float64(time.Minute) / (cfg.RequestsPerMinute * share))
+		state.SetBudget(interval, share)
+		slog.Info("Budget share allocated", "target", t.Name, "share", share,
+			"requests_per_minute", cfg.RequestsPerMinute, "min_interval", interval.Round(time.Millisecond))
+	}
+}
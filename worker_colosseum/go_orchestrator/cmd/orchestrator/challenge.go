@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+
+	"colosseo-orchestrator/internal/notify"
+	"colosseo-orchestrator/internal/proxy"
+)
+
+// challengeMarkers are body fragments found only on anti-bot challenge
+// pages. Generic CAPTCHA widgets are left out: a booking form may embed
+// one legitimately.
+var challengeMarkers = []struct {
+	vendor string
+	marker []byte
+}{
+	{"datadome", []byte("captcha-delivery.com")},
+	{"cloudflare", []byte("/cdn-cgi/challenge-platform/")},
+	{"cloudflare", []byte("<title>Just a moment...</title>")},
+	{"cloudflare", []byte("<title>Attention Required! | Cloudflare</title>")},
+}
+
+// challengeVendor names the anti-bot vendor whose challenge page r is,
+// or returns "" for a regular response
+func challengeVendor(r *colly.Response) string {
+	if r == nil {
+		return ""
+	}
+	if r.Headers != nil && strings.EqualFold(r.Headers.Get("Cf-Mitigated"), "challenge") {
+		return "cloudflare"
+	}
+	for _, m := range challengeMarkers {
+		if bytes.Contains(r.Body, m.marker) {
+			return m.vendor
+		}
+	}
+	return ""
+}
+
+// handleChallenge treats an anti-bot challenge as a failure of the proxy
+// that got it, not as a result: the proxy is reported failed so rotation
+// moves off it, and availability becomes Uncertain. A Warning goes out
+// when challenges start, since until they stop the monitor is blind.
+func handleChallenge(target Target, state *TargetState, proxies *proxy.Manager, dispatcher *notify.Dispatcher, vendor, via string) {
+	slog.Warn("Challenge served, availability unknown", "target", target.Name, "vendor", vendor, "proxy", displayProxy(via))
+	proxyErrors.WithLabelValues("challenge").Inc()
+
+	if proxies != nil && via != "" {
+		proxies.ReportResult(via, false, 0)
+	}
+
+	state.RecordDetection(0, target.Escalation)
+	state.SetAvailability(AvailabilityUncertain)
+
+	// Warn once per episode
+	if !state.SetChallenge(vendor) || dispatcher == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := dispatcher.Dispatch(ctx, notify.Alert{
+		Level:     notify.Warning,
+		Timestamp: time.Now(),
+		Target:    target.Name,
+		Message: fmt.Sprintf("%s is serving %s challenge pages; availability can't be read until polls get through",
+			target.Name, vendor),
+		Metadata: map[string]interface{}{"challenge": vendor, "proxy": displayProxy(via)},
+	})
+	if err != nil {
+		slog.Error("Failed to send challenge warning", "target", target.Name, "err", err)
+	}
+}
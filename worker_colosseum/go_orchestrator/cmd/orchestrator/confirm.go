@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+
+	"colosseo-orchestrator/internal/notify"
+)
+
+// defaultConfirmTimeout bounds the confirmation poll so a true positive
+// is delayed by at most this much
+const defaultConfirmTimeout = 3 * time.Second
+
+// ConfirmConfig enables an independent second poll before Critical
+type ConfirmConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Timeout time.Duration `mapstructure:"timeout"` // default 3s
+}
+
+// Detection is one alert-worthy outcome, ready to report
+type Detection struct {
+	Target     Target
+	Level      notify.AlertLevel
+	Status     notify.AvailabilityStatus
+	Confidence float64
+	Streak     int
+	Price      *notify.Price
+	Link       string
+	Openings   []string // open JSON entries, with their page
+	Metadata   map[string]interface{}
+}
+
+// confirmDetection re-polls the target with a fresh session and keeps the
+// detection Critical only if both polls agree. On disagreement it is
+// downgraded to Warning, with both results in the metadata.
+func confirmDetection(det Detection) Detection {
+	goal := det.Target.goal()
+	matches, err := confirmVisit(det.Target)
+	second := visitConfidence(goal, matches)
+
+	if det.Metadata == nil {
+		det.Metadata = make(map[string]interface{})
+	}
+	det.Metadata["first_confidence"] = det.Confidence
+	det.Metadata["confirm_confidence"] = second
+	if err != nil {
+		second = 0
+		det.Metadata["confirm_error"] = err.Error()
+	}
+
+	if second < det.Target.Escalation.minConfidence() {
+		confirmationPolls.WithLabelValues(det.Target.Name, "disagree").Inc()
+		slog.Info("Confirmation poll disagreed, downgrading to warning",
+			"target", det.Target.Name, "confidence", det.Confidence, "confirmation", second)
+		det.Level = notify.Warning
+		return det
+	}
+
+	confirmationPolls.WithLabelValues(det.Target.Name, "agree").Inc()
+	return det
+}
+
+// confirmVisit polls the target once from a separate collector: its own
+// user agent and cookie jar, no shared storage, and a tight timeout
+func confirmVisit(target Target) (VisitMatches, error) {
+	timeout := target.Confirm.Timeout
+	if timeout <= 0 {
+		timeout = defaultConfirmTimeout
+	}
+
+	c := colly.NewCollector(
+		colly.UserAgent(randomUserAgent()),
+		colly.AllowURLRevisit(),
+	)
+	c.SetRequestTimeout(timeout)
+
+	for k, v := range target.Headers {
+		key, val := k, v // capture loop vars
+		c.OnRequest(func(r *colly.Request) {
+			r.Headers.Set(key, val)
+		})
+	}
+
+	var (
+		mu       sync.Mutex
+		matches  VisitMatches
+		visitErr error
+	)
+	mark := func(selector string, set func(*VisitMatches)) {
+		if selector == "" {
+			return
+		}
+		c.OnHTML(selector, func(e *colly.HTMLElement) {
+			mu.Lock()
+			set(&matches)
+			mu.Unlock()
+		})
+	}
+	mark(target.Selectors["available"], func(m *VisitMatches) { m.Available = true })
+	mark(target.Selectors["sold_out"], func(m *VisitMatches) { m.SoldOut = true })
+	mark(target.Selectors["goal"], func(m *VisitMatches) { m.Goal = true })
+
+	// JSON targets are confirmed on their first page only, to stay fast
+	if target.JSON.enabled() {
+		c.OnResponse(func(r *colly.Response) {
+			var doc interface{}
+			if json.Unmarshal(r.Body, &doc) != nil {
+				return
+			}
+			entries := jsonLookup(doc, target.JSON.Items)
+			mu.Lock()
+			defer mu.Unlock()
+			for _, entry := range entries {
+				if entryAvailable(entry, target.JSON) {
+					matches.Available = true
+				}
+			}
+			matches.SoldOut = len(entries) > 0 && !matches.Available
+		})
+	}
+
+	c.OnError(func(r *colly.Response, err error) {
+		mu.Lock()
+		visitErr = fmt.Errorf("status %d: %w", r.StatusCode, err)
+		mu.Unlock()
+	})
+
+	if err := c.Visit(target.URL); err != nil {
+		return VisitMatches{}, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return matches, visitErr
+}
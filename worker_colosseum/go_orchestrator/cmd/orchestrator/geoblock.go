@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"colosseo-orchestrator/internal/notify"
+	"colosseo-orchestrator/internal/proxy"
+)
+
+// defaultTargetGeo is the exit geography colosseo.it serves tickets to
+const defaultTargetGeo = "IT"
+
+// geo returns the exit geography the target requires
+func (t Target) geo() string {
+	if t.Geo == "" {
+		return defaultTargetGeo
+	}
+	return t.Geo
+}
+
+// handleGeoBlock treats a region-block page as a proxy failure rather
+// than a sold-out result. The proxy that served it is penalized for the
+// target's geography and the target is re-polled at once, hopefully
+// through a correct-geo proxy; if none is left, a Warning goes out.
+func handleGeoBlock(target Target, state *TargetState, proxies *proxy.Manager, dispatcher *notify.Dispatcher, via string) {
+	geo := target.geo()
+	slog.Warn("Region block served, not counting as sold out", "target", target.Name, "proxy", displayProxy(via))
+	proxyErrors.WithLabelValues("geo_block").Inc()
+	geoBlocks.WithLabelValues(target.Name, displayProxy(via)).Inc()
+
+	if proxies != nil && via != "" {
+		proxies.ReportGeoBlock(via, geo)
+	}
+
+	if proxies != nil && proxies.HasGeo(geo, target.proxyFilter()) {
+		state.SetGeoUnavailable(false)
+		state.RequestPoll()
+		return
+	}
+
+	// Warn once per outage; retrying can't help until a proxy recovers
+	if !state.SetGeoUnavailable(true) || dispatcher == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := dispatcher.Dispatch(ctx, notify.Alert{
+		Level:     notify.Warning,
+		Timestamp: time.Now(),
+		Target:    target.Name,
+		Message:   fmt.Sprintf("%s is region-blocked and no healthy %s proxy is available", target.Name, geo),
+		Metadata:  map[string]interface{}{"geo": geo, "proxy": displayProxy(via)},
+	})
+	if err != nil {
+		slog.Error("Failed to send geo-block warning", "target", target.Name, "err", err)
+	}
+}
+
+// displayProxy names the proxy a response came through, without credentials
+func displayProxy(via string) string {
+	if via == "" {
+		return "direct"
+	}
+	if u, err := url.Parse(proxy.ExitOf(via)); err == nil {
+		return u.Host
+	}
+	return "unknown"
+}
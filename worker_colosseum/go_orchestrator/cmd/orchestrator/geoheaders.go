@@ -0,0 +1,30 @@
+package main
+
+import "net/http"
+
+// defaultGeoHeaders keeps Accept-Language consistent with the exit
+// country, so an Italian page isn't requested in German from Germany
+var defaultGeoHeaders = map[string]map[string]string{
+	"IT": {"Accept-Language": "it-IT,it;q=0.9,en-US;q=0.8,en;q=0.7"},
+	"DE": {"Accept-Language": "de-DE,de;q=0.9,en-US;q=0.8,en;q=0.7"},
+	"FR": {"Accept-Language": "fr-FR,fr;q=0.9,en-US;q=0.8,en;q=0.7"},
+}
+
+// geoHeaders returns the headers for requests exiting in geo: the
+// target's geo_headers for that country, else the built-in defaults
+func (t Target) geoHeaders(geo string) map[string]string {
+	if h, ok := t.GeoHeaders[geo]; ok {
+		return h
+	}
+	return defaultGeoHeaders[geo]
+}
+
+// setGeoHeaders makes req's headers match geo, the country of the
+// proxy it was given, overriding static target headers. Proxy selection
+// is the last step before sending, so this is the first point the exit
+// geography is known ("" or "Unknown" leaves the headers alone).
+func setGeoHeaders(req *http.Request, target Target, geo string) {
+	for k, v := range target.geoHeaders(geo) {
+		req.Header.Set(k, v)
+	}
+}
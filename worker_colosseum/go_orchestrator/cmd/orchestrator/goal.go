@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"colosseo-orchestrator/internal/notify"
+)
+
+// Target goals: which observation is alert-worthy for a target
+const (
+	// GoalAvailable alerts when tickets become available (default)
+	GoalAvailable = "available"
+	// GoalSoldOut alerts when the target sells out, e.g. a returns queue
+	GoalSoldOut = "sold_out"
+	// GoalSelector alerts when the target's "goal" selector matches,
+	// e.g. a "join waitlist" element
+	GoalSelector = "selector"
+)
+
+// VisitMatches records which selectors matched during one visit
+type VisitMatches struct {
+	Available bool
+	SoldOut   bool
+	Goal      bool
+	PriceText string // text of the first "price" selector match
+	DeepLink  string // absolute booking URL from the "deep_link" selector
+	// GeoBlocked is set when the "geo_block" selector matched a region block page
+	GeoBlocked bool
+	Proxy      string // proxy the response came through ("" = direct)
+	// Challenge names the anti-bot vendor whose challenge page was served
+	Challenge string
+	// StatusCode and SizeAnomaly describe the response the selectors ran on
+	StatusCode  int
+	SizeAnomaly bool
+	// Entries and Openings aggregate a JSON target's pages: how many
+	// entries were seen and the labels of the open ones
+	Entries  int
+	Openings []string
+	Language string // page language from <html lang> or the "language" selector
+}
+
+// goal returns the target's configured goal, defaulting to availability
+func (t Target) goal() string {
+	if t.Goal == "" {
+		return GoalAvailable
+	}
+	return t.Goal
+}
+
+// validateGoal checks the goal is known and its selector is configured
+func validateGoal(t Target) error {
+	switch t.goal() {
+	case GoalAvailable, GoalSoldOut:
+		return nil
+	case GoalSelector:
+		if t.Selectors["goal"] == "" {
+			return fmt.Errorf("goal %q requires a 'goal' selector", GoalSelector)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown goal %q (want %s, %s or %s)", t.Goal, GoalAvailable, GoalSoldOut, GoalSelector)
+	}
+}
+
+// goalConfidence is a coarse single-poll confidence that the target's
+// goal was observed. Seeing both the wanted and the opposite selector on
+// one page is a mixed signal.
+func goalConfidence(goal string, m VisitMatches) float64 {
+	var wanted, opposite bool
+	switch goal {
+	case GoalSoldOut:
+		wanted, opposite = m.SoldOut, m.Available
+	case GoalSelector:
+		wanted = m.Goal
+	default:
+		wanted, opposite = m.Available, m.SoldOut
+	}
+
+	switch {
+	case wanted && !opposite:
+		return 1.0
+	case wanted:
+		return 0.6
+	default:
+		return 0
+	}
+}
+
+// visitConfidence scores a poll's evidence for the goal from 0 to 1: the
+// selector evidence of goalConfidence, discounted when the response
+// itself looks off (a 2xx other than 200, or a body size far from the
+// usual). A challenge page is no evidence at all.
+func visitConfidence(goal string, m VisitMatches) float64 {
+	if m.Challenge != "" {
+		return 0
+	}
+	confidence := goalConfidence(goal, m)
+	if m.StatusCode != 0 && m.StatusCode != http.StatusOK {
+		confidence *= 0.8
+	}
+	if m.SizeAnomaly {
+		confidence *= 0.7
+	}
+	return confidence
+}
+
+// goalHeadline words an alert for the target's goal
+func goalHeadline(goal string, level notify.AlertLevel) string {
+	confirmed := level == notify.Critical
+	switch goal {
+	case GoalSoldOut:
+		if confirmed {
+			return "Target Sold Out"
+		}
+		return "Possibly Sold Out"
+	case GoalSelector:
+		if confirmed {
+			return "Watched Element Present"
+		}
+		return "Watched Element Possibly Present"
+	default:
+		if confirmed {
+			return "Tickets Available"
+		}
+		return "Possible Availability"
+	}
+}
+
+// bookingLink returns the extracted booking deep link, falling back to
+// the target URL when the selector is unset or didn't match
+func bookingLink(t Target, m VisitMatches) string {
+	if m.DeepLink != "" {
+		return m.DeepLink
+	}
+	if t.Selectors["deep_link"] != "" {
+		slog.Debug("Deep link not found, falling back to target URL", "target", t.Name)
+	}
+	return t.URL
+}
+
+// goalStatus maps the target's goal to the reported availability
+func goalStatus(goal string) notify.AvailabilityStatus {
+	if goal == GoalSoldOut {
+		return notify.SoldOut
+	}
+	return notify.Available
+}
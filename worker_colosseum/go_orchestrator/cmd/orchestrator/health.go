@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// staleIntervals is how many poll intervals may pass without a
+	// successful poll before a target counts as stale
+	staleIntervals = 10
+	// criticalStaleIntervals is the tighter default for critical targets
+	criticalStaleIntervals = 3
+	// minStaleness keeps short poll intervals from flapping readiness
+	minStaleness = time.Minute
+)
+
+// readiness tracks the subsystems /readyz waits for, set by run as
+// each comes up. The metrics server starts ahead of Redis and the
+// monitors, so probes get an answer while they connect.
+type readiness struct {
+	config     atomic.Bool
+	redis      atomic.Pointer[redis.Client]      // set once connected
+	supervisor atomic.Pointer[monitorSupervisor] // set once monitors start
+	stopping   atomic.Bool                       // shutdown began; stop taking traffic
+}
+
+// readinessReport is the /readyz response body
+type readinessReport struct {
+	Ready        bool          `json:"ready"`
+	Config       string        `json:"config"`
+	Redis        string        `json:"redis"`
+	Monitors     int           `json:"monitors"`
+	Stopping     bool          `json:"stopping,omitempty"`
+	StaleTargets []staleTarget `json:"stale_targets,omitempty"`
+}
+
+// staleTarget names a target whose last successful poll is too old
+type staleTarget struct {
+	Name                  string  `json:"name"`
+	LastSuccessAgeSeconds float64 `json:"last_success_age_seconds"`
+	MaxStalenessSeconds   float64 `json:"max_staleness_seconds"`
+}
+
+// maxStaleness returns the configured staleness threshold, or one
+// derived from the poll interval
+func (t Target) maxStaleness(interval time.Duration) time.Duration {
+	if t.MaxStaleness > 0 {
+		return t.MaxStaleness
+	}
+	if interval <= 0 {
+		interval = t.Timeout
+	}
+	n := staleIntervals
+	if t.Critical {
+		n = criticalStaleIntervals
+	}
+	if d := time.Duration(n) * interval; d > minStaleness {
+		return d
+	}
+	return minStaleness
+}
+
+// livez answers as long as the process serves HTTP. It checks nothing
+// else, so a Redis outage never gets the pod restarted.
+func livez(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
+
+// newReadyHandler reports ready only once the config is loaded, Redis
+// answers and at least one monitor runs, and while every active target
+// has polled successfully within its staleness threshold. Paused
+// targets and targets outside their window are skipped; targets come
+// from the current config, so reloads add and remove them. It turns
+// unready as soon as shutdown begins.
+func newReadyHandler(ready *readiness, live *liveConfig, registry *StateRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := readinessReport{Ready: true, Config: "ok", Redis: "ok"}
+
+		if !ready.config.Load() {
+			report.Ready = false
+			report.Config = "loading"
+		}
+		if ready.stopping.Load() {
+			report.Ready = false
+			report.Stopping = true
+		}
+
+		if redisClient := ready.redis.Load(); redisClient == nil {
+			report.Ready = false
+			report.Redis = "connecting"
+		} else {
+			ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+			err := redisClient.Ping(ctx).Err()
+			cancel()
+			if err != nil {
+				report.Ready = false
+				report.Redis = err.Error()
+			}
+		}
+
+		if supervisor := ready.supervisor.Load(); supervisor != nil {
+			report.Monitors = len(supervisor.Running())
+		}
+		if report.Monitors == 0 {
+			report.Ready = false
+		}
+
+		now := time.Now()
+		for _, target := range live.Load().Targets {
+			state, ok := registry.Lookup(target.Name)
+			if !ok {
+				continue
+			}
+			age, idle := state.SuccessAge(now)
+			if idle {
+				continue
+			}
+			if limit := target.maxStaleness(state.PollInterval()); age > limit {
+				report.Ready = false
+				report.StaleTargets = append(report.StaleTargets, staleTarget{
+					Name:                  target.Name,
+					LastSuccessAgeSeconds: age.Seconds(),
+					MaxStalenessSeconds:   limit.Seconds(),
+				})
+			}
+		}
+
+		status := http.StatusOK
+		if !report.Ready {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, report)
+	}
+}
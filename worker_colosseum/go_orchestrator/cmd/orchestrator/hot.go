@@ -0,0 +1,70 @@
+package main
+
+import "time"
+
+// Hot mode defaults
+const (
+	defaultHotInterval = 2 * time.Second
+	defaultHotHold     = 2 * time.Minute
+	defaultHotMax      = 15 * time.Minute
+)
+
+// HotModeConfig polls a target much faster after a near-miss (a Warning
+// detection) to catch the full release, relaxing after a quiet period
+type HotModeConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is the poll interval while hot (default 2s)
+	Interval time.Duration `mapstructure:"interval"`
+	// CoolInterval is the interval once hot mode ends (default: the
+	// target's normal, release-model adjusted interval)
+	CoolInterval time.Duration `mapstructure:"cool_interval"`
+	// Hold keeps the target hot this long after the last near-miss (default 2m)
+	Hold time.Duration `mapstructure:"hold"`
+	// Max bounds one hot spell, so persistent noise can't keep the
+	// target hot forever; afterwards near-misses are ignored for as long
+	// again (default 15m)
+	Max time.Duration `mapstructure:"max"`
+}
+
+func (c HotModeConfig) interval() time.Duration {
+	if c.Interval <= 0 {
+		return defaultHotInterval
+	}
+	return c.Interval
+}
+
+func (c HotModeConfig) hold() time.Duration {
+	if c.Hold <= 0 {
+		return defaultHotHold
+	}
+	return c.Hold
+}
+
+func (c HotModeConfig) max() time.Duration {
+	if c.Max <= 0 {
+		return defaultHotMax
+	}
+	return c.Max
+}
+
+// pollInterval returns the target's effective interval at now: hot,
+// set by its adaptive windows, cooling down after hot mode, or the
+// release model's, stretched while polls fail and never faster than its
+// share of the global budget
+func (t Target) pollInterval(state *TargetState, now time.Time) time.Duration {
+	return max(t.throttled(t.ownInterval(state, now), state), state.BudgetInterval())
+}
+
+// ownInterval is the interval the target would choose by itself
+func (t Target) ownInterval(state *TargetState, now time.Time) time.Duration {
+	if t.HotMode.Enabled && state.Hot(now) {
+		return t.HotMode.interval()
+	}
+	if interval, ok := t.windowInterval(now); ok {
+		return interval
+	}
+	if t.HotMode.Enabled && t.HotMode.CoolInterval > 0 {
+		return t.HotMode.CoolInterval
+	}
+	return t.ReleaseModel.Interval(t.Timeout, state.ReleaseProfile(), now)
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+)
+
+const (
+	// defaultMaxPages bounds a paginated poll when max_pages is unset
+	defaultMaxPages = 10
+	// maxPagesLimit is the hard cap on pages walked in one poll
+	maxPagesLimit = 50
+
+	// Pagination modes
+	PaginateCursor = "cursor"
+	PaginateOffset = "offset"
+
+	// jsonPageKey carries the page number in the request context
+	jsonPageKey = "json_page"
+)
+
+// JSONConfig polls a JSON API instead of scraping HTML. Paths use a
+// JSONPath subset: "$.a.b" walks objects and "[*]" (or "[]") fans out
+// over arrays.
+type JSONConfig struct {
+	// Items is the path to the entries on each page, e.g. "$.data.dates"
+	Items string `mapstructure:"items"`
+	// Available is the path, relative to an entry, of its availability
+	// field. The entry is open when it equals AvailableValue, or is
+	// truthy when no value is set.
+	Available      string `mapstructure:"available"`
+	AvailableValue string `mapstructure:"available_value"`
+	// Label is the relative path naming an entry in alerts, e.g. "date"
+	Label      string           `mapstructure:"label"`
+	Pagination PaginationConfig `mapstructure:"pagination"`
+}
+
+// PaginationConfig walks every page of a JSON target within one poll
+type PaginationConfig struct {
+	// Mode is "cursor" or "offset"; empty fetches a single page
+	Mode string `mapstructure:"mode"`
+	// Cursor is the path to the next-page token; a missing or empty
+	// token ends the walk. It is sent as the CursorParam query parameter.
+	Cursor      string `mapstructure:"cursor"`
+	CursorParam string `mapstructure:"cursor_param"`
+	// OffsetParam and LimitParam page by offset; a page with fewer than
+	// Limit entries is the last
+	OffsetParam string `mapstructure:"offset_param"`
+	LimitParam  string `mapstructure:"limit_param"`
+	Limit       int    `mapstructure:"limit"`
+	// MaxPages bounds the walk (default 10, at most 50)
+	MaxPages int `mapstructure:"max_pages"`
+}
+
+// enabled reports whether the target polls JSON
+func (c JSONConfig) enabled() bool {
+	return c.Items != ""
+}
+
+// maxPages returns MaxPages or its default
+func (p PaginationConfig) maxPages() int {
+	if p.MaxPages <= 0 {
+		return defaultMaxPages
+	}
+	return p.MaxPages
+}
+
+// validateJSON checks a JSON target's paths and pagination at load
+func validateJSON(t Target) error {
+	cfg := t.JSON
+	if !cfg.enabled() {
+		return nil
+	}
+	for name, path := range map[string]string{"items": cfg.Items, "available": cfg.Available, "label": cfg.Label} {
+		if _, err := parseJSONPath(path); err != nil {
+			return fmt.Errorf("json.%s: %w", name, err)
+		}
+	}
+	if cfg.Available == "" {
+		return fmt.Errorf("json.available is required")
+	}
+
+	p := cfg.Pagination
+	switch p.Mode {
+	case "":
+		return nil
+	case PaginateCursor:
+		if _, err := parseJSONPath(p.Cursor); err != nil || p.Cursor == "" {
+			return fmt.Errorf("json.pagination.cursor: a path is required for cursor mode")
+		}
+		if p.CursorParam == "" {
+			return fmt.Errorf("json.pagination.cursor_param is required for cursor mode")
+		}
+	case PaginateOffset:
+		if p.OffsetParam == "" || p.Limit <= 0 {
+			return fmt.Errorf("json.pagination: offset mode needs offset_param and a positive limit")
+		}
+	default:
+		return fmt.Errorf("json.pagination.mode %q (want %s or %s)", p.Mode, PaginateCursor, PaginateOffset)
+	}
+	if p.MaxPages > maxPagesLimit {
+		return fmt.Errorf("json.pagination.max_pages %d exceeds the limit of %d", p.MaxPages, maxPagesLimit)
+	}
+	// Page URLs recur every poll, so visited tracking would skip them
+	if t.VisitedTTL > 0 {
+		return fmt.Errorf("json.pagination cannot be combined with visited_ttl")
+	}
+	return nil
+}
+
+// registerJSON parses JSON responses page by page, following pagination
+// through the collector so every page goes through its rate limit. The
+// visit outcome is handled once the last page is in.
+func registerJSON(c *colly.Collector, target Target, state *TargetState, finish func()) {
+	cfg := target.JSON
+	c.OnResponse(func(r *colly.Response) {
+		page := 1
+		if n, ok := r.Ctx.GetAny(jsonPageKey).(int); ok {
+			page = n
+		}
+
+		var doc interface{}
+		if err := json.Unmarshal(r.Body, &doc); err != nil {
+			slog.Warn("Page is not JSON", "target", target.Name, "page", page, "err", err)
+			return
+		}
+
+		entries := jsonLookup(doc, cfg.Items)
+		var openings []string
+		for i, entry := range entries {
+			if !entryAvailable(entry, cfg) {
+				continue
+			}
+			label := ""
+			if cfg.Label != "" {
+				label = jsonString(firstOf(jsonLookup(entry, cfg.Label)))
+			}
+			if label == "" {
+				label = "#" + strconv.Itoa(i+1)
+			}
+			openings = append(openings, fmt.Sprintf("%s (page %d)", label, page))
+		}
+		state.MarkJSONPage(len(entries), openings)
+
+		next, err := nextPageURL(r.Request.URL, doc, len(entries), cfg.Pagination)
+		switch {
+		case err != nil:
+			slog.Warn("Pagination stopped", "target", target.Name, "page", page, "err", err)
+		case next != "" && page >= cfg.Pagination.maxPages():
+			slog.Warn("Pagination stopped at the page limit", "target", target.Name, "page", page)
+		case next != "":
+			r.Ctx.Put(jsonPageKey, page+1)
+			if err := c.Request("GET", next, nil, r.Ctx, nil); err != nil {
+				slog.Warn("Failed to fetch page", "target", target.Name, "page", page+1, "err", err)
+				break
+			}
+			return
+		}
+		finish()
+	})
+}
+
+// nextPageURL returns the URL of the page after current, or "" on the last page
+func nextPageURL(current *url.URL, doc interface{}, entries int, p PaginationConfig) (string, error) {
+	next := *current
+	query := next.Query()
+
+	switch p.Mode {
+	case PaginateCursor:
+		token := jsonString(firstOf(jsonLookup(doc, p.Cursor)))
+		if token == "" {
+			return "", nil
+		}
+		if token == query.Get(p.CursorParam) {
+			return "", fmt.Errorf("cursor %q repeated", token)
+		}
+		query.Set(p.CursorParam, token)
+	case PaginateOffset:
+		if entries < p.Limit {
+			return "", nil
+		}
+		offset, _ := strconv.Atoi(query.Get(p.OffsetParam))
+		query.Set(p.OffsetParam, strconv.Itoa(offset+p.Limit))
+		if p.LimitParam != "" {
+			query.Set(p.LimitParam, strconv.Itoa(p.Limit))
+		}
+	default:
+		return "", nil
+	}
+
+	next.RawQuery = query.Encode()
+	return next.String(), nil
+}
+
+// entryAvailable reports whether a JSON entry is open
+func entryAvailable(entry interface{}, cfg JSONConfig) bool {
+	for _, v := range jsonLookup(entry, cfg.Available) {
+		if cfg.AvailableValue != "" {
+			if jsonString(v) == cfg.AvailableValue {
+				return true
+			}
+			continue
+		}
+		switch v := v.(type) {
+		case bool:
+			if v {
+				return true
+			}
+		case float64:
+			if v > 0 {
+				return true
+			}
+		case string:
+			if v != "" && v != "false" && v != "0" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonSegment is one step of a parsed path: an object key, or a fan-out
+// over array elements when wildcard is set
+type jsonSegment struct {
+	key      string
+	wildcard bool
+}
+
+// parseJSONPath parses the supported JSONPath subset. An empty path is
+// the document itself.
+func parseJSONPath(path string) ([]jsonSegment, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return nil, nil
+	}
+
+	var segments []jsonSegment
+	for _, part := range strings.Split(path, ".") {
+		key := part
+		wildcard := false
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			if suffix := part[i:]; suffix != "[*]" && suffix != "[]" {
+				return nil, fmt.Errorf("unsupported path element %q (only [*] is supported)", part)
+			}
+			key, wildcard = part[:i], true
+		}
+		if key == "" && !wildcard {
+			return nil, fmt.Errorf("empty path element in %q", path)
+		}
+		if key != "" {
+			segments = append(segments, jsonSegment{key: key})
+		}
+		if wildcard {
+			segments = append(segments, jsonSegment{wildcard: true})
+		}
+	}
+	return segments, nil
+}
+
+// jsonLookup evaluates path against doc. A path ending at an array
+// yields its elements. Malformed paths, rejected at load, yield nothing.
+func jsonLookup(doc interface{}, path string) []interface{} {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil
+	}
+
+	values := []interface{}{doc}
+	for _, seg := range segments {
+		var next []interface{}
+		for _, v := range values {
+			switch {
+			case seg.wildcard:
+				if arr, ok := v.([]interface{}); ok {
+					next = append(next, arr...)
+				}
+			default:
+				if obj, ok := v.(map[string]interface{}); ok {
+					if child, ok := obj[seg.key]; ok {
+						next = append(next, child)
+					}
+				}
+			}
+		}
+		values = next
+	}
+
+	if len(values) == 1 {
+		if arr, ok := values[0].([]interface{}); ok {
+			return arr
+		}
+	}
+	return values
+}
+
+func firstOf(values []interface{}) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
+
+// jsonString renders a scalar JSON value as text ("" for null)
+func jsonString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+
+	"colosseo-orchestrator/internal/notify"
+)
+
+// registerLanguage records the page language: the "language" selector's
+// lang attribute or text when configured, else <html lang>
+func registerLanguage(c *colly.Collector, target Target, state *TargetState) {
+	if target.Language == "" {
+		return
+	}
+	if sel := target.Selectors["language"]; sel != "" {
+		c.OnHTML(sel, func(e *colly.HTMLElement) {
+			lang := e.Attr("lang")
+			if lang == "" {
+				lang = e.Text
+			}
+			state.MarkLanguage(strings.TrimSpace(lang))
+		})
+		return
+	}
+	c.OnHTML("html[lang]", func(e *colly.HTMLElement) {
+		state.MarkLanguage(e.Attr("lang"))
+	})
+}
+
+// languageMatches compares primary language subtags, so "it" matches "it-IT"
+func languageMatches(expected, got string) bool {
+	primary := func(tag string) string {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if i := strings.IndexAny(tag, "-_"); i >= 0 {
+			tag = tag[:i]
+		}
+		return tag
+	}
+	return primary(expected) == primary(got)
+}
+
+// checkLanguage reports whether the visit's page is in the wrong
+// language, which usually means a fallback or error page whose selector
+// matches can't be trusted. A Warning goes out when the mismatch starts.
+// Pages with no detectable language pass.
+func checkLanguage(target Target, state *TargetState, dispatcher *notify.Dispatcher, m VisitMatches) bool {
+	mismatch := target.Language != "" && m.Language != "" && !languageMatches(target.Language, m.Language)
+	if !state.SetLanguageMismatch(mismatch) || !mismatch {
+		return mismatch
+	}
+
+	slog.Warn("Unexpected page language, ignoring selectors", "target", target.Name, "language", m.Language, "expected", target.Language)
+	if dispatcher == nil {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := dispatcher.Dispatch(ctx, notify.Alert{
+		Level:     notify.Warning,
+		Timestamp: time.Now(),
+		Target:    target.Name,
+		Message: fmt.Sprintf("%s served a %q page instead of %q, possibly a fallback page; selectors are not trusted until it recovers",
+			target.Name, m.Language, target.Language),
+		Metadata: map[string]interface{}{
+			"language":          m.Language,
+			"expected_language": target.Language,
+			"available":         m.Available,
+			"sold_out":          m.SoldOut,
+		},
+	})
+	if err != nil {
+		slog.Error("Failed to send language mismatch warning", "target", target.Name, "err", err)
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"colosseo-orchestrator/internal/notify"
+	"colosseo-orchestrator/internal/proxy"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// Latency SLO defaults
+const (
+	defaultLatencyWindow = 5 * time.Minute
+	// latencyMinSamples is how many polls the window needs before the
+	// SLO is judged
+	latencyMinSamples = 5
+)
+
+// LatencySLOConfig warns when a target's p95 poll duration exceeds
+// Threshold over the rolling Window, often the first sign of a block or
+// of the site straining under a release
+type LatencySLOConfig struct {
+	Threshold time.Duration `mapstructure:"threshold"` // p95 bound (0 disables)
+	Window    time.Duration `mapstructure:"window"`    // default 5m
+}
+
+func (c LatencySLOConfig) window() time.Duration {
+	if c.Window <= 0 {
+		return defaultLatencyWindow
+	}
+	return c.Window
+}
+
+// latencySample is one completed poll's duration
+type latencySample struct {
+	at       time.Time
+	duration time.Duration
+}
+
+// percentile returns the p-th percentile (0-1) of the samples by the
+// nearest-rank method, or 0 with none
+func percentile(samples []latencySample, p float64) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	durations := make([]time.Duration, len(samples))
+	for i, s := range samples {
+		durations[i] = s.duration
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	rank := int(math.Ceil(p*float64(len(durations)))) - 1
+	return durations[max(rank, 0)]
+}
+
+// checkLatency records the completed poll's duration and warns once when
+// the target's p95 first breaches its SLO
+func checkLatency(target Target, state *TargetState, dispatcher *notify.Dispatcher) {
+	slo := target.LatencySLO
+	duration, p95, samples := state.ObserveLatency(time.Now(), slo.window())
+	pollDuration.WithLabelValues(target.Name).Observe(duration.Seconds())
+	if slo.Threshold <= 0 {
+		return
+	}
+
+	breached := samples >= latencyMinSamples && p95 > slo.Threshold
+	if !state.SetLatencyBreach(breached) {
+		return
+	}
+	if !breached {
+		slog.Info("p95 poll latency back under SLO", "target", target.Name, "latency", p95.Round(time.Millisecond), "threshold", slo.Threshold)
+		return
+	}
+
+	slog.Warn("p95 poll latency over SLO", "target", target.Name, "latency", p95.Round(time.Millisecond),
+		"threshold", slo.Threshold, "polls", samples, "window", slo.window())
+	if dispatcher == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := dispatcher.Dispatch(ctx, notify.Alert{
+		Level:     notify.Warning,
+		Timestamp: time.Now(),
+		Target:    target.Name,
+		Message: fmt.Sprintf("%s polls are slowing: p95 %s over the last %s exceeds %s; a block or site load may follow",
+			target.Name, p95.Round(time.Millisecond), slo.window(), slo.Threshold),
+		Metadata: map[string]interface{}{
+			"p95_seconds":       p95.Seconds(),
+			"threshold_seconds": slo.Threshold.Seconds(),
+			"samples":           samples,
+		},
+	})
+	if err != nil {
+		slog.Error("Failed to send latency warning", "target", target.Name, "err", err)
+	}
+}
+
+// registerRequestMetrics times every request from send to response, by
+// target and proxy host, and records the size of each response body.
+// Unlike colosseo_poll_duration_seconds it sees each page and retry on
+// its own, so a slow proxy shows before it drags whole polls down.
+func registerRequestMetrics(c *colly.Collector, target Target) {
+	c.OnRequest(func(r *colly.Request) {
+		r.Ctx.Put(requestStartKey(r), time.Now())
+	})
+	observe := func(r *colly.Response) {
+		start, ok := r.Request.Ctx.GetAny(requestStartKey(r.Request)).(time.Time)
+		if !ok {
+			return
+		}
+		requestDuration.WithLabelValues(target.Name, proxyHost(r.Request.ProxyURL)).Observe(time.Since(start).Seconds())
+	}
+	c.OnResponse(func(r *colly.Response) {
+		observe(r)
+		responseBodyBytes.WithLabelValues(target.Name).Set(float64(len(r.Body)))
+	})
+	c.OnError(func(r *colly.Response, err error) {
+		observe(r)
+	})
+}
+
+// requestStartKey keys a request's start time in its context, which the
+// requests of one visit share
+func requestStartKey(r *colly.Request) string {
+	return "request_start_" + strconv.FormatUint(uint64(r.ID), 10)
+}
+
+// proxyHost labels a request by its proxy's host, or "direct"
+func proxyHost(proxyURL string) string {
+	if proxyURL == "" {
+		return "direct"
+	}
+	u, err := url.Parse(proxy.ExitOf(proxyURL))
+	if err != nil || u.Host == "" {
+		return "unknown"
+	}
+	return u.Host
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"io"
+	"math/rand"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+)
+
+// colosseoDomainGlob matches every host the monitors poll
+const colosseoDomainGlob = "*colosseo.it*"
+
+// domainLimiter is a request budget shared by every collector hitting a
+// domain. colly's LimitRule is per collector, so N targets would each
+// get the full parallelism; this caps the aggregate instead. Like a
+// LimitRule, each of the parallel slots is held for the request plus
+// Delay and up to RandomDelay afterwards.
+type domainLimiter struct {
+	glob string
+
+	mu          sync.Mutex
+	slots       chan struct{}
+	delay       time.Duration
+	randomDelay time.Duration
+}
+
+func newDomainLimiter(glob string, parallelism int, delay, randomDelay time.Duration) *domainLimiter {
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+	return &domainLimiter{
+		glob:        glob,
+		slots:       make(chan struct{}, parallelism),
+		delay:       delay,
+		randomDelay: randomDelay,
+	}
+}
+
+// Resize applies reloaded settings. Requests in flight release their
+// slot in the old budget, so for a moment both may be in use.
+func (l *domainLimiter) Resize(parallelism int, delay, randomDelay time.Duration) {
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if cap(l.slots) != parallelism {
+		l.slots = make(chan struct{}, parallelism)
+	}
+	l.delay, l.randomDelay = delay, randomDelay
+}
+
+// current returns the slot budget new requests take from
+func (l *domainLimiter) current() chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.slots
+}
+
+// matches reports whether host falls under the limiter's domain
+func (l *domainLimiter) matches(host string) bool {
+	ok, _ := path.Match(l.glob, host)
+	return ok
+}
+
+// release frees a slot of slots once the post-request delay has elapsed
+func (l *domainLimiter) release(slots chan struct{}) {
+	l.mu.Lock()
+	wait := l.delay
+	if l.randomDelay > 0 {
+		wait += time.Duration(rand.Int63n(int64(l.randomDelay)))
+	}
+	l.mu.Unlock()
+	time.AfterFunc(wait, func() { <-slots })
+}
+
+// Transport wraps base so requests to the domain take a shared slot
+func (l *domainLimiter) Transport(base http.RoundTripper) http.RoundTripper {
+	return &limitedTransport{base: base, limiter: l}
+}
+
+// limitedTransport holds a limiter slot from before the request is sent
+// until its response body is closed
+type limitedTransport struct {
+	base    http.RoundTripper
+	limiter *domainLimiter
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.limiter.matches(req.URL.Hostname()) {
+		return t.base.RoundTrip(req)
+	}
+
+	slots := t.limiter.current()
+	select {
+	case slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	release := func() { t.limiter.release(slots) }
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releasingBody releases its limiter slot exactly once, on Close
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingTransport answers every request after a short pause, tracking
+// how many responses are open at once
+type countingTransport struct {
+	inFlight atomic.Int32
+	peak     atomic.Int32
+	total    atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	n := t.inFlight.Add(1)
+	for {
+		peak := t.peak.Load()
+		if n <= peak || t.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	t.total.Add(1)
+	time.Sleep(5 * time.Millisecond)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       &closeHook{Reader: strings.NewReader("ok"), close: func() { t.inFlight.Add(-1) }},
+		Request:    req,
+	}, nil
+}
+
+type closeHook struct {
+	io.Reader
+	close func()
+}
+
+func (b *closeHook) Close() error {
+	b.close()
+	return nil
+}
+
+func TestDomainLimiterCapsAggregate(t *testing.T) {
+	const (
+		collectors  = 4
+		perTarget   = 5
+		parallelism = 2
+		delay       = 20 * time.Millisecond
+	)
+	limiter := newDomainLimiter(colosseoDomainGlob, parallelism, delay, 0)
+	base := &countingTransport{}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < collectors; i++ {
+		transport := limiter.Transport(base) // one per collector, as createCollector does
+		for j := 0; j < perTarget; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req, _ := http.NewRequest(http.MethodGet, "https://ticketing.colosseo.it/en/", nil)
+				resp, err := transport.RoundTrip(req)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}()
+		}
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	if got := base.peak.Load(); got > parallelism {
+		t.Errorf("peak in flight = %d across %d collectors, want at most %d", got, collectors, parallelism)
+	}
+	if got := base.total.Load(); got != collectors*perTarget {
+		t.Errorf("sent %d requests, want %d", got, collectors*perTarget)
+	}
+	// 20 requests through 2 slots is 10 rounds, each slot held for the
+	// delay after its request before the next round can start
+	if minimum := (collectors*perTarget/parallelism - 1) * delay; elapsed < minimum {
+		t.Errorf("20 requests took %v, want at least %v at the shared rate", elapsed, minimum)
+	}
+}
+
+func TestDomainLimiterPassesOtherHosts(t *testing.T) {
+	limiter := newDomainLimiter(colosseoDomainGlob, 1, time.Hour, 0)
+	transport := limiter.Transport(&countingTransport{})
+
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+}
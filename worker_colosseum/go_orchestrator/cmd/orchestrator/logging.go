@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// LogConfig selects how the orchestrator logs
+type LogConfig struct {
+	// Level is debug, info, warn or error (default info). It applies on
+	// config reload.
+	Level string `mapstructure:"level"`
+	// Format is "text" (default), readable in a terminal, or "json" for
+	// log aggregators. It takes effect after a restart.
+	Format string `mapstructure:"format"`
+}
+
+// logLevel is the default logger's level, swapped on reload
+var logLevel = new(slog.LevelVar)
+
+// setupLogging installs the default slog logger. Output of the log
+// package goes through it too, at info level.
+func setupLogging(cfg LogConfig) error {
+	if err := setLogLevel(cfg.Level); err != nil {
+		return err
+	}
+
+	opts := &slog.HandlerOptions{Level: logLevel, ReplaceAttr: readableDurations}
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "", "text":
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("log format %q: want text or json", cfg.Format)
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// setLogLevel sets the level by name, empty meaning info
+func setLogLevel(name string) error {
+	level, err := parseLogLevel(name)
+	if err != nil {
+		return err
+	}
+	logLevel.Set(level)
+	return nil
+}
+
+func parseLogLevel(name string) (slog.Level, error) {
+	if name == "" {
+		name = "info"
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("log level %q: want debug, info, warn or error", name)
+	}
+	return level, nil
+}
+
+// readableDurations logs durations as "1.5s" rather than nanoseconds,
+// which JSON would otherwise print
+func readableDurations(_ []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindDuration {
+		a.Value = slog.StringValue(a.Value.Duration().String())
+	}
+	return a
+}
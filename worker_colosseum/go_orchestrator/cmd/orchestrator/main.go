@@ -2,25 +2,37 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"math/rand"
 	"net/http"
 	"os"
 	"os/signal"
+	"runtime/debug"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/gocolly/colly/v2"
 	"github.com/gocolly/colly/v2/extensions"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
+
+	"colosseo-orchestrator/internal/config"
+	"colosseo-orchestrator/internal/notify"
+	"colosseo-orchestrator/internal/proxy"
 )
 
 // MonitorConfig holds all configuration
 type MonitorConfig struct {
+	Version      int           `mapstructure:"version"`
 	Targets      []Target      `mapstructure:"targets"`
 	ProxyPool    ProxyConfig   `mapstructure:"proxy_pool"`
 	Telegram     TelegramConfig `mapstructure:"telegram"`
@@ -29,6 +41,17 @@ type MonitorConfig struct {
 	AsyncThreads int           `mapstructure:"async_threads"`
 	Redis        RedisConfig   `mapstructure:"redis"`
 	MetricsPort  int           `mapstructure:"metrics_port"`
+	Admin        AdminConfig   `mapstructure:"admin"`
+	Kafka        KafkaConfig   `mapstructure:"kafka"`
+	Email        EmailConfig   `mapstructure:"email"`
+	GRPC         GRPCConfig    `mapstructure:"grpc"`
+	Budget       BudgetConfig  `mapstructure:"budget"`
+	Alerts       AlertsConfig  `mapstructure:"alerts"`
+	Reload       ReloadConfig  `mapstructure:"reload"`
+	Log          LogConfig     `mapstructure:"log"`
+	// ShutdownTimeout bounds the wait for in-flight polls on shutdown
+	// (default 30s); alerts are flushed after it either way
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
 }
 
 // Target defines a monitoring target
@@ -40,19 +63,313 @@ type Target struct {
 	Headers     map[string]string `mapstructure:"headers"`
 	Priority    int               `mapstructure:"priority"`
 	Timeout     time.Duration     `mapstructure:"timeout"`
+	MaxBackoff  time.Duration     `mapstructure:"max_backoff"`
+	// BackoffBase is the first failure backoff, doubling per further
+	// failure up to MaxBackoff (default 10s)
+	BackoffBase time.Duration `mapstructure:"backoff_base"`
+	// BodySizeFactor flags responses this many times larger or smaller
+	// than the rolling average (0 disables)
+	BodySizeFactor float64 `mapstructure:"body_size_factor"`
+	// Schedule limits polling to active windows (empty = always poll)
+	Schedule ScheduleConfig `mapstructure:"schedule"`
+
+	// Escalation promotes sustained detections to Critical
+	Escalation EscalationConfig `mapstructure:"escalation"`
+	// ReleaseModel adapts the poll interval to historical release hours
+	ReleaseModel ReleaseModelConfig `mapstructure:"release_model"`
+	// HotMode polls faster for a while after a near-miss detection
+	HotMode HotModeConfig `mapstructure:"hot_mode"`
+	// Goal is the alert-worthy event: "available" (default), "sold_out",
+	// or "selector" to alert on the "goal" selector matching
+	Goal string `mapstructure:"goal"`
+	// JSON polls a JSON API, optionally paginated, instead of HTML
+	JSON JSONConfig `mapstructure:"json"`
+	// Shadow evaluates candidate selectors without affecting alerts
+	Shadow ShadowConfig `mapstructure:"shadow"`
+	// Confirm re-polls independently before firing Critical (opt-in)
+	Confirm ConfirmConfig `mapstructure:"confirm"`
+	// Screenshot attaches a headless-Chrome capture to Critical alerts (opt-in)
+	Screenshot ScreenshotConfig `mapstructure:"screenshot"`
+	// QueryOrder shuffles query parameter order per session (opt-in)
+	QueryOrder QueryOrderConfig `mapstructure:"query_order"`
+	// DeepLinkAttr is the attribute of the "deep_link" selector match
+	// holding the booking URL (default "href")
+	DeepLinkAttr string `mapstructure:"deep_link_attr"`
+	// Price gates alerts on the "price" selector's parsed value
+	Price PriceRange `mapstructure:"price"`
+	// AlertFields lists the details availability messages include, from
+	// target, timestamp, confidence, availability, price, metadata, link
+	// and screenshot (default: all for Critical, target, confidence and
+	// link for Warning; empty = headline only)
+	AlertFields []string `mapstructure:"alert_fields"`
+	// Language is the expected page language, e.g. "it"; a page in
+	// another one is distrusted (checked against <html lang> or the
+	// "language" selector)
+	Language string `mapstructure:"language"`
+	// Geo is the exit country the site requires (default "IT"); a
+	// "geo_block" selector match means the proxy exited elsewhere
+	Geo string `mapstructure:"geo"`
+	// GeoHeaders overrides headers per proxy exit country, e.g.
+	// {"DE": {"Accept-Language": "de-DE"}}; built-in defaults cover
+	// Accept-Language for IT, DE and FR
+	GeoHeaders map[string]map[string]string `mapstructure:"geo_headers"`
+	// ProxyPools limits the target to proxies with these pool labels
+	// (default: the unlabeled pool)
+	ProxyPools []string `mapstructure:"proxy_pools"`
+	// ProxyFallback applies when those pools have no healthy proxy:
+	// "pool" (least-bad proxy in the pools), "default" or "none"
+	ProxyFallback string `mapstructure:"proxy_fallback"`
+
+	// Critical targets get a tighter default readiness staleness threshold
+	Critical bool `mapstructure:"critical"`
+	// MaxStaleness fails readiness when the last successful poll is older
+	// (default derived from the poll interval)
+	MaxStaleness time.Duration `mapstructure:"max_staleness"`
+	// LatencySLO warns when the p95 poll duration exceeds a threshold
+	LatencySLO LatencySLOConfig `mapstructure:"latency_slo"`
+	// SelectorStaleness warns when no page has matched the available or
+	// sold_out selector for this long, as after a site redesign (default 1h)
+	SelectorStaleness time.Duration `mapstructure:"selector_staleness"`
+	// MaxInFlight caps this target's concurrent requests, e.g. across
+	// the pages of a sweep, within the shared domain budget (0 = no cap)
+	MaxInFlight int `mapstructure:"max_in_flight"`
+	// AvailableGrace keeps a target available, as stale, while polls
+	// error after it was last confirmed available (default 2m)
+	AvailableGrace time.Duration `mapstructure:"available_grace"`
+	// NotifyCooldown is the minimum time between availability status
+	// change alerts (available, sold out again); changes inside it are
+	// tracked but not sent. Escalation to Critical is never held back.
+	NotifyCooldown time.Duration `mapstructure:"notify_cooldown"`
+	// AcquiredSuppression mutes availability alerts after a reported
+	// acquisition (default 1h), until the target sells out
+	AcquiredSuppression time.Duration `mapstructure:"acquired_suppression"`
+	// VisitedTTL keeps visited-URL fingerprints in Redis for this long,
+	// skipping URLs seen within it. 0 (default) disables visited tracking
+	// so every poll re-fetches the target.
+	VisitedTTL time.Duration `mapstructure:"visited_ttl"`
+
+	// Redirects classifies 3xx responses for recovery by their Location
+	Redirects RedirectConfig `mapstructure:"redirects"`
+	// Breaker pauses polling after sustained failures (off by default)
+	Breaker BreakerConfig `mapstructure:"breaker"`
+	// Adaptive tightens the interval in expected release windows and
+	// relaxes it outside them and while polls fail
+	Adaptive AdaptiveConfig `mapstructure:"adaptive"`
+
+	// Notify sends this target's alerts to its own Telegram chats and
+	// webhooks instead of the global ones
+	Notify NotifyConfig `mapstructure:"notify"`
+
+	schedule    *Schedule          // compiled from Schedule at load
+	hotWindows  *Schedule          // compiled from Adaptive.HotWindows
+	alertFields notify.AlertFields // compiled from AlertFields; nil = level default
+	redirects   []redirectRule     // compiled from Redirects at load
+}
+
+// EscalationConfig controls how detections escalate across polls
+type EscalationConfig struct {
+	// Polls is how many consecutive detections are needed before Critical
+	// (<= 1 escalates on the first detection)
+	Polls int `mapstructure:"polls"`
+	// MinConfidence is the per-poll confidence that counts as a detection
+	MinConfidence float64 `mapstructure:"min_confidence"`
+	// CriticalConfidence is the per-poll confidence needed for Critical;
+	// detections between MinConfidence and it stay Warning
+	CriticalConfidence float64 `mapstructure:"critical_confidence"`
+}
+
+// minConfidence returns MinConfidence or its default
+func (c EscalationConfig) minConfidence() float64 {
+	if c.MinConfidence <= 0 {
+		return defaultMinConfidence
+	}
+	return c.MinConfidence
+}
+
+// criticalConfidence returns CriticalConfidence or its default
+func (c EscalationConfig) criticalConfidence() float64 {
+	if c.CriticalConfidence <= 0 {
+		return defaultCriticalConfidence
+	}
+	return c.CriticalConfidence
 }
 
 // ProxyConfig for proxy pool management
 type ProxyConfig struct {
 	URLs            []string      `mapstructure:"urls"`
 	HealthInterval  time.Duration `mapstructure:"health_interval"`
+	// HealthEndpoints are requested through each proxy by health checks;
+	// one 2xx passes, and the first tried rotates each round
+	HealthEndpoints []string `mapstructure:"health_endpoints"`
+	// HealthConcurrency caps proxies checked at once (default 4); checks
+	// also start at random offsets across half of health_interval
+	HealthConcurrency int `mapstructure:"health_concurrency"`
 	RotationPolicy  string        `mapstructure:"rotation_policy"`
+	Strict          bool          `mapstructure:"strict"` // fail on any malformed URL
+	GeoBonus        float64       `mapstructure:"geo_bonus"`     // weight multiplier for preferred geo
+	WeightJitter    float64       `mapstructure:"weight_jitter"` // +/- fraction of random weight jitter
+	MaxConnsPerProxy int          `mapstructure:"max_conns_per_proxy"` // 0 = unlimited
+	WarmPoolSize    int           `mapstructure:"warm_pool_size"` // keep-alive conns per proxy (0 = off)
+	WarmInterval    time.Duration `mapstructure:"warm_interval"`
+	// Ban shaping after repeated errors: base * multiplier^n, capped at
+	// ban_max, +/- ban_jitter (defaults 1m, 2, 30m, 0.2)
+	BanBase       time.Duration `mapstructure:"ban_base"`
+	BanMultiplier float64       `mapstructure:"ban_multiplier"`
+	BanMax        time.Duration `mapstructure:"ban_max"`
+	BanJitter     float64       `mapstructure:"ban_jitter"`
+	// Pools are labeled proxy groups that targets opt into by name
+	Pools map[string][]string `mapstructure:"pools"`
+	// A Warning fires when healthy proxies span fewer distinct ASNs or
+	// countries than these (0 = off)
+	MinDistinctASNs int `mapstructure:"min_distinct_asns"`
+	MinDistinctGeos int `mapstructure:"min_distinct_geos"`
+	// PoolPolicies overrides rotation_policy per pool: "weighted",
+	// "latency_weighted" (slower proxies win less often), "lru",
+	// "round_robin" or "sticky" (each target keeps its proxy while it
+	// stays healthy)
+	PoolPolicies map[string]string `mapstructure:"pool_policies"`
+	// GeoIPDB names MaxMind .mmdb files (comma-separated, e.g. Country
+	// and ASN) resolving each proxy's real exit country and network;
+	// empty guesses them from the proxy hostname
+	GeoIPDB string `mapstructure:"geoip_db"`
 }
 
 // TelegramConfig for notifications
 type TelegramConfig struct {
 	BotToken string `mapstructure:"bot_token"`
 	ChatID   int64  `mapstructure:"chat_id"`
+	// Rate paces sends in messages per second (default 1, Telegram's
+	// per-chat limit); 429s are retried after Telegram's retry_after
+	Rate float64 `mapstructure:"rate"`
+}
+
+// AlertsConfig caps alert volume per target
+type AlertsConfig struct {
+	MaxPerHour     int  `mapstructure:"max_per_hour"`    // 0 = unlimited
+	ExemptCritical bool `mapstructure:"exempt_critical"` // Critical alerts bypass the cap
+	// Routes maps a level ("info", "warning", "critical") to the channels
+	// that receive it; unlisted levels use each channel's default
+	Routes map[string][]string `mapstructure:"routes"`
+	// LevelStyles overrides the emoji and label decorating each level in
+	// every channel; unlisted levels keep 🚨 CRITICAL, ⚠️ WARNING, ℹ️ Info
+	LevelStyles map[string]LevelStyleConfig `mapstructure:"level_styles"`
+	// WebhookURL enables the webhook channel (empty = disabled)
+	WebhookURL string `mapstructure:"webhook_url"`
+	// WebhookRetry re-sends after network errors and 5xx responses
+	WebhookRetry WebhookRetryConfig `mapstructure:"webhook_retry"`
+	// WebhookExcludeScreenshot drops screenshots from webhook payloads
+	WebhookExcludeScreenshot bool `mapstructure:"webhook_exclude_screenshot"`
+	// WebhookMaxPayloadBytes strips large fields, marking the alert
+	// truncated, when the JSON would exceed it (0 = unlimited)
+	WebhookMaxPayloadBytes int `mapstructure:"webhook_max_payload_bytes"`
+	// DiscordWebhookURL enables the Discord channel, posting warning and
+	// critical alerts as embeds (empty = disabled)
+	DiscordWebhookURL string `mapstructure:"discord_webhook_url"`
+	// WebSocketURL streams every alert to a dashboard (ws:// or wss://),
+	// redialled with backoff whenever the connection drops
+	WebSocketURL string `mapstructure:"websocket_url"`
+	// WebhookSecret signs webhook deliveries with an HMAC-SHA256
+	// X-Signature (empty = unsigned)
+	WebhookSecret string `mapstructure:"webhook_secret"`
+	// TLS applies to the webhook and Kafka senders
+	TLS SenderTLSConfig `mapstructure:"tls"`
+	// Audit records every alert and its delivery outcome, queryable
+	// via the admin /alerts endpoint
+	Audit AuditConfig `mapstructure:"audit"`
+	// RequireChannel refuses to start with no notification channel
+	// configured; without it startup only warns
+	RequireChannel bool `mapstructure:"require_channel"`
+	// ActiveHours mutes non-critical alerts outside its windows (no
+	// windows = always active)
+	ActiveHours ActiveHoursConfig `mapstructure:"active_hours"`
+	// Redelivery persists alerts every channel failed to Redis and
+	// retries them in the background, across restarts
+	Redelivery RedeliveryConfig `mapstructure:"redelivery"`
+}
+
+// RedeliveryConfig for retrying failed alerts; unset durations use the
+// notify defaults
+type RedeliveryConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	Key        string        `mapstructure:"key"`         // Redis list (default "colosseo:alerts:undelivered")
+	MinBackoff time.Duration `mapstructure:"min_backoff"` // retry interval, doubling while failing (default 30s)
+	MaxBackoff time.Duration `mapstructure:"max_backoff"` // default 10m
+	MaxAge     time.Duration `mapstructure:"max_age"`     // drop older alerts (default 24h)
+}
+
+// ActiveHoursConfig is the weekly schedule alerts go out normally in.
+// Outside it only Critical alerts are sent.
+type ActiveHoursConfig struct {
+	ScheduleConfig `mapstructure:",squash"`
+	// Hold delivers each target's latest muted alert when the next
+	// window opens instead of dropping it
+	Hold bool `mapstructure:"hold"`
+}
+
+// WebhookRetryConfig for webhook delivery retries; unset fields use
+// notify.DefaultWebhookRetry
+type WebhookRetryConfig struct {
+	Attempts       int           `mapstructure:"attempts"`        // total sends (default 3)
+	AttemptTimeout time.Duration `mapstructure:"attempt_timeout"` // per send (default 5s)
+	Backoff        time.Duration `mapstructure:"backoff"`         // first retry delay, doubling (default 500ms)
+}
+
+// SenderTLSConfig for outbound notification clients. Defaults to TLS
+// 1.2+ with Go's secure cipher suites.
+type SenderTLSConfig struct {
+	MinVersion   string   `mapstructure:"min_version"`   // "1.2" or "1.3"
+	CipherSuites []string `mapstructure:"cipher_suites"` // IANA names, TLS 1.2 only
+	CertFile     string   `mapstructure:"cert_file"`     // client certificate for mTLS
+	KeyFile      string   `mapstructure:"key_file"`
+	CAFile       string   `mapstructure:"ca_file"` // receivers' CA instead of system roots
+}
+
+// AuditConfig for the alert audit log, kept in a capped Redis stream
+type AuditConfig struct {
+	Stream string `mapstructure:"stream"`  // default "colosseo:alerts:audit"
+	MaxLen int64  `mapstructure:"max_len"` // approximate entries kept (default 10000)
+	// File optionally mirrors entries to an append-only JSON lines file
+	File string `mapstructure:"file"`
+}
+
+// Alert audit defaults
+const (
+	defaultAuditStream = "colosseo:alerts:audit"
+	defaultAuditMaxLen = 10000
+)
+
+// LevelStyleConfig decorates one alert level. A configured level uses
+// exactly this emoji (none when empty) and label (default when empty).
+type LevelStyleConfig struct {
+	Emoji string `mapstructure:"emoji"`
+	Label string `mapstructure:"label"`
+}
+
+// KafkaConfig for publishing alerts to a topic (disabled without brokers)
+type KafkaConfig struct {
+	Brokers   []string `mapstructure:"brokers"`
+	Topic     string   `mapstructure:"topic"`
+	Levels    []string `mapstructure:"levels"`    // "info", "warning", "critical"; empty = all
+	Mechanism string   `mapstructure:"mechanism"` // SASL: "plain", "scram-sha-256", "scram-sha-512"
+	Username  string   `mapstructure:"username"`
+	Password  string   `mapstructure:"password"`
+	TLS       bool     `mapstructure:"tls"`
+}
+
+// EmailConfig for mailing critical alerts over SMTP (disabled without a host)
+type EmailConfig struct {
+	Host     string   `mapstructure:"host"`
+	Port     int      `mapstructure:"port"` // default 587 (STARTTLS); 465 = implicit TLS
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+}
+
+// GRPCConfig for the alert stream server (disabled without an address)
+type GRPCConfig struct {
+	Address string `mapstructure:"address"` // e.g. ":50051"
+	Buffer  int    `mapstructure:"buffer"`  // alerts queued per subscriber (default 64)
 }
 
 // RedisConfig for state store
@@ -60,6 +377,11 @@ type RedisConfig struct {
 	Address  string `mapstructure:"address"`
 	Password string `mapstructure:"password"`
 	DB       int    `mapstructure:"db"`
+
+	// Startup connection retries, for when Redis comes up after us
+	ConnectRetries int           `mapstructure:"connect_retries"` // attempts after the first
+	ConnectBackoff time.Duration `mapstructure:"connect_backoff"` // first retry delay, doubling
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"` // total budget across attempts
 }
 
 var (
@@ -95,148 +417,724 @@ var (
 		},
 		[]string{"reason"},
 	)
+
+	bodySizeAnomalies = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "colosseo_body_size_anomalies_total",
+			Help: "Responses whose size deviated from the rolling baseline",
+		},
+		[]string{"target"},
+	)
+
+	configReloads = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "colosseo_config_reloads_total",
+			Help: "Config reload attempts by result",
+		},
+		[]string{"result"},
+	)
+
+	geoBlocks = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "colosseo_geo_blocks_total",
+			Help: "Region-block pages by target and the proxy that served them",
+		},
+		[]string{"target", "proxy"},
+	)
+
+	confirmationPolls = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "colosseo_confirmation_polls_total",
+			Help: "Confirmation polls before Critical, by whether they agreed",
+		},
+		[]string{"target", "result"},
+	)
+
+	monitorRestarts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "colosseo_monitor_restarts_total",
+			Help: "Reload-triggered monitor restarts by target and result",
+		},
+		[]string{"target", "result"},
+	)
+
+	configVersion = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "colosseo_config_version",
+			Help: "Version of the active configuration",
+		},
+	)
+
+	targetAvailable = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "colosseo_target_available",
+			Help: "Current availability by target (0=sold_out, 1=available, 2=uncertain, 3=unknown)",
+		},
+		[]string{"target"},
+	)
+
+	pollRedirects = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "colosseo_redirects_total",
+			Help: "Redirects polls stopped at, by target and class",
+		},
+		[]string{"target", "class"},
+	)
+
+	breakerGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "colosseo_breaker_state",
+			Help: "Circuit breaker position by target: 0 closed, 1 half-open, 2 open",
+		},
+		[]string{"target"},
+	)
+
+	breakerTransitions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "colosseo_breaker_transitions_total",
+			Help: "Circuit breaker transitions by target and new state",
+		},
+		[]string{"target", "state"},
+	)
+
+	selectorLastMatch = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "colosseo_selector_last_match_seconds",
+			Help: "Unix time of the last page where the available or sold_out selector matched",
+		},
+		[]string{"target"},
+	)
+
+	pollDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "colosseo_poll_duration_seconds",
+			Help:    "Duration of completed polls by target",
+			Buckets: []float64{0.1, 0.25, 0.5, 1, 2, 3, 5, 10, 30},
+		},
+		[]string{"target"},
+	)
+
+	requestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "colosseo_request_duration_seconds",
+			Help:    "Duration of single HTTP requests, failed ones included, by target and proxy host",
+			Buckets: []float64{0.1, 0.25, 0.5, 1, 2, 3, 5, 10, 30},
+		},
+		[]string{"target", "proxy"},
+	)
+
+	responseBodyBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "colosseo_response_body_bytes",
+			Help: "Body size of the last response by target",
+		},
+		[]string{"target"},
+	)
+
+	ticketPrice = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "colosseo_ticket_price",
+			Help: "Last parsed ticket price by target and currency",
+		},
+		[]string{"target", "currency"},
+	)
 )
 
 func init() {
-	prometheus.MustRegister(pollAttempts, availabilityEvents, acquisitions, proxyErrors)
+	prometheus.MustRegister(pollAttempts, availabilityEvents, acquisitions, proxyErrors, bodySizeAnomalies,
+		ticketPrice, targetAvailable, configReloads, configVersion, monitorRestarts, confirmationPolls, shadowDisagreements, geoBlocks, pollDuration, requestDuration, responseBodyBytes, pollRedirects, selectorLastMatch, breakerGauge, breakerTransitions, notify.SuppressedAlerts, notify.AuditDropped, notify.StreamDropped, notify.Undeliverable, notify.UndeliveredBacklog, notify.KafkaPublishFailures)
 }
 
 func main() {
+	// Subcommands
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		if err := runReplay(os.Args[2:]); err != nil {
+			slog.Error("Replay failed", "err", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	os.Exit(exitCode(run()))
+}
+
+// run starts the orchestrator and blocks until shutdown. Every exit path
+// returns a ShutdownError naming its cause.
+func run() (err error) {
+	started := time.Now()
+	var dispatcher *notify.Dispatcher
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Panic", "panic", r, "stack", string(debug.Stack()))
+			err = shutdownErr(ReasonPanic, fmt.Errorf("%v", r))
+		}
+		reportShutdown(err, started, dispatcher)
+	}()
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Configuration setup
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
-	viper.AddConfigPath("/etc/colosseo/")
-	viper.AddConfigPath("$HOME/.colosseo")
+	cfg, err := loadConfig()
+	if err != nil {
+		return shutdownErr(ReasonConfig, err)
+	}
+	if err := setupLogging(cfg.Log); err != nil {
+		return shutdownErr(ReasonConfig, err)
+	}
 
-	// Environment variable overrides
-	viper.BindEnv("telegram.bot_token", "TELEGRAM_BOT_TOKEN")
-	viper.BindEnv("redis.address", "REDIS_URL")
+	configVersion.Set(float64(cfg.Version))
+	// cfg stays the startup config; reloads swap live's snapshot
+	live := newLiveConfig(cfg)
 
-	if err := viper.ReadInConfig(); err != nil {
-		log.Fatalf("Config error: %v", err)
-	}
+	// Per-target runtime state
+	registry := NewStateRegistry()
 
-	var cfg MonitorConfig
-	if err := viper.Unmarshal(&cfg); err != nil {
-		log.Fatalf("Config unmarshal error: %v", err)
+	// Probes answer from here on; /readyz turns green as the
+	// subsystems below come up
+	ready := &readiness{}
+	ready.config.Store(true)
+
+	// Fatal errors from background servers end the run
+	fatal := make(chan error, 3)
+
+	// Requests go out through the proxy pool, if one is configured
+	proxies, err := setupProxies(cfg.ProxyPool, cfg.Targets)
+	if err != nil {
+		return shutdownErr(ReasonConfig, err)
 	}
 
-	// Hot reload
-	viper.OnConfigChange(func(e fsnotify.Event) {
-		log.Printf("Config changed: %s", e.Name)
-		var newCfg MonitorConfig
-		if err := viper.Unmarshal(&newCfg); err != nil {
-			log.Printf("Failed to reload config: %v", err)
-			return
-		}
-		// Apply new configuration
-		updateConfig(&cfg, &newCfg)
-	})
-	viper.WatchConfig()
+	// Start metrics server (scrape-safe, open)
+	go func() {
+		fatal <- shutdownErr(ReasonServer, fmt.Errorf("metrics server: %w", startMetricsServer(cfg.MetricsPort, registry, newReadyHandler(ready, live, registry))))
+	}()
+	slog.Info("Metrics server listening", "port", cfg.MetricsPort)
 
-	log.Println("🚀 Colosseo Orchestrator starting...")
+	// One request budget for the domain, however many targets poll it
+	limiter := newDomainLimiter(colosseoDomainGlob, cfg.AsyncThreads, cfg.PollInterval, cfg.PollInterval/2)
+
+	slog.Info("Colosseo Orchestrator starting")
 
 	// Initialize components
-	redisClient := initRedis(cfg.Redis)
+	redisClient, err := initRedis(ctx, cfg.Redis)
+	if err != nil {
+		return shutdownErr(ReasonRedis, err)
+	}
 	defer redisClient.Close()
-	log.Println("✅ Redis connected")
+	ready.redis.Store(redisClient)
+	slog.Info("Redis connected", "address", cfg.Redis.Address)
 
-	telegramBot := initTelegram(cfg.Telegram)
-	log.Println("✅ Telegram bot initialized")
+	// Telegram is optional; checkChannels decides whether running
+	// without any channel is fatal
+	telegramBot, err := initTelegram(cfg.Telegram)
+	if err != nil {
+		slog.Warn("Telegram disabled", "err", err)
+	}
 
-	// Start metrics server
-	go startMetricsServer(cfg.MetricsPort)
-	log.Printf("📊 Metrics server on :%d/metrics", cfg.MetricsPort)
+	dispatcher = notify.NewDispatcher(telegramBot, cfg.Telegram.ChatID, cfg.Telegram.Rate, cfg.Alerts.WebhookURL)
+	if cfg.Alerts.WebhookURL == "" && targetWebhooks(cfg.Targets) {
+		dispatcher.EnableWebhook()
+	}
+	if err := dispatcher.SetTLS(notify.TLSOptions{
+		MinVersion:   cfg.Alerts.TLS.MinVersion,
+		CipherSuites: cfg.Alerts.TLS.CipherSuites,
+		CertFile:     cfg.Alerts.TLS.CertFile,
+		KeyFile:      cfg.Alerts.TLS.KeyFile,
+		CAFile:       cfg.Alerts.TLS.CAFile,
+	}); err != nil {
+		return shutdownErr(ReasonConfig, fmt.Errorf("alert sender %w", err))
+	}
+	dispatcher.SetWebhookOptions(notify.WebhookOptions{
+		Retry: notify.RetryPolicy{
+			Attempts:       cfg.Alerts.WebhookRetry.Attempts,
+			AttemptTimeout: cfg.Alerts.WebhookRetry.AttemptTimeout,
+			Backoff:        cfg.Alerts.WebhookRetry.Backoff,
+		},
+		Secret:            cfg.Alerts.WebhookSecret,
+		ExcludeScreenshot: cfg.Alerts.WebhookExcludeScreenshot,
+		MaxPayloadBytes:   cfg.Alerts.WebhookMaxPayloadBytes,
+	})
+	if cfg.Alerts.WebSocketURL != "" {
+		dispatcher.SetWebSocketURL(cfg.Alerts.WebSocketURL)
+	}
+	if cfg.Alerts.DiscordWebhookURL != "" {
+		dispatcher.SetDiscord(cfg.Alerts.DiscordWebhookURL)
+	}
+	if cfg.Email.Host != "" {
+		if err := dispatcher.SetEmail(notify.EmailOptions{
+			Host:     cfg.Email.Host,
+			Port:     cfg.Email.Port,
+			Username: cfg.Email.Username,
+			Password: cfg.Email.Password,
+			From:     cfg.Email.From,
+			To:       cfg.Email.To,
+		}); err != nil {
+			return shutdownErr(ReasonConfig, err)
+		}
+	}
+	dispatcher.SetRateLimit(notify.RateLimitOptions{
+		MaxAlerts:      cfg.Alerts.MaxPerHour,
+		Window:         time.Hour,
+		ExemptCritical: cfg.Alerts.ExemptCritical,
+	})
+	if len(cfg.Kafka.Brokers) > 0 {
+		if err := setupKafka(dispatcher, cfg.Kafka); err != nil {
+			return shutdownErr(ReasonConfig, err)
+		}
+		slog.Info("Kafka alerts enabled", "topic", cfg.Kafka.Topic)
+	}
+	if err := setupLevelStyles(dispatcher, cfg.Alerts.LevelStyles); err != nil {
+		return shutdownErr(ReasonConfig, err)
+	}
+	if err := setupRoutes(dispatcher, cfg.Alerts.Routes); err != nil {
+		return shutdownErr(ReasonConfig, err)
+	}
+	setupRecipients(dispatcher, cfg.Targets)
+	if err := setupActiveHours(dispatcher, cfg.Alerts.ActiveHours); err != nil {
+		return shutdownErr(ReasonConfig, err)
+	}
+	if err := setupAudit(dispatcher, redisClient, cfg.Alerts.Audit); err != nil {
+		return shutdownErr(ReasonConfig, err)
+	}
+	if cfg.Alerts.Redelivery.Enabled {
+		dispatcher.SetRedelivery(notify.RedeliveryOptions{
+			Client:     redisClient,
+			Key:        cfg.Alerts.Redelivery.Key,
+			MinBackoff: cfg.Alerts.Redelivery.MinBackoff,
+			MaxBackoff: cfg.Alerts.Redelivery.MaxBackoff,
+			MaxAge:     cfg.Alerts.Redelivery.MaxAge,
+		})
+	}
+	if err := checkChannels(cfg, telegramBot); err != nil {
+		return shutdownErr(ReasonConfig, err)
+	}
+	setupDiversityAlert(proxies, cfg.ProxyPool, dispatcher)
 
-	// Create collectors
-	collectors := make(map[string]*colly.Collector)
 	for _, target := range cfg.Targets {
-		collectors[target.Name] = createCollector(target, cfg, redisClient)
+		registry.Get(target.Name)
 	}
+	allocateBudget(cfg.Budget, cfg.Targets, registry)
 
-	// Start monitoring loops
-	var wg sync.WaitGroup
-	for name, collector := range collectors {
-		wg.Add(1)
-		go runMonitor(ctx, &wg, name, collector, findTarget(cfg.Targets, name), telegramBot)
+	// Start admin server (control/config, behind auth)
+	go func() {
+		admin := requireAdminAuth(cfg.Admin, newAdminMux(live, registry, dispatcher, proxies))
+		fatal <- shutdownErr(ReasonServer, fmt.Errorf("admin server: %w", startAdminServer(cfg.Admin.Address, admin)))
+	}()
+	slog.Info("Admin server listening", "address", cfg.Admin.Address)
+
+	// Start alert stream server (typed gRPC subscribers)
+	if cfg.GRPC.Address != "" {
+		go func() {
+			opts := notify.GRPCOptions{Address: cfg.GRPC.Address, Buffer: cfg.GRPC.Buffer}
+			if err := dispatcher.ServeGRPC(ctx, opts); err != nil {
+				fatal <- shutdownErr(ReasonServer, fmt.Errorf("grpc server: %w", err))
+			}
+		}()
+		slog.Info("gRPC alert stream listening", "address", cfg.GRPC.Address)
 	}
 
+	go runReleaseModel(ctx, redisClient, cfg.Targets, registry)
+
+	// Start monitoring loops, one collector per target
+	var wg sync.WaitGroup
+	supervisor := newMonitorSupervisor(ctx, &wg, cfg.Reload, func(ctx context.Context, target Target) {
+		state := registry.Get(target.Name)
+		restoreNotified(redisClient, target, state)
+		collector := createCollector(target, live.Load(), redisClient, state, dispatcher, limiter, proxies)
+		// A stopping monitor's queued requests are dropped, not sent
+		collector.OnRequest(func(r *colly.Request) {
+			if ctx.Err() != nil {
+				r.Abort()
+			}
+		})
+		runMonitor(ctx, target.Name, collector, target, state, dispatcher)
+	})
+	supervisor.Reconcile(cfg.Targets)
+	ready.supervisor.Store(supervisor)
+
+	// Hot reload, watched once everything it reconfigures exists
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		slog.Info("Config changed", "file", e.Name)
+		reloadConfig(live, supervisor, registry, limiter, dispatcher)
+	})
+	viper.WatchConfig()
+
 	// Graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	
-	log.Println("👂 Listening for signals...")
-	<-sigChan
+	slog.Info("Listening for signals")
+	select {
+	case sig := <-sigChan:
+		err = shutdownErr(ReasonSignal, fmt.Errorf("received %s", sig))
+	case err = <-fatal:
+	}
 	
-	log.Println("🛑 Shutting down...")
+	slog.Info("Shutting down")
+	ready.stopping.Store(true)
 	cancel()
-	wg.Wait()
-	log.Println("✅ Shutdown complete")
+	timeout := live.Load().shutdownTimeout()
+	if !waitTimeout(&wg, timeout) {
+		slog.Warn("Shutdown timed out with monitors still polling",
+			"timeout", timeout, "targets", supervisor.Running())
+	}
+	slog.Info("Shutdown complete")
+	return err
+}
+
+// loadConfig reads the config file and environment overrides via viper
+func loadConfig() (MonitorConfig, error) {
+	var cfg MonitorConfig
+
+	viper.SetConfigName("config")
+	viper.SetConfigType("yaml")
+	viper.AddConfigPath(".")
+	viper.AddConfigPath("/etc/colosseo/")
+	viper.AddConfigPath("$HOME/.colosseo")
+
+	// Defaults
+	viper.SetDefault("admin.address", "127.0.0.1:8081")
+
+	// Environment variable overrides
+	viper.BindEnv("telegram.bot_token", "TELEGRAM_BOT_TOKEN")
+	viper.BindEnv("redis.address", "REDIS_URL")
+	viper.BindEnv("admin.token", "ADMIN_TOKEN")
+	viper.BindEnv("admin.hmac_secret", "ADMIN_HMAC_SECRET")
+	viper.BindEnv("alerts.webhook_secret", "WEBHOOK_SECRET")
+	viper.BindEnv("email.password", "SMTP_PASSWORD")
+
+	if err := viper.ReadInConfig(); err != nil {
+		return cfg, err
+	}
+
+	if err := viper.Unmarshal(&cfg); err != nil {
+		return cfg, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	if err := cfg.Admin.validate(); err != nil {
+		return cfg, err
+	}
+	if err := compileTargets(cfg.Targets); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
 }
 
-func initRedis(cfg RedisConfig) *redis.Client {
+// compileTargets validates and prepares derived per-target settings
+func compileTargets(targets []Target) error {
+	for i := range targets {
+		t := &targets[i]
+		if err := config.ValidateURL(t.URL); err != nil {
+			return fmt.Errorf("target %s: %w", t.Name, err)
+		}
+		if err := config.ValidateSelectors(t.Selectors); err != nil {
+			return fmt.Errorf("target %s: %w", t.Name, err)
+		}
+		if err := config.ValidateSelectors(t.Shadow.Selectors); err != nil {
+			return fmt.Errorf("target %s: shadow: %w", t.Name, err)
+		}
+		if err := validateGoal(*t); err != nil {
+			return fmt.Errorf("target %s: %w", t.Name, err)
+		}
+		if err := validateJSON(*t); err != nil {
+			return fmt.Errorf("target %s: %w", t.Name, err)
+		}
+		if _, err := proxy.ParsePoolFallback(t.ProxyFallback); err != nil {
+			return fmt.Errorf("target %s: %w", t.Name, err)
+		}
+		if err := t.Notify.validate(); err != nil {
+			return fmt.Errorf("target %s: %w", t.Name, err)
+		}
+		if t.AlertFields != nil {
+			fields, err := notify.ParseAlertFields(t.AlertFields)
+			if err != nil {
+				return fmt.Errorf("target %s: %w", t.Name, err)
+			}
+			t.alertFields = fields
+		}
+		rules, err := compileRedirects(t.Redirects)
+		if err != nil {
+			return fmt.Errorf("target %s: %w", t.Name, err)
+		}
+		t.redirects = rules
+		schedule, err := t.Schedule.Compile()
+		if err != nil {
+			return fmt.Errorf("target %s: schedule: %w", t.Name, err)
+		}
+		t.schedule = schedule
+		if err := t.ReleaseModel.validate(); err != nil {
+			return fmt.Errorf("target %s: %w", t.Name, err)
+		}
+		hotWindows, err := t.Adaptive.compile()
+		if err != nil {
+			return fmt.Errorf("target %s: %w", t.Name, err)
+		}
+		t.hotWindows = hotWindows
+	}
+	return nil
+}
+
+// proxyFilter returns the proxy pools the target may draw from. The
+// fallback was validated by compileTargets.
+func (t Target) proxyFilter() proxy.PoolFilter {
+	fallback, _ := proxy.ParsePoolFallback(t.ProxyFallback)
+	return proxy.PoolFilter{Pools: t.ProxyPools, Fallback: fallback, Session: t.Name}
+}
+
+// initRedis connects and pings Redis, retrying with exponential backoff
+// until the retries or the total connect timeout are exhausted
+func initRedis(ctx context.Context, cfg RedisConfig) (*redis.Client, error) {
 	client := redis.NewClient(&redis.Options{
 		Addr:     cfg.Address,
 		Password: cfg.Password,
 		DB:       cfg.DB,
 	})
-	
-	// Test connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	
-	if err := client.Ping(ctx).Err(); err != nil {
-		log.Fatalf("Redis connection failed: %v", err)
+
+	if cfg.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.ConnectTimeout)
+		defer cancel()
 	}
-	
-	return client
+
+	backoff := cfg.ConnectBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	attempts := cfg.ConnectRetries + 1
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err = client.Ping(pingCtx).Err()
+		cancel()
+		if err == nil {
+			return client, nil
+		}
+
+		if attempt == attempts {
+			break
+		}
+		slog.Warn("Redis connection failed, retrying", "attempt", attempt, "attempts", attempts, "err", err, "backoff", backoff)
+
+		select {
+		case <-ctx.Done():
+			client.Close()
+			return nil, fmt.Errorf("redis connection failed after %d attempt(s): %w (last error: %v)", attempt, ctx.Err(), err)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	client.Close()
+	return nil, fmt.Errorf("redis connection failed after %d attempt(s): %w", attempts, err)
 }
 
-func initTelegram(cfg TelegramConfig) *tgbotapi.BotAPI {
-	if cfg.BotToken == "" {
-		log.Println("⚠️ No Telegram bot token configured")
+// checkChannels makes a config with nowhere to send alerts loud: an
+// error with alerts.require_channel, a warning otherwise. Undeliverable
+// alerts are then only logged, at error level.
+func checkChannels(cfg MonitorConfig, telegramBot *tgbotapi.BotAPI) error {
+	var channels []string
+	if telegramBot != nil {
+		channels = append(channels, "telegram")
+	}
+	if cfg.Alerts.WebhookURL != "" || targetWebhooks(cfg.Targets) {
+		channels = append(channels, "webhook")
+	}
+	if cfg.Alerts.WebSocketURL != "" {
+		channels = append(channels, "websocket")
+	}
+	if cfg.Alerts.DiscordWebhookURL != "" {
+		channels = append(channels, "discord")
+	}
+	if cfg.Email.Host != "" {
+		channels = append(channels, "email")
+	}
+	if len(cfg.Kafka.Brokers) > 0 {
+		channels = append(channels, "kafka")
+	}
+	if cfg.GRPC.Address != "" {
+		channels = append(channels, "grpc")
+	}
+	if len(channels) > 0 {
+		slog.Info("Alert channels configured", "channels", channels)
 		return nil
 	}
-	
-	bot, err := tgbotapi.NewBotAPI(cfg.BotToken)
+
+	if cfg.Alerts.RequireChannel {
+		return fmt.Errorf("no notification channel configured (telegram, alerts.webhook_url, alerts.discord_webhook_url, email, kafka or grpc)")
+	}
+	slog.Error("No notification channel configured, alerts will only be logged. Set telegram.bot_token, alerts.webhook_url, alerts.discord_webhook_url, email.host, kafka.brokers or grpc.address")
+	return nil
+}
+
+func setupKafka(d *notify.Dispatcher, cfg KafkaConfig) error {
+	levels := make([]notify.AlertLevel, 0, len(cfg.Levels))
+	for _, name := range cfg.Levels {
+		level, err := notify.ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("kafka levels: %w", err)
+		}
+		levels = append(levels, level)
+	}
+
+	return d.SetKafka(notify.KafkaOptions{
+		Brokers:   cfg.Brokers,
+		Topic:     cfg.Topic,
+		Levels:    levels,
+		Mechanism: cfg.Mechanism,
+		Username:  cfg.Username,
+		Password:  cfg.Password,
+		TLS:       cfg.TLS,
+	})
+}
+
+// setupLevelStyles applies the configured severity decoration
+func setupLevelStyles(d *notify.Dispatcher, styles map[string]LevelStyleConfig) error {
+	if len(styles) == 0 {
+		return nil
+	}
+	table := make(map[notify.AlertLevel]notify.LevelStyle, len(styles))
+	for name, s := range styles {
+		level, err := notify.ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("alert level styles: %w", err)
+		}
+		table[level] = notify.LevelStyle{Emoji: s.Emoji, Label: s.Label}
+	}
+	d.SetLevelStyles(table)
+	return nil
+}
+
+func setupRoutes(d *notify.Dispatcher, routes map[string][]string) error {
+	if len(routes) == 0 {
+		return nil
+	}
+	table := make(map[notify.AlertLevel][]notify.Route, len(routes))
+	for name, entries := range routes {
+		level, err := notify.ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("alert routes: %w", err)
+		}
+		// "telegram>webhook" is a failover group, tried left to right
+		for _, entry := range entries {
+			route := notify.Route(strings.Split(entry, ">"))
+			for i := range route {
+				route[i] = strings.TrimSpace(route[i])
+			}
+			table[level] = append(table[level], route)
+		}
+	}
+	d.SetRoutes(table)
+	return nil
+}
+
+// setupActiveHours mutes non-critical alerts outside the configured
+// windows
+func setupActiveHours(d *notify.Dispatcher, cfg ActiveHoursConfig) error {
+	schedule, err := cfg.Compile()
 	if err != nil {
-		log.Printf("Telegram bot init failed: %v", err)
+		return fmt.Errorf("alerts active_hours: %w", err)
+	}
+	if schedule == nil {
 		return nil
 	}
-	
-	log.Printf("✅ Telegram bot authorized: %s", bot.Self.UserName)
-	return bot
+	d.SetQuietHours(notify.QuietHoursOptions{Hours: schedule, Hold: cfg.Hold})
+	return nil
 }
 
-func createCollector(target Target, cfg MonitorConfig, redisClient *redis.Client) *colly.Collector {
+// setupAudit records alerts to the Redis stream, and to the file when set
+func setupAudit(d *notify.Dispatcher, client *redis.Client, cfg AuditConfig) error {
+	if cfg.Stream == "" {
+		cfg.Stream = defaultAuditStream
+	}
+	if cfg.MaxLen <= 0 {
+		cfg.MaxLen = defaultAuditMaxLen
+	}
+	stores := []notify.AuditStore{notify.NewRedisAudit(client, cfg.Stream, cfg.MaxLen)}
+	if cfg.File != "" {
+		f, err := notify.OpenFileAudit(cfg.File)
+		if err != nil {
+			return err
+		}
+		stores = append(stores, f)
+	}
+	d.SetAudit(stores...)
+	return nil
+}
+
+// initTelegram authorizes the configured bot
+func initTelegram(cfg TelegramConfig) (*tgbotapi.BotAPI, error) {
+	if cfg.BotToken == "" {
+		return nil, errors.New("no bot token configured")
+	}
+
+	bot, err := tgbotapi.NewBotAPI(cfg.BotToken)
+	if err != nil {
+		return nil, fmt.Errorf("bot init failed: %w", err)
+	}
+
+	slog.Info("Telegram bot authorized", "bot", bot.Self.UserName)
+	return bot, nil
+}
+
+func createCollector(target Target, cfg MonitorConfig, redisClient *redis.Client, state *TargetState, dispatcher *notify.Dispatcher, limiter *domainLimiter, proxies *proxy.Manager) *colly.Collector {
 	c := colly.NewCollector(
 		colly.UserAgent(randomUserAgent()),
-		colly.AllowedDomains("ticketing.colosseo.it", "www.colosseo.it"),
+		colly.AllowedDomains(config.AllowedDomains...),
 		colly.MaxDepth(cfg.MaxDepth),
 		colly.Async(true),
 	)
 
-	// Storage for session persistence
-	c.SetStorage(&RedisStorage{
-		client: redisClient,
-		prefix: fmt.Sprintf("colly:%s:", target.Name),
-	})
+	// Monitoring re-polls the same URL every cycle, so visited tracking
+	// is opt-in and expires before the URL would be skipped for good
+	if target.VisitedTTL <= 0 {
+		c.AllowURLRevisit = true
+	}
+
+	// Storage for session persistence (replay runs without Redis)
+	if redisClient != nil {
+		c.SetStorage(&RedisStorage{
+			client:     redisClient,
+			prefix:     fmt.Sprintf("colly:%s:", target.Name),
+			visitedTTL: target.VisitedTTL,
+		})
+	}
 
 	// Extensions
 	extensions.RandomUserAgent(c)
 	extensions.Referer(c)
 
-	// Rate limiting with adaptive jitter
-	c.Limit(&colly.LimitRule{
-		DomainGlob:  "*colosseo.it*",
-		Parallelism: cfg.AsyncThreads,
-		Delay:       cfg.PollInterval,
-		RandomDelay: cfg.PollInterval / 2,
-	})
+	// Every request leaves through a proxy from the target's pools when
+	// a pool is configured, with headers matching its exit country
+	var transport http.RoundTripper = http.DefaultTransport.(*http.Transport).Clone()
+	if proxies != nil {
+		transport = newProxyTransport(target, proxies)
+	}
+
+	// Rate limiting with adaptive jitter, shared across collectors when
+	// a domain limiter is given. The target's own in-flight cap applies
+	// on top, to every request its collector expands into.
+	if limiter != nil {
+		c.WithTransport(limiter.Transport(transport))
+		if target.MaxInFlight > 0 {
+			c.Limit(&colly.LimitRule{
+				DomainGlob:  "*",
+				Parallelism: target.MaxInFlight,
+			})
+		}
+	} else {
+		c.WithTransport(transport)
+		parallelism := cfg.AsyncThreads
+		if target.MaxInFlight > 0 && target.MaxInFlight < parallelism {
+			parallelism = target.MaxInFlight
+		}
+		c.Limit(&colly.LimitRule{
+			DomainGlob:  colosseoDomainGlob,
+			Parallelism: parallelism,
+			Delay:       cfg.PollInterval,
+			RandomDelay: cfg.PollInterval / 2,
+		})
+	}
 
 	// Custom headers
 	for k, v := range target.Headers {
@@ -246,17 +1144,103 @@ func createCollector(target Target, cfg MonitorConfig, redisClient *redis.Client
 		})
 	}
 
+	// Query parameter order is fixed for this collector's session
+	if shuffler := newQueryShuffler(target.QueryOrder, rand.Int63()); shuffler != nil {
+		c.OnRequest(func(r *colly.Request) {
+			r.URL.RawQuery = shuffler.Reorder(r.URL.RawQuery)
+		})
+	}
+
 	// Callbacks
+	c.OnRequest(func(r *colly.Request) {
+		// Later pages of a JSON target continue the same visit
+		if r.Ctx.GetAny(jsonPageKey) == nil {
+			state.BeginVisit()
+		}
+	})
+
 	c.OnHTML(target.Selectors["available"], func(e *colly.HTMLElement) {
-		handleAvailability(e, target, true)
+		handleAvailability(e, target, state, true)
 	})
 	
 	c.OnHTML(target.Selectors["sold_out"], func(e *colly.HTMLElement) {
-		handleAvailability(e, target, false)
+		handleAvailability(e, target, state, false)
 	})
+
+	if sel := target.Selectors["geo_block"]; sel != "" {
+		c.OnHTML(sel, func(e *colly.HTMLElement) {
+			state.MarkMatch("geo_block")
+		})
+	}
+
+	if target.goal() == GoalSelector {
+		c.OnHTML(target.Selectors["goal"], func(e *colly.HTMLElement) {
+			state.MarkMatch("goal")
+		})
+	}
+
+	if sel := target.Selectors["deep_link"]; sel != "" {
+		attr := target.DeepLinkAttr
+		if attr == "" {
+			attr = "href"
+		}
+		c.OnHTML(sel, func(e *colly.HTMLElement) {
+			if link := e.Request.AbsoluteURL(strings.TrimSpace(e.Attr(attr))); link != "" {
+				state.MarkDeepLink(link)
+			}
+		})
+	}
+
+	if sel := target.Selectors["price"]; sel != "" {
+		c.OnHTML(sel, func(e *colly.HTMLElement) {
+			state.MarkPrice(strings.TrimSpace(e.Text))
+		})
+	}
+
+	registerShadow(c, target, state)
+	registerLanguage(c, target, state)
+	registerRequestMetrics(c, target)
+
+	finish := func() {
+		checkLatency(target, state, dispatcher)
+		checkShadow(target, state, dispatcher)
+		handleVisitOutcome(target, state, redisClient, dispatcher, proxies)
+	}
+	if target.JSON.enabled() {
+		registerJSON(c, target, state, finish)
+	} else {
+		c.OnScraped(func(r *colly.Response) {
+			checkSelectors(target, state, dispatcher)
+			finish()
+		})
+	}
 	
+	c.OnResponse(func(r *colly.Response) {
+		state.MarkProxy(r.Request.ProxyURL)
+
+		// A challenge interstitial served with 200 is a block, not a poll
+		if vendor := challengeVendor(r); vendor != "" {
+			state.MarkChallenge(vendor)
+			recordPollError(target, state, dispatcher)
+			state.RecordFailure(target.backoffBase(), target.MaxBackoff)
+			return
+		}
+		state.RecordSuccess()
+		reportProxy(proxies, r.Request, true)
+
+		// Cheap selector-independent tripwire for block pages and layout changes
+		anomalous := checkBodySize(target, state, dispatcher, len(r.Body))
+		state.MarkResponse(r.StatusCode, anomalous)
+	})
+
+	// Classified redirects stop and reach OnError as 3xx responses
+	c.SetRedirectHandler(redirectHandler(target))
+
 	c.OnError(func(r *colly.Response, err error) {
-		handleError(r, err, target)
+		if handleRedirect(c, r, err, target, state, dispatcher, proxies) {
+			return
+		}
+		handleError(r, err, target, state, dispatcher, proxies)
 	})
 
 	return c
@@ -264,52 +1248,387 @@ func createCollector(target Target, cfg MonitorConfig, redisClient *redis.Client
 
 func runMonitor(
 	ctx context.Context,
-	wg *sync.WaitGroup,
 	name string,
 	c *colly.Collector,
 	target Target,
-	bot *tgbotapi.BotAPI,
+	state *TargetState,
+	dispatcher *notify.Dispatcher,
 ) {
-	defer wg.Done()
+	interval := max(target.Timeout, state.BudgetInterval())
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	state.SetPollInterval(interval)
 
-	ticker := time.NewTicker(target.Timeout)
-	defer ticker.Stop()
+	slog.Info("Starting monitor", "target", name, "interval", interval)
+
+	poll := func() {
+		// Honor failure backoff and any server Retry-After hint
+		if wait := state.BackoffRemaining(); wait > 0 {
+			slog.Info("Backing off", "target", name, "next_poll_in", wait.Round(time.Second))
+			return
+		}
+		// An open breaker holds polls back silently; it alerted on opening
+		if !breakerAllows(target, state) {
+			return
+		}
 
-	log.Printf("👁️ Starting monitor: %s (interval: %v)", name, target.Timeout)
+		pollAttempts.WithLabelValues(name).Inc()
+
+		if err := c.Visit(target.URL); err != nil {
+			slog.Warn("Visit failed", "target", name, "err", err)
+		}
+		c.Wait()
+		state.ObservePoll()
+		checkBreaker(target, state, dispatcher)
+	}
+
+	// retune recomputes the effective interval, hot mode after a
+	// near-miss, else set by the hot windows, faster or slower around
+	// likely release hours and slower while polls fail, and schedules the
+	// next poll that far from now
+	retune := func(now time.Time) {
+		defer timer.Reset(interval)
+		next := target.pollInterval(state, now)
+		if next == interval {
+			return
+		}
+		slog.Info("Poll interval changed", "target", name, "from", interval, "to", next,
+			"release_probability", state.ReleaseProfile().Probability(now), "hot", state.Hot(now),
+			"failure_rate", state.FailureRate())
+		interval = next
+		state.SetPollInterval(interval)
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Printf("🛑 Stopping monitor: %s", name)
+			slog.Info("Stopping monitor", "target", name)
 			return
-			
-		case <-ticker.C:
-			pollAttempts.WithLabelValues(name).Inc()
-			
-			if err := c.Visit(target.URL); err != nil {
-				log.Printf("[%s] Visit error: %v", name, err)
+
+		case <-state.PollRequests():
+			// Manual polls bypass pause but still honor backoff
+			slog.Info("Manual poll requested", "target", name)
+			poll()
+			retune(time.Now())
+
+		case <-timer.C:
+			now := time.Now()
+			if state.Paused() {
+				retune(now)
+				continue
 			}
-			c.Wait()
+
+			// Outside the active window the monitor sleeps until it reopens
+			if !target.schedule.Active(now) {
+				next := target.schedule.NextStart(now)
+				if state.SetInactiveUntil(next) {
+					slog.Info("Outside active window, sleeping", "target", name, "until", next)
+				}
+				retune(now)
+				continue
+			}
+			if state.SetInactiveUntil(time.Time{}) {
+				slog.Info("Active window opened, resuming polls", "target", name)
+			}
+
+			poll()
+			// Wait from the poll's end, so a near-miss switches to hot
+			// mode at once and failures stretch the next delay
+			retune(time.Now())
 		}
 	}
 }
 
-func handleAvailability(e *colly.HTMLElement, target Target, available bool) {
+func handleAvailability(e *colly.HTMLElement, target Target, state *TargetState, available bool) {
+	selector := "sold_out"
+	if available {
+		selector = "available"
+	}
+	state.MarkMatch(selector)
+
+	// Alerts go out once the whole visit is scored, in handleVisitOutcome
 	status := "unavailable"
 	if available {
 		status = "available"
-		log.Printf("🎉 AVAILABILITY DETECTED: %s", target.Name)
-		
-		// Send Telegram notification
-		// TODO: Implement notification
+		slog.Info("Availability detected", "target", target.Name)
 	}
 	
 	availabilityEvents.WithLabelValues(target.Name, status).Inc()
 }
 
-func handleError(r *colly.Response, err error, target Target) {
-	log.Printf("[%s] Error: %v (status: %d)", target.Name, err, r.StatusCode)
-	
+// handleVisitOutcome scores a completed visit and escalates sustained
+// detections across polls
+func handleVisitOutcome(target Target, state *TargetState, redisClient *redis.Client, dispatcher *notify.Dispatcher, proxies *proxy.Manager) {
+	goal := target.goal()
+	matches := state.EndVisit()
+
+	// An anti-bot challenge page says nothing about availability
+	if matches.Challenge != "" {
+		handleChallenge(target, state, proxies, dispatcher, matches.Challenge, matches.Proxy)
+		return
+	}
+	if state.SetChallenge("") {
+		slog.Info("Polls are getting through again", "target", target.Name)
+	}
+
+	// Nor does a region block page
+	if matches.GeoBlocked {
+		handleGeoBlock(target, state, proxies, dispatcher, matches.Proxy)
+		return
+	}
+
+	// Nor does a page in the wrong language, likely a fallback page
+	if checkLanguage(target, state, dispatcher, matches) {
+		state.RecordDetection(0, target.Escalation)
+		state.SetAvailability(AvailabilityUncertain)
+		return
+	}
+
+	// Selling out ends any post-acquisition suppression
+	if matches.SoldOut && !matches.Available && dispatcher != nil && dispatcher.Unsuppress(target.Name) {
+		slog.Info("Sold out, lifting post-acquisition suppression", "target", target.Name)
+	}
+	confidence := visitConfidence(goal, matches)
+	status := goalStatus(goal)
+
+	// An unreadable price leaves availability Uncertain; a readable one
+	// outside the configured range is not alert-worthy
+	observed := confidence // before the price gate, for the availability gauge
+	price, err := checkPrice(target, matches)
+	switch {
+	case err != nil:
+		if confidence > 0 {
+			slog.Warn("Price unreadable, reporting uncertain", "target", target.Name, "err", err)
+			status = notify.Uncertain
+		}
+	case price != nil:
+		ticketPrice.WithLabelValues(target.Name, price.Currency).Set(price.Amount())
+		if confidence > 0 && !target.Price.Contains(*price) {
+			slog.Info("Price outside configured range, not alerting", "target", target.Name, "price", price.String())
+			confidence = 0
+		}
+	}
+	state.SetAvailability(visitAvailability(matches, observed, status, target.Escalation))
+	announceReversal(target, state, redisClient, dispatcher, matches)
+
+	level, streak, detected := state.RecordDetection(confidence, target.Escalation)
+	if !detected {
+		return
+	}
+
+	// A near-miss may precede the full release: poll faster for a while
+	if level == notify.Warning && target.HotMode.Enabled {
+		now := time.Now()
+		wasHot := state.Hot(now)
+		if state.TriggerHot(now, target.HotMode) && !wasHot {
+			slog.Info("Near-miss, entering hot mode", "target", target.Name, "interval", target.HotMode.interval(), "hold", target.HotMode.hold())
+		}
+	}
+
+	// Feed the release model on the start of each availability streak
+	if streak == 1 && goal == GoalAvailable && target.ReleaseModel.Enabled && redisClient != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		if err := recordRelease(ctx, redisClient, target, time.Now()); err != nil {
+			slog.Warn("Failed to record release event", "target", target.Name, "err", err)
+		}
+		cancel()
+	}
+
+	det := Detection{
+		Target:     target,
+		Level:      level,
+		Status:     status,
+		Confidence: confidence,
+		Streak:     streak,
+		Price:      price,
+		Link:       bookingLink(target, matches),
+		Openings:   matches.Openings,
+	}
+
+	// Critical waits on an out-of-band confirmation poll and screenshot
+	// when enabled, off the collector's goroutine
+	if level == notify.Critical && (target.Confirm.Enabled || target.Screenshot.Enabled) {
+		go func() {
+			if target.Confirm.Enabled {
+				det = confirmDetection(det)
+			}
+			reportDetection(det, state, redisClient, dispatcher)
+		}()
+		return
+	}
+	reportDetection(det, state, redisClient, dispatcher)
+}
+
+// defaultAvailableGrace is how long failing polls keep a stale availability
+const defaultAvailableGrace = 2 * time.Minute
+
+// checkBodySize compares a response's size against the target's
+// baseline, warning once per run of anomalous responses
+func checkBodySize(target Target, state *TargetState, dispatcher *notify.Dispatcher, size int) bool {
+	baseline, anomalous := state.ObserveBodySize(size, target.BodySizeFactor)
+	changed := state.SetBodySizeAnomaly(anomalous)
+	if !anomalous {
+		return false
+	}
+	slog.Warn("Body size anomaly", "target", target.Name, "bytes", size, "baseline", int(baseline))
+	bodySizeAnomalies.WithLabelValues(target.Name).Inc()
+	if !changed || dispatcher == nil {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := dispatcher.Dispatch(ctx, notify.Alert{
+		Level:     notify.Warning,
+		Timestamp: time.Now(),
+		Target:    target.Name,
+		Message: fmt.Sprintf("%s returned %d bytes against a baseline of %d; it may be a block page or a layout change",
+			target.Name, size, int(baseline)),
+		Metadata: map[string]interface{}{"bytes": size, "baseline": int(baseline)},
+	})
+	if err != nil {
+		slog.Error("Failed to send body size warning", "target", target.Name, "err", err)
+	}
+	return true
+}
+
+// recordPollError keeps a recent availability alive through transient
+// poll errors, warning once that confirmation is failing
+func recordPollError(target Target, state *TargetState, dispatcher *notify.Dispatcher) {
+	grace := target.AvailableGrace
+	if grace <= 0 {
+		grace = defaultAvailableGrace
+	}
+	if !state.RecordPollError(grace, time.Now()) {
+		return
+	}
+
+	slog.Warn("Polls failing after availability, keeping it as stale", "target", target.Name, "grace", grace)
+	if dispatcher == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := dispatcher.Dispatch(ctx, notify.Alert{
+		Level:     notify.Warning,
+		Timestamp: time.Now(),
+		Target:    target.Name,
+		Message: fmt.Sprintf("%s was available but polls are now failing; availability is unconfirmed and expires in %s",
+			target.Name, grace),
+		Metadata: map[string]interface{}{"stale": true},
+	})
+	if err != nil {
+		slog.Error("Failed to send stale availability warning", "target", target.Name, "err", err)
+	}
+}
+
+// visitAvailability classifies a completed visit for the availability gauge
+func visitAvailability(m VisitMatches, confidence float64, status notify.AvailabilityStatus, cfg EscalationConfig) Availability {
+	switch {
+	case status == notify.Uncertain:
+		return AvailabilityUncertain
+	case confidence >= cfg.minConfidence():
+		return AvailabilityAvailable
+	case m.SoldOut && !m.Available:
+		return AvailabilitySoldOut
+	default:
+		return AvailabilityUncertain
+	}
+}
+
+// reportDetection announces a detection at its level. Every detection
+// is logged; an alert goes out only on a transition (see ClaimAlert),
+// so a target that stays available alerts once, and again after it is
+// seen sold out in between, even across restarts.
+func reportDetection(det Detection, state *TargetState, redisClient *redis.Client, dispatcher *notify.Dispatcher) {
+	target := det.Target
+	goal := target.goal()
+
+	note := ""
+	if det.Price != nil {
+		note = ", price " + det.Price.String()
+	}
+	if len(det.Openings) > 0 {
+		note += ", open: " + strings.Join(det.Openings, "; ")
+	}
+
+	switch det.Level {
+	case notify.Critical:
+		slog.Warn(goalHeadline(goal, det.Level), "target", target.Name, "level", det.Level.String(),
+			"status", det.Status, "note", note, "polls", det.Streak, "link", det.Link)
+	default:
+		slog.Warn(goalHeadline(goal, det.Level), "target", target.Name, "level", det.Level.String(),
+			"status", det.Status, "note", note, "confidence", det.Confidence, "polls", det.Streak, "polls_needed", max(target.Escalation.Polls, 1))
+	}
+
+	if dispatcher == nil {
+		return
+	}
+	prev, ok := state.ClaimAlert(det.Status, det.Level, target.NotifyCooldown, time.Now())
+	if !ok {
+		return
+	}
+	metadata := map[string]interface{}{"streak": det.Streak}
+	for k, v := range det.Metadata {
+		metadata[k] = v
+	}
+	if len(det.Openings) > 0 {
+		metadata["open"] = strings.Join(det.Openings, "; ")
+	}
+
+	// Captured only for alerts actually going out; without one the text
+	// alert is sent alone
+	var screenshot []byte
+	if det.Level == notify.Critical && target.Screenshot.Enabled {
+		png, err := captureScreenshot(target)
+		if err != nil {
+			slog.Warn("Screenshot failed, alerting without it", "target", target.Name, "err", err)
+		}
+		screenshot = png
+	}
+
+	dispatchTransition(target, state, redisClient, dispatcher, prev, notify.Alert{
+		Level:        det.Level,
+		Timestamp:    time.Now(),
+		Target:       target.Name,
+		Availability: det.Status,
+		Confidence:   float32(det.Confidence),
+		Headline:     goalHeadline(goal, det.Level),
+		Price:        det.Price,
+		Link:         det.Link,
+		Metadata:     metadata,
+		Screenshot:   screenshot,
+		Fields:       target.alertFields,
+	})
+}
+
+func handleError(r *colly.Response, err error, target Target, state *TargetState, dispatcher *notify.Dispatcher, proxies *proxy.Manager) {
+	// Requests aborted by shutdown say nothing about the proxy or target
+	if errors.Is(err, context.Canceled) {
+		slog.Debug("Request cancelled", "target", target.Name, "err", err)
+		return
+	}
+
+	slog.Warn("Request failed", "target", target.Name, "err", err, "status_code", r.StatusCode,
+		"proxy", proxyHost(r.Request.ProxyURL))
+	recordPollError(target, state, dispatcher)
+
+	// A timeout is a failed poll, but not a ban or a server refusal
+	if errors.Is(err, context.DeadlineExceeded) {
+		proxyErrors.WithLabelValues("timeout").Inc()
+		reportProxy(proxies, r.Request, false)
+		state.RecordFailure(target.backoffBase(), target.MaxBackoff)
+		return
+	}
+
+	// Challenge pages mostly come as 403 or 503
+	if vendor := challengeVendor(r); vendor != "" {
+		handleChallenge(target, state, proxies, dispatcher, vendor, r.Request.ProxyURL)
+		state.RecordFailure(target.backoffBase(), target.MaxBackoff)
+		return
+	}
+	reportProxy(proxies, r.Request, false)
+
 	switch r.StatusCode {
 	case 429:
 		proxyErrors.WithLabelValues("rate_limited").Inc()
@@ -320,19 +1639,45 @@ func handleError(r *colly.Response, err error, target Target) {
 	default:
 		proxyErrors.WithLabelValues("other").Inc()
 	}
+
+	state.RecordFailure(target.backoffBase(), target.MaxBackoff)
+
+	// The server's explicit instruction wins over our own backoff when longer
+	if (r.StatusCode == 429 || r.StatusCode == 503) && r.Headers != nil {
+		if wait, ok := parseRetryAfter(r.Headers.Get("Retry-After"), time.Now()); ok {
+			slog.Info("Server requested Retry-After", "target", target.Name, "wait", wait)
+			state.SetRetryAfter(wait)
+		}
+	}
 }
 
-func startMetricsServer(port int) {
-	http.Handle("/metrics", promhttp.Handler())
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
+// metricsHandler serves OpenMetrics, with exemplars, to scrapers that
+// ask for it in Accept and the classic text format to everyone else
+func metricsHandler() http.Handler {
+	return promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+			EnableOpenMetrics: true,
+		}),
+	)
+}
+
+func startMetricsServer(port int, registry *StateRegistry, ready http.Handler) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metricsHandler())
+	mux.HandleFunc("/livez", livez)
+	mux.HandleFunc("/health", livez) // kept for existing probes
+	mux.Handle("/readyz", ready)
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, registry.Snapshot())
 	})
 	
 	addr := fmt.Sprintf(":%d", port)
-	if err := http.ListenAndServe(addr, nil); err != nil {
-		log.Fatalf("Metrics server failed: %v", err)
-	}
+	return http.ListenAndServe(addr, mux)
+}
+
+func startAdminServer(addr string, handler http.Handler) error {
+	return http.ListenAndServe(addr, handler)
 }
 
 func randomUserAgent() string {
@@ -344,19 +1689,38 @@ func randomUserAgent() string {
 	return uas[time.Now().UnixNano()%int64(len(uas))]
 }
 
-func findTarget(targets []Target, name string) Target {
-	for _, t := range targets {
-		if t.Name == name {
-			return t
+// parseRetryAfter parses a Retry-After header in either delay-seconds
+// or HTTP-date form, returning the wait relative to now.
+func parseRetryAfter(header string, now time.Time) (time.Duration, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(header); err == nil {
+		if secs < 0 {
+			return 0, false
 		}
+		return time.Duration(secs) * time.Second, true
 	}
-	return Target{}
+
+	if at, err := http.ParseTime(header); err == nil {
+		wait := at.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
 }
 
-func updateConfig(old, new *MonitorConfig) {
-	// Atomic update of config values
-	old.PollInterval = new.PollInterval
-	old.AsyncThreads = new.AsyncThreads
-	// Deep copy targets if needed
-	log.Println("Configuration updated")
+// findTarget returns the configured target with the given name
+func findTarget(targets []Target, name string) (Target, error) {
+	for _, t := range targets {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return Target{}, fmt.Errorf("target not found: %s", name)
 }
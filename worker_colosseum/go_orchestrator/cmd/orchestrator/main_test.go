@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"colosseo-orchestrator/internal/proxy"
+
+	"github.com/gocolly/colly/v2"
+
+	"github.com/spf13/viper"
+)
+
+// The example config is what operators copy; it has to load as shipped
+func TestLoadExampleConfig(t *testing.T) {
+	example, err := os.ReadFile("../../config.example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), example, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir) // loadConfig looks for config.yaml in the working directory
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if len(cfg.Targets) == 0 {
+		t.Fatal("example config has no targets")
+	}
+	for _, target := range cfg.Targets {
+		if target.Name == "" || target.URL == "" {
+			t.Errorf("target %+v lacks a name or URL", target)
+		}
+	}
+	if cfg.Admin.Address != "127.0.0.1:8081" {
+		t.Errorf("admin address = %q, want the 127.0.0.1:8081 default", cfg.Admin.Address)
+	}
+}
+
+// testCollector builds a target's collector for a local test server
+func testCollector(t *testing.T, target Target, storage *RedisStorage) *colly.Collector {
+	t.Helper()
+	cfg := MonitorConfig{MaxDepth: 1, AsyncThreads: 1}
+	c := createCollector(target, cfg, storage.client, NewStateRegistry().Get(target.Name), nil, nil, nil)
+	c.AllowedDomains = nil // the test server is not a Colosseo host
+	return c
+}
+
+func TestMonitoringTargetRevisitedEachCycle(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte("<html><body>sold out</body></html>"))
+	}))
+	defer srv.Close()
+
+	storage, _ := newTestStorage(t, 0)
+	c := testCollector(t, Target{Name: "tickets", URL: srv.URL}, storage)
+	for cycle := 0; cycle < 3; cycle++ {
+		if err := c.Visit(srv.URL); err != nil {
+			t.Fatalf("cycle %d: Visit: %v", cycle, err)
+		}
+		c.Wait()
+	}
+	if got := hits.Load(); got != 3 {
+		t.Errorf("server saw %d requests over 3 cycles, want 3", got)
+	}
+}
+
+func TestVisitedTTLSkipsUntilExpiry(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte("<html><body>sold out</body></html>"))
+	}))
+	defer srv.Close()
+
+	storage, redisSrv := newTestStorage(t, time.Minute)
+	c := testCollector(t, Target{Name: "tickets", URL: srv.URL, VisitedTTL: time.Minute}, storage)
+	if err := c.Visit(srv.URL); err != nil {
+		t.Fatalf("Visit: %v", err)
+	}
+	c.Wait()
+	if err := c.Visit(srv.URL); !errors.Is(err, colly.ErrAlreadyVisited) {
+		t.Errorf("second Visit within the TTL = %v, want %v", err, colly.ErrAlreadyVisited)
+	}
+
+	redisSrv.advance(time.Minute)
+	if err := c.Visit(srv.URL); err != nil {
+		t.Fatalf("Visit after the TTL: %v", err)
+	}
+	c.Wait()
+	if got := hits.Load(); got != 2 {
+		t.Errorf("server saw %d requests, want 2", got)
+	}
+}
+
+func TestCancelledRequestLeavesProxyHealth(t *testing.T) {
+	proxies, err := proxy.NewManager([]string{"http://10.0.0.1:8080"}, time.Hour, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := Target{Name: "tickets", Timeout: time.Minute}
+	state := NewStateRegistry().Get(target.Name)
+	response := func(status int) *colly.Response {
+		return &colly.Response{
+			StatusCode: status,
+			Request:    &colly.Request{ProxyURL: "http://10.0.0.1:8080", Ctx: colly.NewContext()},
+		}
+	}
+
+	// Shutdown aborting in-flight requests, well past the ban threshold
+	for i := 0; i < 10; i++ {
+		handleError(response(0), fmt.Errorf("Get: %w", context.Canceled), target, state, nil, proxies)
+	}
+	health := proxies.GetHealthStats()[0]
+	if health.HealthScore != 1 || health.ConsecutiveErrors != 0 || health.Banned {
+		t.Errorf("after cancellations: health %v, %d errors, banned %v; want untouched",
+			health.HealthScore, health.ConsecutiveErrors, health.Banned)
+	}
+	if wait := state.BackoffRemaining(); wait != 0 {
+		t.Errorf("cancellations set a %v backoff, want none", wait)
+	}
+
+	// A real failure does count against the proxy
+	handleError(response(http.StatusServiceUnavailable), errors.New("Service Unavailable"), target, state, nil, proxies)
+	health = proxies.GetHealthStats()[0]
+	if health.HealthScore >= 1 || health.ConsecutiveErrors != 1 {
+		t.Errorf("after a 503: health %v, %d errors; want it penalized", health.HealthScore, health.ConsecutiveErrors)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"delta seconds", "120", 2 * time.Minute, true},
+		{"zero seconds", "0", 0, true},
+		{"padded seconds", " 30 ", 30 * time.Second, true},
+		{"negative seconds", "-5", 0, false},
+		{"HTTP-date", "Sat, 14 Mar 2026 09:05:00 GMT", 5 * time.Minute, true},
+		{"RFC 850 date", "Saturday, 14-Mar-26 09:00:30 GMT", 30 * time.Second, true},
+		{"asctime date", "Sat Mar 14 09:01:00 2026", time.Minute, true},
+		{"past date", "Sat, 14 Mar 2026 08:00:00 GMT", 0, true},
+		{"empty", "", 0, false},
+		{"garbage", "soon", 0, false},
+		{"fractional seconds", "1.5", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.header, now)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.header, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSweepRespectsMaxInFlight(t *testing.T) {
+	const maxInFlight = 2
+	var inFlight, peak, hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		hits.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("<html><body>sold out</body></html>"))
+	}))
+	defer srv.Close()
+
+	// A shared budget far wider than the target's own cap
+	limiter := newDomainLimiter(colosseoDomainGlob, 16, 0, 0)
+	target := Target{Name: "tickets", URL: srv.URL, MaxInFlight: maxInFlight}
+	c := createCollector(target, MonitorConfig{MaxDepth: 1, AsyncThreads: 16}, nil,
+		NewStateRegistry().Get(target.Name), nil, limiter, nil)
+	c.AllowedDomains = nil
+
+	// A 14-day sweep
+	for day := 1; day <= 14; day++ {
+		if err := c.Visit(fmt.Sprintf("%s/tickets?date=2026-04-%02d", srv.URL, day)); err != nil {
+			t.Fatalf("Visit day %d: %v", day, err)
+		}
+	}
+	c.Wait()
+
+	if got := hits.Load(); got != 14 {
+		t.Errorf("server saw %d requests, want 14", got)
+	}
+	if got := peak.Load(); got > maxInFlight {
+		t.Errorf("peak in flight = %d, want at most %d", got, maxInFlight)
+	}
+}
+
+func TestFailureBackoffIgnoresRequestTimeout(t *testing.T) {
+	response := func(retryAfter string) *colly.Response {
+		headers := http.Header{}
+		if retryAfter != "" {
+			headers.Set("Retry-After", retryAfter)
+		}
+		return &colly.Response{StatusCode: http.StatusServiceUnavailable, Headers: &headers, Request: &colly.Request{Ctx: colly.NewContext()}}
+	}
+	tests := []struct {
+		name       string
+		target     Target
+		retryAfter string
+		want       time.Duration
+	}{
+		{"default base despite a long timeout", Target{Name: "slow", Timeout: time.Hour}, "", defaultBackoffBase},
+		{"configured base", Target{Name: "tickets", Timeout: time.Hour, BackoffBase: 3 * time.Second}, "", 3 * time.Second},
+		{"longer Retry-After wins", Target{Name: "tickets", Timeout: time.Hour}, "120", 2 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewStateRegistry().Get(tt.target.Name)
+			handleError(response(tt.retryAfter), errors.New("Service Unavailable"), tt.target, state, nil, nil)
+			if got := state.BackoffRemaining(); got > tt.want || got < tt.want-time.Second {
+				t.Errorf("backoff = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// releaseHistoryKey is a sorted set of release hours per target
+	releaseHistoryKey = "colosseo:history:%s"
+	// releaseModelRefresh is how often profiles are recomputed from Redis
+	releaseModelRefresh = 15 * time.Minute
+
+	hoursPerWeek = 7 * 24
+	week         = 7 * 24 * time.Hour
+)
+
+// ReleaseModelConfig tunes polling from the target's learned release profile
+type ReleaseModelConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Timezone string `mapstructure:"timezone"` // bucket hours in this zone (default UTC)
+	Weeks    int    `mapstructure:"weeks"`    // history window (default 8)
+	// HighProbability is the release probability at which FastInterval applies
+	HighProbability float64       `mapstructure:"high_probability"`
+	FastInterval    time.Duration `mapstructure:"fast_interval"`
+	// SlowInterval applies to hours that never saw a release in the window
+	SlowInterval time.Duration `mapstructure:"slow_interval"`
+}
+
+// ReleaseProfile is an hour-of-week release probability: the fraction of
+// observed weeks in which the target became available during that hour
+type ReleaseProfile struct {
+	loc         *time.Location
+	probability [hoursPerWeek]float64
+	events      int
+}
+
+func (c ReleaseModelConfig) weeks() int {
+	if c.Weeks <= 0 {
+		return 8
+	}
+	return c.Weeks
+}
+
+// validate rejects a timezone that would silently bucket hours in UTC
+func (c ReleaseModelConfig) validate() error {
+	if c.Timezone == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		return fmt.Errorf("release_model: timezone %q: %w", c.Timezone, err)
+	}
+	return nil
+}
+
+// location is the zone hours are bucketed in. The timezone was validated
+// by compileTargets.
+func (c ReleaseModelConfig) location() *time.Location {
+	if c.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Interval picks the poll interval for a release probability: fast in
+// likely hours, slow in hours with no history, base otherwise
+func (c ReleaseModelConfig) Interval(base time.Duration, profile *ReleaseProfile, now time.Time) time.Duration {
+	if !c.Enabled || profile == nil || profile.events == 0 {
+		return base
+	}
+
+	p := profile.Probability(now)
+	high := c.HighProbability
+	if high <= 0 {
+		high = 0.3
+	}
+
+	switch {
+	case p >= high && c.FastInterval > 0:
+		return c.FastInterval
+	case p == 0 && c.SlowInterval > 0:
+		return c.SlowInterval
+	default:
+		return base
+	}
+}
+
+// Probability returns the release probability for the hour containing t
+func (p *ReleaseProfile) Probability(t time.Time) float64 {
+	if p == nil {
+		return 0
+	}
+	return p.probability[hourOfWeek(t.In(p.loc))]
+}
+
+func hourOfWeek(t time.Time) int {
+	return int(t.Weekday())*24 + t.Hour()
+}
+
+// recordRelease notes that the target became available at t. Members are
+// hour keys so repeated detections within one hour count once.
+func recordRelease(ctx context.Context, client *redis.Client, target Target, t time.Time) error {
+	cfg := target.ReleaseModel
+	key := fmt.Sprintf(releaseHistoryKey, target.Name)
+	hour := t.In(cfg.location()).Format("2006-01-02T15")
+
+	pipe := client.Pipeline()
+	pipe.ZAddNX(ctx, key, redis.Z{Score: float64(t.Unix()), Member: hour})
+	cutoff := t.Add(-time.Duration(cfg.weeks()) * week).Unix()
+	pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(cutoff, 10))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// loadReleaseProfile builds the hour-of-week profile from the history window
+func loadReleaseProfile(ctx context.Context, client *redis.Client, target Target, now time.Time) (*ReleaseProfile, error) {
+	cfg := target.ReleaseModel
+	key := fmt.Sprintf(releaseHistoryKey, target.Name)
+	since := now.Add(-time.Duration(cfg.weeks()) * week).Unix()
+
+	entries, err := client.ZRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+		Min: strconv.FormatInt(since, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &ReleaseProfile{loc: cfg.location(), events: len(entries)}
+	if len(entries) == 0 {
+		return profile, nil
+	}
+
+	var counts [hoursPerWeek]int
+	oldest := now
+	for _, e := range entries {
+		at := time.Unix(int64(e.Score), 0)
+		counts[hourOfWeek(at.In(profile.loc))]++
+		if at.Before(oldest) {
+			oldest = at
+		}
+	}
+
+	// Weeks observed so far, so a young history isn't diluted by empty weeks
+	observed := math.Ceil(now.Sub(oldest).Hours() / week.Hours())
+	observed = math.Max(1, math.Min(observed, float64(cfg.weeks())))
+	for i, n := range counts {
+		profile.probability[i] = math.Min(1, float64(n)/observed)
+	}
+
+	return profile, nil
+}
+
+// runReleaseModel periodically refreshes release profiles for targets
+// with the model enabled
+func runReleaseModel(ctx context.Context, client *redis.Client, targets []Target, registry *StateRegistry) {
+	refresh := func() {
+		for _, target := range targets {
+			if !target.ReleaseModel.Enabled {
+				continue
+			}
+			profile, err := loadReleaseProfile(ctx, client, target, time.Now())
+			if err != nil {
+				slog.Warn("Release model refresh failed", "target", target.Name, "err", err)
+				continue
+			}
+			registry.Get(target.Name).SetReleaseProfile(profile)
+		}
+	}
+
+	refresh()
+	ticker := time.NewTicker(releaseModelRefresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refresh()
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"colosseo-orchestrator/internal/notify"
+)
+
+// PriceRange gates alerts on the price parsed from the "price" selector.
+// Bounds are in major currency units; zero leaves that side open.
+type PriceRange struct {
+	Min float64 `mapstructure:"min"`
+	Max float64 `mapstructure:"max"`
+}
+
+// currencySymbols maps symbols and codes seen on ticket pages to ISO codes
+var currencySymbols = map[string]string{
+	"€":   "EUR",
+	"eur": "EUR",
+	"$":   "USD",
+	"usd": "USD",
+	"£":   "GBP",
+	"gbp": "GBP",
+}
+
+// Contains reports whether price falls inside the range
+func (r PriceRange) Contains(p notify.Price) bool {
+	amount := p.Amount()
+	if r.Min > 0 && amount < r.Min {
+		return false
+	}
+	if r.Max > 0 && amount > r.Max {
+		return false
+	}
+	return true
+}
+
+// checkPrice parses the visit's price text when the target has a price
+// selector. A nil price with a nil error means the target tracks no price.
+func checkPrice(target Target, m VisitMatches) (*notify.Price, error) {
+	if target.Selectors["price"] == "" {
+		return nil, nil
+	}
+	if m.PriceText == "" {
+		return nil, fmt.Errorf("price selector did not match")
+	}
+	price, err := parsePrice(m.PriceText)
+	if err != nil {
+		return nil, err
+	}
+	return &price, nil
+}
+
+// parsePrice parses a displayed price such as "€ 1.234,50", "18,00 €" or
+// "EUR 25.00". The currency may lead or trail and defaults to EUR. When
+// both separators appear the later one is the decimal point; a lone
+// separator followed by exactly three digits is a thousands separator,
+// Italian style.
+func parsePrice(text string) (notify.Price, error) {
+	currency := ""
+	var digits strings.Builder
+	var letters strings.Builder
+	for _, r := range strings.TrimSpace(text) {
+		switch {
+		case unicode.IsDigit(r) || r == '.' || r == ',':
+			digits.WriteRune(r)
+		case unicode.IsLetter(r):
+			letters.WriteRune(unicode.ToLower(r))
+		case unicode.IsSpace(r):
+		default:
+			if code, ok := currencySymbols[string(r)]; ok {
+				currency = code
+			}
+		}
+	}
+	// Words other than a currency code (e.g. "Intero") are labels
+	if code, ok := currencySymbols[letters.String()]; ok {
+		currency = code
+	}
+	if currency == "" {
+		currency = "EUR"
+	}
+
+	amount, err := parseAmount(digits.String())
+	if err != nil {
+		return notify.Price{}, fmt.Errorf("parse price %q: %w", text, err)
+	}
+	return notify.Price{Cents: int64(math.Round(amount * 100)), Currency: currency}, nil
+}
+
+// parseAmount normalizes decimal and thousands separators to a float
+func parseAmount(s string) (float64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("no digits")
+	}
+
+	lastDot := strings.LastIndex(s, ".")
+	lastComma := strings.LastIndex(s, ",")
+
+	decimal := byte(0)
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		decimal = s[max(lastDot, lastComma)]
+	case lastComma >= 0:
+		if len(s)-lastComma-1 != 3 {
+			decimal = ','
+		}
+	case lastDot >= 0:
+		if len(s)-lastDot-1 != 3 {
+			decimal = '.'
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == decimal:
+			b.WriteByte('.')
+		case c == '.' || c == ',':
+			// thousands separator
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return strconv.ParseFloat(b.String(), 64)
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"colosseo-orchestrator/internal/notify"
+	"colosseo-orchestrator/internal/proxy"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultProxyHealthInterval applies when proxy_pool.health_interval is unset
+const defaultProxyHealthInterval = 30 * time.Second
+
+// errNoProxy fails a request that would otherwise leave from the host IP
+var errNoProxy = errors.New("no proxy available")
+
+// setupProxies builds the proxy manager from the proxy_pool config, or
+// returns nil when no proxies are configured and requests go direct.
+// Warm pools keep the first target's origin warm; every target polls the
+// same site.
+func setupProxies(cfg ProxyConfig, targets []Target) (*proxy.Manager, error) {
+	if len(cfg.URLs) == 0 && len(cfg.Pools) == 0 {
+		return nil, nil
+	}
+
+	rotation, err := proxy.ParseRotationPolicy(cfg.RotationPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("proxy_pool: %w", err)
+	}
+	policies := make(map[string]proxy.RotationPolicy, len(cfg.PoolPolicies))
+	for pool, name := range cfg.PoolPolicies {
+		policy, err := proxy.ParseRotationPolicy(name)
+		if err != nil {
+			return nil, fmt.Errorf("proxy_pool: pool %s: %w", pool, err)
+		}
+		policies[pool] = policy
+	}
+
+	interval := cfg.HealthInterval
+	if interval <= 0 {
+		interval = defaultProxyHealthInterval
+	}
+	var proxies *proxy.Manager
+	if cfg.GeoIPDB != "" {
+		proxies, err = proxy.NewManagerWithGeoIP(cfg.URLs, interval, cfg.GeoIPDB)
+		if err == nil && cfg.Strict && len(proxies.Rejected()) > 0 {
+			err = fmt.Errorf("invalid proxy URL %s", proxies.Rejected()[0])
+		}
+	} else {
+		proxies, err = proxy.NewManager(cfg.URLs, interval, cfg.Strict)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("proxy_pool: %w", err)
+	}
+	for name, urls := range cfg.Pools {
+		if err := proxies.AddPool(name, urls); err != nil {
+			return nil, fmt.Errorf("proxy_pool: pool %s: %w", name, err)
+		}
+	}
+	if err := proxies.SetHealthEndpoints(cfg.HealthEndpoints); err != nil {
+		return nil, fmt.Errorf("proxy_pool: %w", err)
+	}
+	proxies.SetHealthCheckConcurrency(cfg.HealthConcurrency)
+	proxies.SetMaxConnsPerProxy(cfg.MaxConnsPerProxy)
+	if cfg.WarmPoolSize > 0 && len(targets) > 0 {
+		if err := proxies.SetWarmPool(proxy.WarmPoolOptions{
+			Size:     cfg.WarmPoolSize,
+			Target:   targets[0].URL,
+			Interval: cfg.WarmInterval,
+		}); err != nil {
+			return nil, fmt.Errorf("proxy_pool: %w", err)
+		}
+	}
+
+	geoBonus := cfg.GeoBonus
+	if geoBonus <= 0 {
+		geoBonus = proxy.DefaultGeoBonus
+	}
+	proxies.SetSelectionWeights(geoBonus, cfg.WeightJitter)
+	proxies.SetBanPolicy(proxy.BanPolicy{
+		Base:       cfg.BanBase,
+		Multiplier: cfg.BanMultiplier,
+		Max:        cfg.BanMax,
+		Jitter:     cfg.BanJitter,
+	})
+	proxies.SetRotationPolicy(rotation)
+	for pool, policy := range policies {
+		proxies.SetPoolPolicy(pool, policy)
+	}
+
+	prometheus.MustRegister(proxies.Metrics()...)
+	if rejected := proxies.Rejected(); len(rejected) > 0 {
+		slog.Warn("Proxy pool started without malformed URLs", "rejected", len(rejected))
+	}
+	return proxies, nil
+}
+
+// setupDiversityAlert warns when healthy proxies span too few networks
+// or countries to survive a correlated ban
+func setupDiversityAlert(proxies *proxy.Manager, cfg ProxyConfig, dispatcher *notify.Dispatcher) {
+	if proxies == nil || (cfg.MinDistinctASNs <= 0 && cfg.MinDistinctGeos <= 0) {
+		return
+	}
+	proxies.SetDiversityAlert(proxy.DiversityOptions{
+		MinASNs: cfg.MinDistinctASNs,
+		MinGeos: cfg.MinDistinctGeos,
+		OnLow: func(d proxy.Diversity) {
+			slog.Warn("Proxy pool diversity low", "healthy", d.Healthy, "asns", d.ASNs, "geos", d.Geos)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := dispatcher.Dispatch(ctx, notify.Alert{
+				Level:     notify.Warning,
+				Timestamp: time.Now(),
+				Target:    "proxy_pool",
+				Message: fmt.Sprintf("Healthy proxies span only %d ASN(s) and %d countr(ies); one ban could take out the pool",
+					d.ASNs, d.Geos),
+				Metadata: map[string]interface{}{"healthy": d.Healthy, "asns": d.ASNs, "geos": d.Geos},
+			}); err != nil {
+				slog.Error("Failed to send proxy diversity warning", "err", err)
+			}
+		},
+	})
+}
+
+// proxyTransport sends each request through a proxy picked from the
+// target's pools, preferring its geography, over that proxy's own
+// transport: a chained proxy tunnels through every hop, and its
+// connections are reused. Each request holds one of the proxy's
+// connection slots (max_conns_per_proxy) until its response is read,
+// when colly hands it to OnResponse, or until it fails on its way to
+// OnError. With no proxy to give, the request fails rather than going
+// direct.
+type proxyTransport struct {
+	proxies *proxy.Manager
+	target  Target
+	geo     string
+	filter  proxy.PoolFilter
+}
+
+func newProxyTransport(target Target, proxies *proxy.Manager) *proxyTransport {
+	return &proxyTransport{proxies: proxies, target: target, geo: target.geo(), filter: target.proxyFilter()}
+}
+
+func (t *proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id, err := t.proxies.Acquire(req.Context(), t.geo, t.filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errNoProxy, err)
+	}
+	release := func() { t.proxies.Release(id) }
+
+	rt := t.proxies.RoundTripper(id)
+	if rt == nil { // removed since it was picked
+		release()
+		return nil, errNoProxy
+	}
+
+	// Like colly's own proxy switchers, record the proxy in the request
+	// in place so it surfaces as the colly request's ProxyURL. That is
+	// the proxy's ID rather than its exit URL, which chains can share.
+	*req = *req.WithContext(context.WithValue(req.Context(), colly.ProxyURLKey, id))
+	setGeoHeaders(req, t.target, t.proxies.GeoOf(id))
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		release()
+		// colly only learns the proxy from a response, so a request that
+		// never got one is charged to its proxy here. Cancellation says
+		// nothing about the proxy.
+		if !errors.Is(req.Context().Err(), context.Canceled) {
+			t.proxies.ReportResult(id, false, 0)
+		}
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// reportProxy feeds a request's outcome into its proxy's health record
+func reportProxy(proxies *proxy.Manager, r *colly.Request, success bool) {
+	if proxies == nil || r.ProxyURL == "" {
+		return
+	}
+	var latency time.Duration
+	if start, ok := r.Ctx.GetAny(requestStartKey(r)).(time.Time); ok {
+		latency = time.Since(start)
+	}
+	proxies.ReportResult(r.ProxyURL, success, latency)
+}
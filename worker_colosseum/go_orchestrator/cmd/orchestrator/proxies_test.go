@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"colosseo-orchestrator/internal/proxy"
+)
+
+// A proxy that refuses connections never yields a response, so colly
+// can't name it to OnError; the transport has to record the failure
+func TestDeadProxyFailureRecorded(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dead := "http://" + ln.Addr().String()
+	ln.Close()
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	proxies, err := proxy.NewManager([]string{dead}, time.Hour, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage, _ := newTestStorage(t, 0)
+	target := Target{Name: "tickets", URL: srv.URL}
+	cfg := MonitorConfig{MaxDepth: 1, AsyncThreads: 1}
+	c := createCollector(target, cfg, storage.client, NewStateRegistry().Get(target.Name), nil, nil, proxies)
+	c.AllowedDomains = nil
+
+	if err := c.Visit(srv.URL); err != nil {
+		t.Fatalf("Visit: %v", err)
+	}
+	c.Wait()
+
+	if got := hits.Load(); got != 0 {
+		t.Errorf("server saw %d requests, want none through a dead proxy", got)
+	}
+	health := proxies.GetHealthStats()[0]
+	if health.ConsecutiveErrors != 1 || health.HealthScore >= 1 {
+		t.Errorf("after one refused connection: %d errors, health %v; want 1 error recorded once",
+			health.ConsecutiveErrors, health.HealthScore)
+	}
+	if health.InUse != 0 {
+		t.Errorf("InUse = %d, want the slot released", health.InUse)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"strings"
+)
+
+// QueryOrderConfig varies the order of query parameters across sessions,
+// as real browsers do, while keeping it stable within one session
+type QueryOrderConfig struct {
+	Shuffle bool `mapstructure:"shuffle"`
+	// Pinned parameters keep their original position, e.g. ones covered
+	// by a request signature
+	Pinned []string `mapstructure:"pinned"`
+}
+
+// queryShuffler reorders query parameters with a per-session seed
+type queryShuffler struct {
+	seed   int64
+	pinned map[string]bool
+}
+
+func newQueryShuffler(cfg QueryOrderConfig, seed int64) *queryShuffler {
+	if !cfg.Shuffle {
+		return nil
+	}
+	pinned := make(map[string]bool, len(cfg.Pinned))
+	for _, name := range cfg.Pinned {
+		pinned[name] = true
+	}
+	return &queryShuffler{seed: seed, pinned: pinned}
+}
+
+// Reorder permutes the unpinned parameters of rawQuery. Pairs are moved
+// verbatim, so encoding and repeated keys are preserved, and the same
+// query always gets the same order within a session.
+func (q *queryShuffler) Reorder(rawQuery string) string {
+	if q == nil || rawQuery == "" {
+		return rawQuery
+	}
+
+	pairs := strings.Split(rawQuery, "&")
+	var movable []int
+	h := fnv.New64a()
+	for i, pair := range pairs {
+		h.Write([]byte(pair))
+		h.Write([]byte{'&'})
+		if !q.pinned[queryKey(pair)] {
+			movable = append(movable, i)
+		}
+	}
+	if len(movable) < 2 {
+		return rawQuery
+	}
+
+	// Seeded by session and query so the order is stable per session
+	rng := rand.New(rand.NewSource(q.seed ^ int64(h.Sum64())))
+	shuffled := make([]string, len(movable))
+	for i, idx := range movable {
+		shuffled[i] = pairs[idx]
+	}
+	rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	result := append([]string(nil), pairs...)
+	for i, idx := range movable {
+		result[idx] = shuffled[i]
+	}
+	return strings.Join(result, "&")
+}
+
+// queryKey returns the raw key of a "k=v" pair
+func queryKey(pair string) string {
+	key, _, _ := strings.Cut(pair, "=")
+	return key
+}
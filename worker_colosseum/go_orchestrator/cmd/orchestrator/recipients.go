@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+
+	"colosseo-orchestrator/internal/notify"
+)
+
+// NotifyConfig sends a target's alerts to its own recipients. A list
+// left empty falls back to telegram.chat_id or alerts.webhook_url.
+type NotifyConfig struct {
+	ChatIDs     []int64  `mapstructure:"chat_ids"`
+	WebhookURLs []string `mapstructure:"webhook_urls"`
+}
+
+// validate checks the webhook URLs are absolute http(s) URLs
+func (c NotifyConfig) validate() error {
+	for _, raw := range c.WebhookURLs {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("notify: webhook URL %q must be an absolute http or https URL", raw)
+		}
+	}
+	return nil
+}
+
+// setupRecipients routes each target's alerts to its own chats and
+// webhooks, if it has any
+func setupRecipients(d *notify.Dispatcher, targets []Target) {
+	if d == nil {
+		return
+	}
+	byTarget := make(map[string]notify.Recipients)
+	for _, t := range targets {
+		if len(t.Notify.ChatIDs) == 0 && len(t.Notify.WebhookURLs) == 0 {
+			continue
+		}
+		byTarget[t.Name] = notify.Recipients{
+			ChatIDs:     t.Notify.ChatIDs,
+			WebhookURLs: t.Notify.WebhookURLs,
+		}
+	}
+	d.SetRecipients(byTarget)
+}
+
+// targetWebhooks reports whether any target posts to its own webhooks
+func targetWebhooks(targets []Target) bool {
+	for _, t := range targets {
+		if len(t.Notify.WebhookURLs) > 0 {
+			return true
+		}
+	}
+	return false
+}
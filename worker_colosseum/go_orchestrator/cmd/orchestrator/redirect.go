@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+
+	"colosseo-orchestrator/internal/notify"
+	"colosseo-orchestrator/internal/proxy"
+)
+
+// Redirect classes, the colosseo_redirects_total class label values
+const (
+	redirectSessionExpiry = "session_expiry"
+	redirectMaintenance   = "maintenance"
+	redirectGeo           = "geo_redirect"
+	redirectOffDomain     = "off_domain"
+	redirectOther         = "other"
+)
+
+// defaultMaintenanceBackoff is how long a maintenance redirect pauses polling
+const defaultMaintenanceBackoff = 5 * time.Minute
+
+// maxRedirects matches net/http's limit for redirects that are followed
+const maxRedirects = 10
+
+// Default Location patterns, used for classes the config leaves unset
+var defaultRedirectPatterns = map[string][]string{
+	redirectSessionExpiry: {`(?i)/(login|signin|sign-in|auth)\b`, `(?i)session[-_]?expired`},
+	redirectMaintenance:   {`(?i)\bmaint(enance)?\b`, `(?i)/(queue|waiting-?room)\b`},
+	redirectGeo:           {`(?i)(geo|region|country)[-_]?(block|restrict)`, `(?i)not[-_]available[-_]in`},
+}
+
+// RedirectConfig classifies 3xx responses by their Location, each class
+// getting its own recovery instead of counting as a proxy error
+type RedirectConfig struct {
+	// Location regexps per class; unset classes use the defaults
+	SessionExpiry []string `mapstructure:"session_expiry"` // new session, re-poll
+	Maintenance   []string `mapstructure:"maintenance"`    // back off
+	GeoRedirect   []string `mapstructure:"geo_redirect"`   // rotate to a correct-geo proxy
+	// MaintenanceBackoff pauses polling after a maintenance redirect (default 5m)
+	MaintenanceBackoff time.Duration `mapstructure:"maintenance_backoff"`
+}
+
+// redirectRule maps a Location pattern to its class
+type redirectRule struct {
+	class   string
+	pattern *regexp.Regexp
+}
+
+// compileRedirects builds the target's Location rules, in class order
+func compileRedirects(cfg RedirectConfig) ([]redirectRule, error) {
+	var rules []redirectRule
+	for _, class := range []struct {
+		name     string
+		patterns []string
+	}{
+		{redirectSessionExpiry, cfg.SessionExpiry},
+		{redirectMaintenance, cfg.Maintenance},
+		{redirectGeo, cfg.GeoRedirect},
+	} {
+		patterns := class.patterns
+		if patterns == nil {
+			patterns = defaultRedirectPatterns[class.name]
+		}
+		for _, p := range patterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("redirects: %s pattern %q: %w", class.name, p, err)
+			}
+			rules = append(rules, redirectRule{class: class.name, pattern: re})
+		}
+	}
+	return rules, nil
+}
+
+// classifyRedirect returns the class of the first rule matching location
+func classifyRedirect(rules []redirectRule, location string) (string, bool) {
+	for _, r := range rules {
+		if r.pattern.MatchString(location) {
+			return r.class, true
+		}
+	}
+	return "", false
+}
+
+// redirectHandler stops at redirects the target's rules classify, so
+// they surface as 3xx responses, and follows the rest
+func redirectHandler(target Target) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if _, ok := classifyRedirect(target.redirects, req.URL.String()); ok {
+			return http.ErrUseLastResponse
+		}
+		if len(via) >= maxRedirects {
+			return http.ErrUseLastResponse
+		}
+		// As colly's default: credentials don't follow a host change
+		if last := via[len(via)-1]; req.URL.Host != last.URL.Host {
+			req.Header.Del("Authorization")
+		}
+		return nil
+	}
+}
+
+// offDomainRedirect extracts the host of a redirect colly refused to
+// follow because it left the allowed domains
+func offDomainRedirect(err error) (string, bool) {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return "", false
+	}
+	msg := urlErr.Err.Error()
+	const prefix = "Not following redirect to "
+	if !strings.HasPrefix(msg, prefix) {
+		return "", false
+	}
+	host, _, _ := strings.Cut(strings.TrimPrefix(msg, prefix), " ")
+	return host, true
+}
+
+// handleRedirect logs and recovers from a redirect the poll stopped at,
+// reporting whether err was one. Session expiry starts a fresh cookie
+// session and re-polls, maintenance backs off, and a geo redirect is a
+// region block of the proxy that served it.
+func handleRedirect(c *colly.Collector, r *colly.Response, err error, target Target, state *TargetState, dispatcher *notify.Dispatcher, proxies *proxy.Manager) bool {
+	var location, class string
+	switch host, offDomain := offDomainRedirect(err); {
+	case r.StatusCode >= 300 && r.StatusCode < 400:
+		if r.Headers != nil {
+			location = r.Headers.Get("Location")
+		}
+		if base := r.Request.URL; base != nil && location != "" {
+			if u, err := base.Parse(location); err == nil {
+				location = u.String()
+			}
+		}
+		class = redirectOther
+	case offDomain:
+		location = "//" + host
+		class = redirectOffDomain
+	default:
+		return false
+	}
+	if matched, ok := classifyRedirect(target.redirects, location); ok {
+		class = matched
+	}
+
+	slog.Warn("Redirected", "target", target.Name, "status_code", r.StatusCode, "class", class, "location", location)
+	pollRedirects.WithLabelValues(target.Name, class).Inc()
+	recordPollError(target, state, dispatcher)
+
+	switch class {
+	case redirectSessionExpiry:
+		// The stale session's cookies go with the old jar
+		jar, _ := cookiejar.New(nil)
+		c.SetCookieJar(jar)
+		state.RequestPoll()
+	case redirectMaintenance:
+		backoff := target.Redirects.MaintenanceBackoff
+		if backoff <= 0 {
+			backoff = defaultMaintenanceBackoff
+		}
+		slog.Warn("Site in maintenance, pausing polls", "target", target.Name, "backoff", backoff)
+		state.SetRetryAfter(backoff)
+	case redirectGeo:
+		handleGeoBlock(target, state, proxies, dispatcher, r.Request.ProxyURL)
+	default:
+		state.RecordFailure(target.backoffBase(), target.MaxBackoff)
+	}
+	return true
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"colosseo-orchestrator/internal/notify"
+
+	"github.com/spf13/viper"
+)
+
+// Config reload results, the colosseo_config_reloads_total label values
+const (
+	reloadSuccess         = "success"
+	reloadUnmarshalError  = "unmarshal_error"
+	reloadValidationError = "validation_error"
+)
+
+// liveConfig is the running config. A reload builds the updated config
+// on its own copy and swaps it in, so readers on other goroutines (the
+// admin server, readiness probes, monitors starting) always get a whole
+// snapshot.
+type liveConfig struct {
+	current atomic.Pointer[MonitorConfig]
+}
+
+func newLiveConfig(cfg MonitorConfig) *liveConfig {
+	c := &liveConfig{}
+	c.current.Store(&cfg)
+	return c
+}
+
+// Load returns the current config. Its slices and maps are shared with
+// other readers and must not be modified.
+func (c *liveConfig) Load() MonitorConfig {
+	return *c.current.Load()
+}
+
+// reloadConfig re-reads the config after a file change and applies it,
+// reconciling monitors with the new targets and recording the outcome
+// as a metric and a structured log line. Added targets start, removed
+// ones stop and lose their state, and changed ones restart; a change to
+// the collector-wide settings restarts every monitor.
+func reloadConfig(live *liveConfig, supervisor *monitorSupervisor, registry *StateRegistry, limiter *domainLimiter, dispatcher *notify.Dispatcher) {
+	cfg := live.Load()
+	var newCfg MonitorConfig
+	if err := viper.Unmarshal(&newCfg); err != nil {
+		logReload(reloadUnmarshalError, cfg.Version, newCfg.Version, nil, err)
+		return
+	}
+	if err := compileTargets(newCfg.Targets); err != nil {
+		logReload(reloadValidationError, cfg.Version, newCfg.Version, nil, err)
+		return
+	}
+	if _, err := parseLogLevel(newCfg.Log.Level); err != nil {
+		logReload(reloadValidationError, cfg.Version, newCfg.Version, nil, err)
+		return
+	}
+
+	changes := configDiff(cfg, newCfg)
+	oldVersion := cfg.Version
+	collectorsChanged := cfg.PollInterval != newCfg.PollInterval ||
+		cfg.MaxDepth != newCfg.MaxDepth ||
+		cfg.AsyncThreads != newCfg.AsyncThreads
+	if needRestart := updateConfig(&cfg, &newCfg); len(needRestart) > 0 {
+		slog.Warn("Config changes take effect only after a restart", "sections", needRestart)
+	}
+	live.current.Store(&cfg)
+	setLogLevel(cfg.Log.Level) // validated above
+	if limiter != nil {
+		limiter.Resize(cfg.AsyncThreads, cfg.PollInterval, cfg.PollInterval/2)
+	}
+	setupRecipients(dispatcher, cfg.Targets)
+	if dispatcher != nil && targetWebhooks(cfg.Targets) {
+		if _, ok := dispatcher.Channel("webhook"); !ok {
+			slog.Warn("Target webhook recipients take effect only after a restart")
+		}
+	}
+	// Reallocate before restarting monitors so they start within budget
+	allocateBudget(cfg.Budget, cfg.Targets, registry)
+	if supervisor != nil {
+		supervisor.Reconcile(newCfg.Targets)
+		if collectorsChanged {
+			supervisor.RestartAll()
+		}
+	}
+	registry.Retain(cfg.Targets)
+	configVersion.Set(float64(cfg.Version))
+	logReload(reloadSuccess, oldVersion, newCfg.Version, changes, nil)
+}
+
+// updateConfig applies a reloaded config in place: the targets, the log
+// level and the settings collectors are built from, which take effect
+// as monitors are recreated. Servers, connections and alert channels are set up once at
+// startup; changes to those are not applied but returned, by config
+// key, so the operator knows a restart is needed.
+func updateConfig(old, new *MonitorConfig) (needRestart []string) {
+	for _, section := range []struct {
+		key      string
+		old, new interface{}
+	}{
+		{"proxy_pool", old.ProxyPool, new.ProxyPool},
+		{"telegram", old.Telegram, new.Telegram},
+		{"redis", old.Redis, new.Redis},
+		{"metrics_port", old.MetricsPort, new.MetricsPort},
+		{"admin", old.Admin, new.Admin},
+		{"kafka", old.Kafka, new.Kafka},
+		{"email", old.Email, new.Email},
+		{"grpc", old.GRPC, new.GRPC},
+		{"alerts", old.Alerts, new.Alerts},
+		{"reload", old.Reload, new.Reload},
+		{"log.format", old.Log.Format, new.Log.Format},
+	} {
+		if !reflect.DeepEqual(section.old, section.new) {
+			needRestart = append(needRestart, section.key)
+		}
+	}
+
+	old.Version = new.Version
+	old.Targets = new.Targets
+	old.Budget = new.Budget
+	old.PollInterval = new.PollInterval
+	old.MaxDepth = new.MaxDepth
+	old.AsyncThreads = new.AsyncThreads
+	old.ShutdownTimeout = new.ShutdownTimeout
+	old.Log.Level = new.Log.Level
+	return needRestart
+}
+
+func logReload(result string, from, to int, changes []string, err error) {
+	configReloads.WithLabelValues(result).Inc()
+
+	diff := strings.Join(changes, "; ")
+	if diff == "" && err == nil {
+		diff = "none"
+	}
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelError
+	}
+	slog.Log(context.Background(), level, "Config reload", "result", result, "version_from", from, "version_to", to,
+		"changes", diff, "err", errString(err))
+}
+
+// configDiff summarizes what differs between two configs: changed
+// scalar settings and added, removed or modified targets by name
+func configDiff(old, new MonitorConfig) []string {
+	var changes []string
+	field := func(name string, a, b interface{}) {
+		if a != b {
+			changes = append(changes, fmt.Sprintf("%s %v -> %v", name, a, b))
+		}
+	}
+	field("poll_interval", old.PollInterval, new.PollInterval)
+	field("async_threads", old.AsyncThreads, new.AsyncThreads)
+	field("max_depth", old.MaxDepth, new.MaxDepth)
+	field("metrics_port", old.MetricsPort, new.MetricsPort)
+	field("budget.requests_per_minute", old.Budget.RequestsPerMinute, new.Budget.RequestsPerMinute)
+	field("proxy_pool.urls", len(old.ProxyPool.URLs), len(new.ProxyPool.URLs))
+
+	oldTargets := make(map[string]Target, len(old.Targets))
+	for _, t := range old.Targets {
+		oldTargets[t.Name] = t
+	}
+	var added, removed, modified []string
+	for _, t := range new.Targets {
+		prev, ok := oldTargets[t.Name]
+		switch {
+		case !ok:
+			added = append(added, t.Name)
+		case !sameTarget(prev, t):
+			modified = append(modified, t.Name)
+		}
+		delete(oldTargets, t.Name)
+	}
+	for name := range oldTargets {
+		removed = append(removed, name)
+	}
+	sort.Strings(removed)
+
+	for _, group := range []struct {
+		label string
+		names []string
+	}{{"targets added", added}, {"targets removed", removed}, {"targets modified", modified}} {
+		if len(group.names) > 0 {
+			changes = append(changes, fmt.Sprintf("%s: %s", group.label, strings.Join(group.names, ",")))
+		}
+	}
+	return changes
+}
+
+// sameTarget compares configured fields, ignoring compiled state
+func sameTarget(a, b Target) bool {
+	a.schedule, b.schedule = nil, nil
+	a.hotWindows, b.hotWindows = nil, nil
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"colosseo-orchestrator/internal/notify"
+	"github.com/gocolly/colly/v2"
+)
+
+// replayTransport answers every request with a captured body so a
+// collector runs its normal callbacks against those exact bytes
+type replayTransport struct {
+	body        []byte
+	contentType string // default text/html
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	contentType := t.contentType
+	if contentType == "" {
+		contentType = "text/html; charset=utf-8"
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{contentType}},
+		Body:          io.NopCloser(bytes.NewReader(t.body)),
+		ContentLength: int64(len(t.body)),
+		Request:       req,
+	}, nil
+}
+
+// replayChannel is the dispatcher's only channel in fixture replay: it
+// prints alerts instead of sending them and keeps the highest level
+// each target reached
+type replayChannel struct {
+	mu      sync.Mutex
+	highest map[string]notify.AlertLevel
+}
+
+func (r *replayChannel) Name() string { return "replay" }
+
+func (r *replayChannel) Accepts(notify.AlertLevel) bool { return true }
+
+func (r *replayChannel) Send(ctx context.Context, alert notify.Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if prev, ok := r.highest[alert.Target]; !ok || alert.Level > prev {
+		r.highest[alert.Target] = alert.Level
+	}
+	text := alert.Message
+	if text == "" {
+		text = fmt.Sprintf("%s (%s, %.0f%% confidence)", alert.Headline, alert.Availability, alert.Confidence*100)
+	}
+	fmt.Printf("  -> %s alert: %s\n", alert.Level, text)
+	return nil
+}
+
+// level returns the highest alert level seen for target, if any
+func (r *replayChannel) level(target string) (notify.AlertLevel, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	level, ok := r.highest[target]
+	return level, ok
+}
+
+// runReplay re-runs captured responses through the detection pipeline
+// offline. With --file, one target's selectors are evaluated:
+//
+//	orchestrator replay --target colosseo-arena-march-15 --file body.html
+//
+// With --dir, every target with a fixture is polled against it through
+// the full pipeline, alert dispatch included, with alerts printed rather
+// than sent. Fixtures are named after the target: <dir>/<target>.html,
+// or <dir>/<target>.json for JSON targets; targets without one are
+// skipped. Each fixture is polled as many times as the target's
+// escalation needs (or --polls), so a known-available page reaches
+// Critical. --expect fails the run when a replayed target's highest
+// alert is below the given level, for CI:
+//
+//	orchestrator replay --dir fixtures/available --expect critical
+//
+// The collector is built by the same createCollector the live monitors
+// use, so selector results match what production would have seen.
+// Confirmation polls and screenshots, which would reach the live site,
+// are off.
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	targetName := fs.String("target", "", "target name from the config")
+	file := fs.String("file", "", "captured response body to evaluate")
+	dir := fs.String("dir", "", "directory of <target>.html/.json fixtures to replay through the alert pipeline")
+	polls := fs.Int("polls", 0, "polls per fixture (default: the target's escalation polls)")
+	expect := fs.String("expect", "", "fail unless every replayed target alerts at this level or higher")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dir != "" {
+		return runFixtureReplay(*dir, *targetName, *polls, *expect)
+	}
+	if *targetName == "" || *file == "" {
+		return fmt.Errorf("--target and --file, or --dir, are required")
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+
+	target, err := findTarget(cfg.Targets, *targetName)
+	if err != nil {
+		return err
+	}
+
+	body, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+
+	// No rate limit delay when nothing goes over the network
+	cfg.PollInterval = 0
+
+	state := NewStateRegistry().Get(target.Name)
+	c := createCollector(target, cfg, nil, state, nil, nil, nil)
+	c.WithTransport(&replayTransport{body: body})
+
+	var available, soldOut int
+	c.OnHTML(target.Selectors["available"], func(e *colly.HTMLElement) {
+		available++
+	})
+	c.OnHTML(target.Selectors["sold_out"], func(e *colly.HTMLElement) {
+		soldOut++
+	})
+
+	if err := c.Visit(target.URL); err != nil {
+		return fmt.Errorf("visit: %w", err)
+	}
+	c.Wait()
+
+	goal := target.goal()
+	confidence := visitConfidence(goal, state.EndVisit())
+
+	status := "uncertain"
+	switch {
+	case available > 0:
+		status = "available"
+	case soldOut > 0:
+		status = "sold_out"
+	}
+
+	fmt.Printf("Target:       %s\n", target.Name)
+	fmt.Printf("File:         %s (%d bytes)\n", *file, len(body))
+	fmt.Printf("available:    %d match(es) for %q\n", available, target.Selectors["available"])
+	fmt.Printf("sold_out:     %d match(es) for %q\n", soldOut, target.Selectors["sold_out"])
+	fmt.Printf("Availability: %s\n", status)
+	fmt.Printf("Goal:         %s (%.0f%% confidence)\n", goal, confidence*100)
+
+	return nil
+}
+
+// runFixtureReplay polls each target's fixture in dir through the alert
+// pipeline; see runReplay
+func runFixtureReplay(dir, only string, polls int, expect string) error {
+	var want notify.AlertLevel
+	if expect != "" {
+		level, err := notify.ParseLevel(expect)
+		if err != nil {
+			return fmt.Errorf("--expect: %w", err)
+		}
+		want = level
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	targets := cfg.Targets
+	if only != "" {
+		target, err := findTarget(cfg.Targets, only)
+		if err != nil {
+			return err
+		}
+		targets = []Target{target}
+	}
+
+	// No rate limit delay when nothing goes over the network
+	cfg.PollInterval = 0
+
+	alerts := &replayChannel{highest: make(map[string]notify.AlertLevel)}
+	dispatcher := &notify.Dispatcher{}
+	dispatcher.Register(alerts)
+	registry := NewStateRegistry()
+
+	var replayed, failed []string
+	for _, target := range targets {
+		ext, contentType := ".html", "text/html; charset=utf-8"
+		if target.JSON.enabled() {
+			ext, contentType = ".json", "application/json"
+		}
+		path := filepath.Join(dir, target.Name+ext)
+		body, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Printf("%s: no fixture %s, skipped\n", target.Name, path)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		target.Confirm.Enabled = false
+		target.Screenshot.Enabled = false
+		target.VisitedTTL = 0
+		n := polls
+		if n <= 0 {
+			n = max(target.Escalation.Polls, 1)
+		}
+
+		fmt.Printf("%s: replaying %s (%d poll(s))\n", target.Name, path, n)
+		state := registry.Get(target.Name)
+		c := createCollector(target, cfg, nil, state, dispatcher, nil, nil)
+		c.WithTransport(&replayTransport{body: body, contentType: contentType})
+		for i := 0; i < n; i++ {
+			if err := c.Visit(target.URL); err != nil {
+				return fmt.Errorf("%s: visit: %w", target.Name, err)
+			}
+			c.Wait()
+		}
+		replayed = append(replayed, target.Name)
+
+		level, ok := alerts.level(target.Name)
+		if expect != "" && (!ok || level < want) {
+			got := "no alert"
+			if ok {
+				got = level.String()
+			}
+			fmt.Printf("%s: expected %s, got %s\n", target.Name, want, got)
+			failed = append(failed, target.Name)
+		}
+	}
+
+	if len(replayed) == 0 {
+		return fmt.Errorf("no fixtures for the configured targets in %s", dir)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d target(s) below %s: %s", len(failed), len(replayed), want, strings.Join(failed, ", "))
+	}
+	return nil
+}
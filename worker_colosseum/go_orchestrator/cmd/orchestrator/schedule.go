@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ScheduleConfig restricts polling to configured weekly windows
+type ScheduleConfig struct {
+	Timezone string         `mapstructure:"timezone"`
+	Windows  []WindowConfig `mapstructure:"windows"`
+}
+
+// WindowConfig is one active time range on the given days. A window
+// whose end is before its start runs past midnight into the next day.
+type WindowConfig struct {
+	Days  []string `mapstructure:"days"`  // "mon".."sun"; empty means every day
+	Start string   `mapstructure:"start"` // "HH:MM"
+	End   string   `mapstructure:"end"`   // "HH:MM", "24:00" for end of day
+}
+
+// Schedule is a validated ScheduleConfig. A nil Schedule is always active.
+type Schedule struct {
+	loc     *time.Location
+	windows []window
+}
+
+type window struct {
+	days       [7]bool
+	start, end int // minutes since local midnight
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Compile validates the config and returns a Schedule, or nil when no
+// windows are configured
+func (c ScheduleConfig) Compile() (*Schedule, error) {
+	if len(c.Windows) == 0 {
+		return nil, nil
+	}
+
+	tz := c.Timezone
+	if tz == "" {
+		tz = "UTC"
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("timezone %q: %w", tz, err)
+	}
+
+	s := &Schedule{loc: loc}
+	for i, wc := range c.Windows {
+		var w window
+		if w.start, err = parseClock(wc.Start); err != nil {
+			return nil, fmt.Errorf("window %d start: %w", i, err)
+		}
+		if w.end, err = parseClock(wc.End); err != nil {
+			return nil, fmt.Errorf("window %d end: %w", i, err)
+		}
+		if w.start == w.end {
+			return nil, fmt.Errorf("window %d: start equals end", i)
+		}
+		if w.start == 24*60 {
+			return nil, fmt.Errorf("window %d: start cannot be 24:00", i)
+		}
+
+		if len(wc.Days) == 0 {
+			for d := range w.days {
+				w.days[d] = true
+			}
+		}
+		for _, name := range wc.Days {
+			d, ok := weekdays[strings.ToLower(name)]
+			if !ok {
+				return nil, fmt.Errorf("window %d: unknown day %q", i, name)
+			}
+			w.days[d] = true
+		}
+
+		s.windows = append(s.windows, w)
+	}
+
+	return s, nil
+}
+
+// Active reports whether t falls inside any window. Evaluation uses
+// local wall-clock time, so windows track DST shifts.
+func (s *Schedule) Active(t time.Time) bool {
+	if s == nil {
+		return true
+	}
+
+	local := t.In(s.loc)
+	minute := local.Hour()*60 + local.Minute()
+	today := local.Weekday()
+	yesterday := (today + 6) % 7
+
+	for _, w := range s.windows {
+		if w.start < w.end {
+			if w.days[today] && minute >= w.start && minute < w.end {
+				return true
+			}
+			continue
+		}
+		// Overnight window: tail of yesterday's or head of today's
+		if (w.days[today] && minute >= w.start) || (w.days[yesterday] && minute < w.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextStart returns the next window start after t, or the zero time for
+// a nil Schedule
+func (s *Schedule) NextStart(t time.Time) time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+
+	local := t.In(s.loc)
+	var next time.Time
+	for day := 0; day <= 7; day++ {
+		date := local.AddDate(0, 0, day)
+		for _, w := range s.windows {
+			if !w.days[date.Weekday()] {
+				continue
+			}
+			start := time.Date(date.Year(), date.Month(), date.Day(), w.start/60, w.start%60, 0, 0, s.loc)
+			if start.After(t) && (next.IsZero() || start.Before(next)) {
+				next = start
+			}
+		}
+		if !next.IsZero() {
+			return next
+		}
+	}
+	return next
+}
+
+// parseClock parses "HH:MM" into minutes since midnight, allowing "24:00"
+func parseClock(v string) (int, error) {
+	if v == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, want HH:MM", v)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
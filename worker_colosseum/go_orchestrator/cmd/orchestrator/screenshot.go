@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/chromedp"
+)
+
+// defaultScreenshotTimeout bounds a capture, browser start included
+const defaultScreenshotTimeout = 8 * time.Second
+
+// ScreenshotConfig attaches a rendered screenshot to Critical alerts, as
+// proof the page really showed availability (opt-in)
+type ScreenshotConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Timeout time.Duration `mapstructure:"timeout"` // default 8s
+	// Selector is the element captured; default the target's "available"
+	// selector, and the whole viewport when nothing matches
+	Selector string `mapstructure:"selector"`
+	// RemoteURL is the DevTools websocket of a running Chrome, e.g. a
+	// sidecar container; empty launches a local headless Chrome
+	RemoteURL string `mapstructure:"remote_url"`
+}
+
+func (c ScreenshotConfig) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return defaultScreenshotTimeout
+	}
+	return c.Timeout
+}
+
+// captureScreenshot renders the target in headless Chrome and returns a
+// PNG of the availability area. colly only sees HTML, so this is a
+// separate load of the page, made only for alerts about to be sent.
+func captureScreenshot(target Target) ([]byte, error) {
+	cfg := target.Screenshot
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.timeout())
+	defer cancel()
+
+	var allocCtx context.Context
+	var cancelAlloc context.CancelFunc
+	if cfg.RemoteURL != "" {
+		allocCtx, cancelAlloc = chromedp.NewRemoteAllocator(ctx, cfg.RemoteURL)
+	} else {
+		opts := append(chromedp.DefaultExecAllocatorOptions[:], chromedp.UserAgent(randomUserAgent()))
+		allocCtx, cancelAlloc = chromedp.NewExecAllocator(ctx, opts...)
+	}
+	defer cancelAlloc()
+	browserCtx, cancelBrowser := chromedp.NewContext(allocCtx)
+	defer cancelBrowser()
+
+	sel := cfg.Selector
+	if sel == "" {
+		sel = target.Selectors["available"]
+	}
+
+	// Waiting on the selector could eat the whole timeout when it doesn't
+	// match, so look once after load and fall back to the viewport
+	var nodes []*cdp.Node
+	var png []byte
+	err := chromedp.Run(browserCtx,
+		chromedp.EmulateViewport(1280, 900),
+		chromedp.Navigate(target.URL),
+		chromedp.Nodes(sel, &nodes, chromedp.ByQueryAll, chromedp.AtLeast(0)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("load page: %w", err)
+	}
+	if len(nodes) > 0 {
+		err = chromedp.Run(browserCtx, chromedp.ScreenshotNodes(nodes[:1], 1, &png))
+	} else {
+		err = chromedp.Run(browserCtx, chromedp.CaptureScreenshot(&png))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("capture: %w", err)
+	}
+	return png, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"colosseo-orchestrator/internal/notify"
+)
+
+// defaultSelectorStaleness is how long a target's pages may go without
+// the available or sold_out selector matching before they count as stale
+const defaultSelectorStaleness = time.Hour
+
+func (t Target) selectorStaleness() time.Duration {
+	if t.SelectorStaleness <= 0 {
+		return defaultSelectorStaleness
+	}
+	return t.SelectorStaleness
+}
+
+// checkSelectors catches the venue changing its HTML: every page should
+// match either the available or the sold_out selector, so pages matching
+// neither for the staleness window mean the selectors no longer fit and
+// the monitor is polling blind. Warns once per stale spell. Challenge
+// and region block pages say nothing about the selectors and are skipped.
+func checkSelectors(target Target, state *TargetState, dispatcher *notify.Dispatcher) {
+	m := state.EndVisit()
+	if m.Challenge != "" || m.GeoBlocked {
+		return
+	}
+
+	now := time.Now()
+	matched := m.Available || m.SoldOut
+	if matched {
+		selectorLastMatch.WithLabelValues(target.Name).Set(float64(now.Unix()))
+	}
+	since := now.Sub(state.ObserveSelectors(matched, now))
+	window := target.selectorStaleness()
+
+	stale := since >= window
+	if !state.SetSelectorsStale(stale) {
+		return
+	}
+	if !stale {
+		slog.Info("Selectors are matching again", "target", target.Name)
+		return
+	}
+
+	slog.Warn("Neither the available nor the sold_out selector has matched", "target", target.Name, "since", since.Round(time.Second))
+	if dispatcher == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := dispatcher.Dispatch(ctx, notify.Alert{
+		Level:     notify.Warning,
+		Timestamp: now,
+		Target:    target.Name,
+		Message: fmt.Sprintf("Selectors appear stale for %s: neither available nor sold_out has matched for %s; the page layout may have changed",
+			target.Name, since.Round(time.Second)),
+		Metadata: map[string]interface{}{
+			"available_selector": target.Selectors["available"],
+			"sold_out_selector":  target.Selectors["sold_out"],
+			"window_seconds":     window.Seconds(),
+		},
+	})
+	if err != nil {
+		slog.Error("Failed to send selector staleness warning", "target", target.Name, "err", err)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"colosseo-orchestrator/internal/notify"
+)
+
+// defaultShadowChannel receives shadow disagreement reports
+const defaultShadowChannel = "telegram"
+
+// ShadowConfig runs candidate selectors against every response alongside
+// the live ones. The live selectors stay authoritative; disagreements are
+// reported to one channel, outside the normal alert routes.
+type ShadowConfig struct {
+	Selectors map[string]string `mapstructure:"selectors"`
+	// Channel receives disagreement reports (default "telegram")
+	Channel string `mapstructure:"channel"`
+}
+
+var shadowDisagreements = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "colosseo_shadow_disagreements_total",
+		Help: "Visits where shadow selectors disagreed with the live ones, by selector",
+	},
+	[]string{"target", "selector"},
+)
+
+// registerShadow hooks the target's shadow selectors into the collector
+func registerShadow(c *colly.Collector, target Target, state *TargetState) {
+	for key, sel := range target.Shadow.Selectors {
+		if sel == "" {
+			continue
+		}
+		key := key // capture loop var
+		c.OnHTML(sel, func(e *colly.HTMLElement) {
+			state.MarkShadow(key)
+		})
+	}
+}
+
+// liveMatched reports whether the live selector for key matched
+func liveMatched(m VisitMatches, key string) bool {
+	switch key {
+	case "available":
+		return m.Available
+	case "sold_out":
+		return m.SoldOut
+	case "goal":
+		return m.Goal
+	case "price":
+		return m.PriceText != ""
+	case "deep_link":
+		return m.DeepLink != ""
+	default:
+		return false
+	}
+}
+
+// checkShadow compares the visit's live and shadow matches. A report
+// goes out only when the set of disagreements changes, so a persistent
+// difference isn't repeated every poll.
+func checkShadow(target Target, state *TargetState, dispatcher *notify.Dispatcher) {
+	if len(target.Shadow.Selectors) == 0 {
+		return
+	}
+	live := state.EndVisit()
+	shadow := state.ShadowMatches()
+
+	var diffs []string
+	for key := range target.Shadow.Selectors {
+		if l, s := liveMatched(live, key), shadow[key]; l != s {
+			shadowDisagreements.WithLabelValues(target.Name, key).Inc()
+			diffs = append(diffs, fmt.Sprintf("%s: live=%t shadow=%t", key, l, s))
+		}
+	}
+	sort.Strings(diffs)
+	summary := strings.Join(diffs, ", ")
+	if !state.SetShadowDiff(summary) {
+		return
+	}
+
+	if summary == "" {
+		slog.Info("Shadow selectors agree with live again", "target", target.Name)
+		summary = "shadow selectors agree with live again"
+	} else {
+		slog.Warn("Shadow selectors disagree", "target", target.Name, "summary", summary)
+	}
+	if dispatcher == nil {
+		return
+	}
+
+	channel := target.Shadow.Channel
+	if channel == "" {
+		channel = defaultShadowChannel
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := dispatcher.SendTo(ctx, channel, notify.Alert{
+		Level:     notify.Info,
+		Timestamp: time.Now(),
+		Target:    target.Name,
+		Message:   fmt.Sprintf("Shadow selectors for %s: %s", target.Name, summary),
+		Metadata:  map[string]interface{}{"shadow": true},
+	})
+	if err != nil {
+		slog.Error("Failed to report shadow disagreement", "target", target.Name, "err", err)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"colosseo-orchestrator/internal/notify"
+)
+
+// ShutdownReason classifies why the orchestrator stopped
+type ShutdownReason string
+
+const (
+	ReasonSignal ShutdownReason = "signal"
+	ReasonConfig ShutdownReason = "config"
+	ReasonRedis  ShutdownReason = "redis"
+	ReasonServer ShutdownReason = "server"
+	ReasonPanic  ShutdownReason = "panic"
+)
+
+// defaultShutdownTimeout bounds the wait for monitors to finish their
+// in-flight poll
+const defaultShutdownTimeout = 30 * time.Second
+
+// shutdownTimeout returns ShutdownTimeout or its default
+func (c MonitorConfig) shutdownTimeout() time.Duration {
+	if c.ShutdownTimeout <= 0 {
+		return defaultShutdownTimeout
+	}
+	return c.ShutdownTimeout
+}
+
+// waitTimeout waits for wg up to d, reporting whether it finished
+func waitTimeout(wg *sync.WaitGroup, d time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(d):
+		return false
+	}
+}
+
+// exitCodes maps each reason to a distinct process exit code
+var exitCodes = map[ShutdownReason]int{
+	ReasonSignal: 0,
+	ReasonConfig: 2,
+	ReasonRedis:  3,
+	ReasonServer: 4,
+	ReasonPanic:  5,
+}
+
+// ShutdownError carries the cause of a shutdown up to main
+type ShutdownError struct {
+	Reason ShutdownReason
+	Err    error
+}
+
+func (e *ShutdownError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Reason, e.Err)
+}
+
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
+
+// shutdownErr wraps err with a shutdown reason
+func shutdownErr(reason ShutdownReason, err error) error {
+	return &ShutdownError{Reason: reason, Err: err}
+}
+
+// exitCode returns the process exit code for a shutdown error. Errors
+// without a reason exit with 1.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var se *ShutdownError
+	if errors.As(err, &se) {
+		if code, ok := exitCodes[se.Reason]; ok {
+			return code
+		}
+	}
+	return 1
+}
+
+// reportShutdown logs the shutdown cause and, when a dispatcher is
+// available, sends a final alert naming it: Info for a requested stop,
+// Critical for anything else
+func reportShutdown(err error, started time.Time, dispatcher *notify.Dispatcher) {
+	reason := ShutdownReason("unknown")
+	var se *ShutdownError
+	if errors.As(err, &se) {
+		reason = se.Reason
+	}
+
+	slog.Info("Shutdown", "reason", string(reason), "exit_code", exitCode(err),
+		"uptime", time.Since(started).Round(time.Second), "err", errString(err))
+
+	if dispatcher == nil {
+		return
+	}
+
+	level := notify.Critical
+	if reason == ReasonSignal {
+		level = notify.Info
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	alert := notify.Alert{
+		Level:     level,
+		Timestamp: time.Now(),
+		Target:    "orchestrator",
+		Message:   fmt.Sprintf("Orchestrator shutting down (%s): %s", reason, errString(err)),
+		Metadata: map[string]interface{}{
+			"reason":    string(reason),
+			"exit_code": exitCode(err),
+		},
+	}
+	if dispatchErr := dispatcher.Dispatch(ctx, alert); dispatchErr != nil {
+		slog.Error("Failed to send shutdown alert", "err", dispatchErr)
+	}
+	if closeErr := dispatcher.Close(); closeErr != nil {
+		slog.Error("Failed to flush notifications", "err", closeErr)
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
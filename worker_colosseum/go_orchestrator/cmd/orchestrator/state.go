@@ -0,0 +1,890 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"colosseo-orchestrator/internal/notify"
+)
+
+// defaultMaxBackoff caps the exponential failure backoff when a target doesn't set one
+const defaultMaxBackoff = 5 * time.Minute
+
+// defaultBackoffBase is the first failure backoff when a target doesn't set one
+const defaultBackoffBase = 10 * time.Second
+
+// backoffBase returns the target's first failure backoff. It is its own
+// setting: the request timeout says nothing about how long to back off.
+func (t Target) backoffBase() time.Duration {
+	if t.BackoffBase <= 0 {
+		return defaultBackoffBase
+	}
+	return t.BackoffBase
+}
+
+const (
+	// bodySizeAlpha is the EWMA smoothing factor for the body size baseline
+	bodySizeAlpha = 0.2
+	// bodySizeMinSamples is how many responses are needed before flagging anomalies
+	bodySizeMinSamples = 5
+	// defaultMinConfidence is the per-poll confidence counted as a detection
+	defaultMinConfidence = 0.5
+	// defaultCriticalConfidence is the per-poll confidence a detection
+	// needs to escalate to Critical
+	defaultCriticalConfidence = 0.8
+)
+
+// Availability is a target's current availability, exported as the
+// colosseo_target_available gauge value
+type Availability int
+
+const (
+	AvailabilitySoldOut Availability = iota
+	AvailabilityAvailable
+	AvailabilityUncertain
+	AvailabilityUnknown
+)
+
+func (a Availability) String() string {
+	switch a {
+	case AvailabilitySoldOut:
+		return "sold_out"
+	case AvailabilityAvailable:
+		return "available"
+	case AvailabilityUncertain:
+		return "uncertain"
+	default:
+		return "unknown"
+	}
+}
+
+// TargetState tracks runtime state for a single monitoring target
+type TargetState struct {
+	mu                  sync.Mutex
+	name                string
+	consecutiveFailures int
+	retryAfterUntil     time.Time
+	backoffUntil        time.Time
+	bodySize            int
+	bodySizeBaseline    float64
+	bodySizeSamples     int
+	bodySizeAnomaly     bool // last response's size was anomalous
+	paused              bool
+	pollNow             chan struct{}
+	inactiveUntil       time.Time
+	visit               VisitMatches
+	detectionStreak     int
+	detectionScore      float64
+	releaseProfile      *ReleaseProfile
+	pollInterval        time.Duration
+	lastAttempt         time.Time
+	lastSuccess         time.Time
+	activeSince         time.Time // staleness clock start after idling
+	availability        Availability
+	shadow              map[string]bool // shadow selectors matched this visit
+	shadowDiff          string          // last reported shadow disagreement
+	geoUnavailable      bool            // no correct-geo proxy at the last region block
+	lastAvailable       time.Time       // last poll that confirmed availability
+	availabilityStale   bool            // available per lastAvailable, but polls are failing
+	availabilityGrace   time.Duration   // how long a stale availability is kept
+	languageMismatch    bool            // last page was in the wrong language
+	challenge           string          // anti-bot vendor challenging polls, "" when clear
+	latency             []latencySample // completed polls within the SLO window
+	latencyBreach       bool            // p95 was over the SLO at the last poll
+	selectorMatch       time.Time       // last page matching available or sold_out
+	selectorsStale      bool            // no such page within the staleness window
+	hotSince            time.Time       // start of the current hot spell
+	hotUntil            time.Time       // hot mode lasts until, absent new near-misses
+	hotBlockedUntil     time.Time       // near-misses ignored after a spell hit its max
+	budgetInterval      time.Duration   // minimum interval under the global budget (0 = none)
+	budgetShare         float64         // fraction of the global budget
+	notified            Notified        // last availability status alerted
+	breaker             breakerState    // circuit breaker position
+	breakerUntil        time.Time       // an open breaker's cooldown end
+	failureRate         float64         // EWMA of polls ending in failure
+}
+
+// TargetStatus is a point-in-time view of a target's runtime state
+type TargetStatus struct {
+	Name                string     `json:"name"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	BackoffSeconds      float64    `json:"backoff_seconds"`
+	BodySize            int        `json:"body_size"`
+	BodySizeBaseline    float64    `json:"body_size_baseline"`
+	Paused              bool       `json:"paused"`
+	OutsideWindow       bool       `json:"outside_window"`
+	NextWindowStart     *time.Time `json:"next_window_start,omitempty"`
+	DetectionStreak     int        `json:"detection_streak"`
+	DetectionScore      float64    `json:"detection_score"`
+	PollIntervalSeconds float64    `json:"poll_interval_seconds"`
+	ReleaseProbability  float64    `json:"release_probability"`
+	LastAttempt         *time.Time `json:"last_attempt,omitempty"`
+	LastSuccess         *time.Time `json:"last_success,omitempty"`
+	Availability        string     `json:"availability"`
+	// AvailabilityStale marks an availability kept from before the
+	// current run of poll errors; its confidence decays to 0 over the grace
+	AvailabilityStale      bool    `json:"availability_stale,omitempty"`
+	AvailabilityConfidence float64 `json:"availability_confidence,omitempty"`
+	// LatencyP95Seconds is the p95 poll duration over the SLO window
+	LatencyP95Seconds float64 `json:"latency_p95_seconds"`
+	LatencyBreach     bool    `json:"latency_breach,omitempty"`
+	// SelectorsStale is set when neither the available nor the sold_out
+	// selector has matched a page within the target's staleness window
+	SelectorsStale bool `json:"selectors_stale,omitempty"`
+	// Challenge is the anti-bot vendor whose challenge pages polls are
+	// getting instead of the target, if any
+	Challenge string `json:"challenge,omitempty"`
+	// HotUntil is when hot mode ends, absent new near-misses
+	HotUntil *time.Time `json:"hot_until,omitempty"`
+	// BudgetIntervalSeconds is the fastest the global request budget
+	// lets the target poll, from its BudgetShare
+	BudgetIntervalSeconds float64 `json:"budget_interval_seconds,omitempty"`
+	BudgetShare           float64 `json:"budget_share,omitempty"`
+	// Breaker is the circuit breaker position: closed, open or half_open
+	// while a probe poll runs. BreakerUntil is an open breaker's
+	// cooldown end.
+	Breaker      string     `json:"breaker"`
+	BreakerUntil *time.Time `json:"breaker_until,omitempty"`
+	// FailureRate is the smoothed share of recent polls that failed,
+	// which stretches an adaptive target's interval
+	FailureRate float64 `json:"failure_rate"`
+}
+
+// StateRegistry holds runtime state for all targets
+type StateRegistry struct {
+	mu     sync.RWMutex
+	states map[string]*TargetState
+}
+
+// NewStateRegistry creates an empty state registry
+func NewStateRegistry() *StateRegistry {
+	return &StateRegistry{
+		states: make(map[string]*TargetState),
+	}
+}
+
+// Get returns the state for a target, creating it on first use
+func (r *StateRegistry) Get(name string) *TargetState {
+	r.mu.RLock()
+	s, ok := r.states[name]
+	r.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.states[name]; ok {
+		return s
+	}
+	s = &TargetState{name: name, pollNow: make(chan struct{}, 1), activeSince: time.Now(), availability: AvailabilityUnknown}
+	r.states[name] = s
+	targetAvailable.WithLabelValues(name).Set(float64(AvailabilityUnknown))
+	return s
+}
+
+// Retain drops the state of targets no longer configured, so removed
+// targets stop counting towards readiness and status
+func (r *StateRegistry) Retain(targets []Target) {
+	keep := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		keep[t.Name] = true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name := range r.states {
+		if !keep[name] {
+			delete(r.states, name)
+			targetAvailable.DeleteLabelValues(name)
+		}
+	}
+}
+
+// Lookup returns the state for a target without creating it
+func (r *StateRegistry) Lookup(name string) (*TargetState, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.states[name]
+	return s, ok
+}
+
+// Snapshot returns the status of every tracked target, sorted by name
+func (r *StateRegistry) Snapshot() []TargetStatus {
+	r.mu.RLock()
+	states := make([]*TargetState, 0, len(r.states))
+	for _, s := range r.states {
+		states = append(states, s)
+	}
+	r.mu.RUnlock()
+
+	result := make([]TargetStatus, 0, len(states))
+	for _, s := range states {
+		result = append(result, s.Status())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// Status returns a point-in-time view of the target's state
+func (s *TargetState) Status() TargetStatus {
+	wait := s.BackoffRemaining()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := TargetStatus{
+		Name:                s.name,
+		ConsecutiveFailures: s.consecutiveFailures,
+		BackoffSeconds:      wait.Seconds(),
+		BodySize:            s.bodySize,
+		BodySizeBaseline:    s.bodySizeBaseline,
+		Paused:              s.paused,
+		OutsideWindow:       !s.inactiveUntil.IsZero(),
+		DetectionStreak:     s.detectionStreak,
+		DetectionScore:      s.detectionScore,
+		PollIntervalSeconds: s.pollInterval.Seconds(),
+		ReleaseProbability:  s.releaseProfile.Probability(time.Now()),
+		Availability:        s.availability.String(),
+		LatencyP95Seconds:   percentile(s.latency, 0.95).Seconds(),
+		LatencyBreach:       s.latencyBreach,
+		SelectorsStale:      s.selectorsStale,
+		Challenge:           s.challenge,
+		Breaker:             s.breaker.String(),
+		FailureRate:         s.failureRate,
+	}
+	if s.breaker == breakerOpen {
+		until := s.breakerUntil
+		status.BreakerUntil = &until
+	}
+	if s.budgetShare > 0 {
+		status.BudgetIntervalSeconds = s.budgetInterval.Seconds()
+		status.BudgetShare = s.budgetShare
+	}
+	if s.availabilityStale {
+		// Past the grace window the next poll error falls to unknown;
+		// report it as such already
+		if confidence := s.staleConfidence(time.Now()); confidence > 0 {
+			status.AvailabilityStale = true
+			status.AvailabilityConfidence = confidence
+		} else {
+			status.Availability = AvailabilityUnknown.String()
+		}
+	}
+	if now := time.Now(); now.Before(s.hotUntil) {
+		until := s.hotUntil
+		status.HotUntil = &until
+	}
+	if status.OutsideWindow {
+		next := s.inactiveUntil
+		status.NextWindowStart = &next
+	}
+	if !s.lastAttempt.IsZero() {
+		at := s.lastAttempt
+		status.LastAttempt = &at
+	}
+	if !s.lastSuccess.IsZero() {
+		at := s.lastSuccess
+		status.LastSuccess = &at
+	}
+	return status
+}
+
+// SetInactiveUntil records that the target is outside its active window
+// until the given time (zero when inside). It reports whether the
+// inside/outside state changed.
+func (s *TargetState) SetInactiveUntil(until time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := s.inactiveUntil.IsZero() != until.IsZero()
+	s.inactiveUntil = until
+	if changed && until.IsZero() {
+		s.activeSince = time.Now()
+	}
+	return changed
+}
+
+// SetPaused pauses or resumes scheduled polling
+func (s *TargetState) SetPaused(paused bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paused && !paused {
+		s.activeSince = time.Now()
+	}
+	s.paused = paused
+}
+
+// Paused reports whether scheduled polling is paused
+func (s *TargetState) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// RequestPoll asks the monitor for an immediate out-of-schedule poll.
+// It returns false if a request is already pending.
+func (s *TargetState) RequestPoll() bool {
+	select {
+	case s.pollNow <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// PollRequests delivers pending manual poll requests to the monitor
+func (s *TargetState) PollRequests() <-chan struct{} {
+	return s.pollNow
+}
+
+// RecordSuccess clears the failure backoff after a successful poll
+func (s *TargetState) RecordSuccess() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastSuccess = time.Now()
+	s.consecutiveFailures = 0
+	s.backoffUntil = time.Time{}
+}
+
+// RecordFailure extends the exponential backoff after a failed poll.
+// The delay doubles per consecutive failure, starting at base and
+// capped at maxBackoff.
+func (s *TargetState) RecordFailure(base, maxBackoff time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
+	s.consecutiveFailures++
+	backoff := base
+	for i := 1; i < s.consecutiveFailures && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	s.backoffUntil = time.Now().Add(backoff)
+}
+
+// SetRetryAfter records the server's Retry-After hint. A later hint
+// never shortens one that is already in effect.
+func (s *TargetState) SetRetryAfter(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until := time.Now().Add(d)
+	if until.After(s.retryAfterUntil) {
+		s.retryAfterUntil = until
+	}
+}
+
+// BackoffRemaining returns how long the monitor must wait before the
+// next poll: the larger of our own backoff and the server's Retry-After.
+func (s *TargetState) BackoffRemaining() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until := s.backoffUntil
+	if s.retryAfterUntil.After(until) {
+		until = s.retryAfterUntil
+	}
+	if wait := time.Until(until); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// ObservePoll folds the completed poll's outcome, failed if it left the
+// target failing, into the failure rate
+func (s *TargetState) ObservePoll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	failed := 0.0
+	if s.consecutiveFailures > 0 {
+		failed = 1
+	}
+	s.failureRate = failureRateAlpha*failed + (1-failureRateAlpha)*s.failureRate
+}
+
+// FailureRate returns the smoothed share of recent polls that failed
+func (s *TargetState) FailureRate() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.failureRate
+}
+
+// BreakerWait returns how long an open breaker still holds polls back.
+// Once its cooldown has passed the breaker goes half-open and probe
+// reports that the next poll is its probe.
+func (s *TargetState) BreakerWait(now time.Time) (wait time.Duration, probe bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.breaker != breakerOpen {
+		return 0, false
+	}
+	if wait := s.breakerUntil.Sub(now); wait > 0 {
+		return wait, false
+	}
+	s.breaker = breakerHalfOpen
+	return 0, true
+}
+
+// UpdateBreaker moves the breaker after a poll: it opens once threshold
+// polls in a row have failed, and a half-open probe closes it on
+// success or reopens it for another cooldown.
+func (s *TargetState) UpdateBreaker(threshold int, cooldown time.Duration, now time.Time) (from, to breakerState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	from = s.breaker
+	switch {
+	case s.breaker == breakerHalfOpen && s.consecutiveFailures == 0:
+		s.breaker = breakerClosed
+	case s.breaker == breakerHalfOpen, s.breaker == breakerClosed && s.consecutiveFailures >= threshold:
+		s.breaker = breakerOpen
+		s.breakerUntil = now.Add(cooldown)
+	}
+	return from, s.breaker
+}
+
+// ObserveBodySize folds a response size into the rolling baseline and
+// reports whether it deviates from the previous baseline by more than
+// factor in either direction. A factor <= 1 disables detection.
+func (s *TargetState) ObserveBodySize(size int, factor float64) (baseline float64, anomalous bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	baseline = s.bodySizeBaseline
+	if factor > 1 && s.bodySizeSamples >= bodySizeMinSamples && baseline > 0 {
+		ratio := float64(size) / baseline
+		anomalous = ratio > factor || ratio < 1/factor
+	}
+
+	s.bodySize = size
+	if s.bodySizeSamples == 0 {
+		s.bodySizeBaseline = float64(size)
+	} else {
+		s.bodySizeBaseline = bodySizeAlpha*float64(size) + (1-bodySizeAlpha)*s.bodySizeBaseline
+	}
+	s.bodySizeSamples++
+
+	return baseline, anomalous
+}
+
+// SetBodySizeAnomaly records whether the last response's size was
+// anomalous, reporting whether that changed
+func (s *TargetState) SetBodySizeAnomaly(anomalous bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := anomalous != s.bodySizeAnomaly
+	s.bodySizeAnomaly = anomalous
+	return changed
+}
+
+// BeginVisit resets the per-visit selector matches and records the attempt
+func (s *TargetState) BeginVisit() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastAttempt = time.Now()
+	s.visit = VisitMatches{}
+	s.shadow = nil
+}
+
+// MarkMatch records that the named selector ("available", "sold_out",
+// "goal" or "geo_block") matched during the current visit
+func (s *TargetState) MarkMatch(selector string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch selector {
+	case "available":
+		s.visit.Available = true
+	case "sold_out":
+		s.visit.SoldOut = true
+	case "goal":
+		s.visit.Goal = true
+	case "geo_block":
+		s.visit.GeoBlocked = true
+	}
+}
+
+// MarkJSONPage folds one page of a JSON target into the current visit.
+// The visit is sold out once entries were seen and none was open.
+func (s *TargetState) MarkJSONPage(entries int, openings []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.visit.Entries += entries
+	s.visit.Openings = append(s.visit.Openings, openings...)
+	s.visit.Available = len(s.visit.Openings) > 0
+	s.visit.SoldOut = s.visit.Entries > 0 && !s.visit.Available
+}
+
+// MarkLanguage records the first language marker found in the current visit
+func (s *TargetState) MarkLanguage(lang string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.visit.Language == "" {
+		s.visit.Language = lang
+	}
+}
+
+// SetLanguageMismatch records whether the last page was in the wrong
+// language and reports whether that changed
+func (s *TargetState) SetLanguageMismatch(mismatch bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := mismatch != s.languageMismatch
+	s.languageMismatch = mismatch
+	return changed
+}
+
+// MarkResponse records the status and body size sanity of the response
+// the current visit's selectors run on
+func (s *TargetState) MarkResponse(statusCode int, sizeAnomaly bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.visit.StatusCode = statusCode
+	s.visit.SizeAnomaly = sizeAnomaly
+}
+
+// MarkChallenge records that the current visit got an anti-bot
+// challenge page from vendor
+func (s *TargetState) MarkChallenge(vendor string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.visit.Challenge = vendor
+}
+
+// SetChallenge records the vendor challenging polls ("" once a page gets
+// through), reporting whether that changed
+func (s *TargetState) SetChallenge(vendor string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := vendor != s.challenge
+	s.challenge = vendor
+	return changed
+}
+
+// MarkProxy records the proxy the current visit's response came through
+func (s *TargetState) MarkProxy(proxyURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.visit.Proxy = proxyURL
+}
+
+// SetGeoUnavailable records whether a correct-geo proxy was missing at
+// the last region block and reports whether that changed
+func (s *TargetState) SetGeoUnavailable(unavailable bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := unavailable != s.geoUnavailable
+	s.geoUnavailable = unavailable
+	return changed
+}
+
+// MarkPrice records the text of the first price match in the current visit
+func (s *TargetState) MarkPrice(text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.visit.PriceText == "" {
+		s.visit.PriceText = text
+	}
+}
+
+// MarkDeepLink records the first booking deep link found in the current visit
+func (s *TargetState) MarkDeepLink(link string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.visit.DeepLink == "" {
+		s.visit.DeepLink = link
+	}
+}
+
+// MarkShadow records that the shadow selector for key matched during
+// the current visit
+func (s *TargetState) MarkShadow(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shadow == nil {
+		s.shadow = make(map[string]bool)
+	}
+	s.shadow[key] = true
+}
+
+// ShadowMatches returns which shadow selectors matched during the current visit
+func (s *TargetState) ShadowMatches() map[string]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	matches := make(map[string]bool, len(s.shadow))
+	for k, v := range s.shadow {
+		matches[k] = v
+	}
+	return matches
+}
+
+// SetShadowDiff stores the visit's shadow disagreement summary and
+// reports whether it changed since the last visit
+func (s *TargetState) SetShadowDiff(diff string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := diff != s.shadowDiff
+	s.shadowDiff = diff
+	return changed
+}
+
+// ObserveLatency records the duration of the visit completing now and
+// returns it with the p95 and sample count over the trailing window
+func (s *TargetState) ObserveLatency(now time.Time, window time.Duration) (duration, p95 time.Duration, samples int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	duration = now.Sub(s.lastAttempt)
+
+	cutoff := now.Add(-window)
+	kept := s.latency[:0]
+	for _, sample := range s.latency {
+		if sample.at.After(cutoff) {
+			kept = append(kept, sample)
+		}
+	}
+	s.latency = append(kept, latencySample{at: now, duration: duration})
+	return duration, percentile(s.latency, 0.95), len(s.latency)
+}
+
+// SetLatencyBreach records whether the latency SLO is breached and
+// reports whether that changed
+func (s *TargetState) SetLatencyBreach(breached bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := breached != s.latencyBreach
+	s.latencyBreach = breached
+	return changed
+}
+
+// ObserveSelectors records whether a page matched the available or
+// sold_out selector and returns when one last did. Until the first match
+// the clock runs from the first page observed.
+func (s *TargetState) ObserveSelectors(matched bool, now time.Time) (lastMatch time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if matched || s.selectorMatch.IsZero() {
+		s.selectorMatch = now
+	}
+	return s.selectorMatch
+}
+
+// SetSelectorsStale records whether the selectors are stale and reports
+// whether that changed
+func (s *TargetState) SetSelectorsStale(stale bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := stale != s.selectorsStale
+	s.selectorsStale = stale
+	return changed
+}
+
+// EndVisit returns which selectors matched during the current visit
+func (s *TargetState) EndVisit() VisitMatches {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.visit
+}
+
+// RecordDetection folds one poll's confidence into the consecutive
+// detection streak. A poll below the minimum confidence breaks the
+// streak. Detections stay Warning until the streak reaches cfg.Polls,
+// then escalate to Critical if this poll's confidence reaches the
+// critical threshold; below it the evidence is too ambiguous and the
+// detection stays Warning.
+func (s *TargetState) RecordDetection(confidence float64, cfg EscalationConfig) (level notify.AlertLevel, streak int, detected bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if confidence < cfg.minConfidence() {
+		s.detectionStreak = 0
+		s.detectionScore = 0
+		return notify.Info, 0, false
+	}
+
+	s.detectionStreak++
+	s.detectionScore += confidence
+
+	if s.detectionStreak >= cfg.Polls && confidence >= cfg.criticalConfidence() {
+		return notify.Critical, s.detectionStreak, true
+	}
+	return notify.Warning, s.detectionStreak, true
+}
+
+// ClaimAlert reports whether a detection of status at level should be
+// alerted: on a change of status, or on escalation to a higher level of
+// the same status. A status change within cooldown of the last alert is
+// recorded but not alerted, so a flapping page doesn't spam; escalation
+// is never held back. A true result records the alert, and prev is what
+// to restore if it can't be sent.
+func (s *TargetState) ClaimAlert(status notify.AvailabilityStatus, level notify.AlertLevel, cooldown time.Duration, now time.Time) (prev Notified, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev = s.notified
+	switch {
+	case prev.Status == status && level <= prev.Level:
+		return prev, false
+	case prev.Status != status && !prev.At.IsZero() && now.Sub(prev.At) < cooldown:
+		s.notified.Status = status
+		s.notified.Level = level
+		return prev, false
+	}
+	s.notified = Notified{Status: status, Level: level, At: now}
+	return prev, true
+}
+
+// Notified returns the last availability status alerted
+func (s *TargetState) Notified() Notified {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.notified
+}
+
+// SetNotified replaces the last alerted status, e.g. from Redis at startup
+func (s *TargetState) SetNotified(n Notified) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notified = n
+}
+
+// SetAvailability records the target's current availability and
+// updates its gauge
+func (s *TargetState) SetAvailability(a Availability) {
+	s.mu.Lock()
+	s.availability = a
+	s.availabilityStale = false
+	if a == AvailabilityAvailable {
+		s.lastAvailable = time.Now()
+	}
+	s.mu.Unlock()
+	targetAvailable.WithLabelValues(s.name).Set(float64(a))
+}
+
+// RecordPollError updates availability after a failed poll. A target
+// last seen available stays available, flagged stale with decaying
+// confidence, until grace has passed since the last confirmation; after
+// that, or if it wasn't available, it becomes unknown. enteredStale is
+// true for the error that starts a stale period.
+func (s *TargetState) RecordPollError(grace time.Duration, now time.Time) (enteredStale bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.availability == AvailabilityAvailable && now.Sub(s.lastAvailable) < grace {
+		enteredStale = !s.availabilityStale
+		s.availabilityStale = true
+		s.availabilityGrace = grace
+		return enteredStale
+	}
+
+	s.availability = AvailabilityUnknown
+	s.availabilityStale = false
+	targetAvailable.WithLabelValues(s.name).Set(float64(AvailabilityUnknown))
+	return false
+}
+
+// staleConfidence decays linearly from 1 at the last confirmation to 0
+// at the end of the grace window. Callers hold s.mu.
+func (s *TargetState) staleConfidence(now time.Time) float64 {
+	if s.availabilityGrace <= 0 {
+		return 0
+	}
+	left := 1 - float64(now.Sub(s.lastAvailable))/float64(s.availabilityGrace)
+	if left < 0 {
+		return 0
+	}
+	return left
+}
+
+// SetReleaseProfile stores the latest computed release profile
+func (s *TargetState) SetReleaseProfile(p *ReleaseProfile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releaseProfile = p
+}
+
+// ReleaseProfile returns the latest release profile (nil until computed)
+func (s *TargetState) ReleaseProfile() *ReleaseProfile {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.releaseProfile
+}
+
+// TriggerHot enters or extends hot mode after a near-miss at now and
+// reports whether the target is hot. A spell is cut off at cfg's max,
+// after which near-misses are ignored for as long again.
+func (s *TargetState) TriggerHot(now time.Time, cfg HotModeConfig) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Before(s.hotBlockedUntil) {
+		return false
+	}
+	if !now.Before(s.hotUntil) {
+		s.hotSince = now
+	}
+	s.hotUntil = now.Add(cfg.hold())
+	if end := s.hotSince.Add(cfg.max()); s.hotUntil.After(end) {
+		// Persistent near-misses look like noise: end at the cap and cool off
+		s.hotUntil = end
+		s.hotBlockedUntil = end.Add(cfg.max())
+	}
+	return now.Before(s.hotUntil)
+}
+
+// Hot reports whether the target is in hot mode at now
+func (s *TargetState) Hot(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return now.Before(s.hotUntil)
+}
+
+// SetPollInterval records the monitor's current effective poll interval
+func (s *TargetState) SetPollInterval(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pollInterval = d
+}
+
+// PollInterval returns the monitor's current effective poll interval
+func (s *TargetState) PollInterval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pollInterval
+}
+
+// SetBudget records the target's share of the global request budget
+// and the minimum interval it allows
+func (s *TargetState) SetBudget(interval time.Duration, share float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.budgetInterval = interval
+	s.budgetShare = share
+}
+
+// BudgetInterval returns the minimum interval under the global budget,
+// 0 without one
+func (s *TargetState) BudgetInterval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.budgetInterval
+}
+
+// SuccessAge returns how long the target has gone without a successful
+// poll, counted from the later of the last success and the moment it
+// last became active. idle is set while paused or outside its window,
+// when no polls are expected.
+func (s *TargetState) SuccessAge(now time.Time) (age time.Duration, idle bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paused || !s.inactiveUntil.IsZero() {
+		return 0, true
+	}
+	since := s.activeSince
+	if s.lastSuccess.After(since) {
+		since = s.lastSuccess
+	}
+	return now.Sub(since), false
+}
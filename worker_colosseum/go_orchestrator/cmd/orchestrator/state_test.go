@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordFailureDoublesFromBase(t *testing.T) {
+	state := NewStateRegistry().Get("tickets")
+	want := []time.Duration{
+		10 * time.Second, 20 * time.Second, 40 * time.Second, 80 * time.Second,
+		2 * time.Minute, 2 * time.Minute, // capped
+	}
+	for i, w := range want {
+		state.RecordFailure(10*time.Second, 2*time.Minute)
+		if got := state.BackoffRemaining(); got > w || got < w-time.Second {
+			t.Errorf("failure %d: backoff = %v, want %v", i+1, got, w)
+		}
+	}
+
+	state.RecordSuccess()
+	if got := state.BackoffRemaining(); got != 0 {
+		t.Errorf("backoff after a success = %v, want 0", got)
+	}
+}
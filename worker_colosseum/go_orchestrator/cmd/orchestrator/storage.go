@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// storageTimeout bounds each storage call; colly's cookie hooks can't
+// take a context or return an error
+const storageTimeout = 2 * time.Second
+
+// RedisStorage is a colly storage.Storage that keeps a collector's
+// visited requests and cookies in Redis, so sessions survive monitor
+// restarts and redeploys. Keys are namespaced by prefix:
+//
+//	<prefix>request:<id>  visited marker, expiring after visitedTTL
+//	<prefix>cookie:<host> the host's cookies, as colly serializes them
+//
+// Visited markers are what make IsVisited skip a re-crawl, so each one
+// expires on its own: the set of visited requests never outgrows the
+// requests seen within visitedTTL. Without a TTL nothing is recorded and
+// every request counts as unvisited.
+type RedisStorage struct {
+	client     *redis.Client
+	prefix     string
+	visitedTTL time.Duration
+}
+
+// Init checks that Redis is reachable
+func (s *RedisStorage) Init() error {
+	if s.client == nil {
+		return errors.New("redis storage: no client")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+	return s.client.Ping(ctx).Err()
+}
+
+// Visited marks requestID visited for visitedTTL
+func (s *RedisStorage) Visited(requestID uint64) error {
+	if s.visitedTTL <= 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+	return s.client.Set(ctx, s.requestKey(requestID), "1", s.visitedTTL).Err()
+}
+
+// IsVisited reports whether requestID was visited within visitedTTL
+func (s *RedisStorage) IsVisited(requestID uint64) (bool, error) {
+	if s.visitedTTL <= 0 {
+		return false, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+	n, err := s.client.Exists(ctx, s.requestKey(requestID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Cookies returns the stored cookies for u's host
+func (s *RedisStorage) Cookies(u *url.URL) string {
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+	cookies, err := s.client.Get(ctx, s.cookieKey(u)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		slog.Warn("Cookie load failed", "host", u.Host, "err", err)
+	}
+	return cookies
+}
+
+// SetCookies stores cookies for u's host
+func (s *RedisStorage) SetCookies(u *url.URL, cookies string) {
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+	if err := s.client.Set(ctx, s.cookieKey(u), cookies, 0).Err(); err != nil {
+		slog.Warn("Cookie save failed", "host", u.Host, "err", err)
+	}
+}
+
+func (s *RedisStorage) requestKey(requestID uint64) string {
+	return s.prefix + "request:" + strconv.FormatUint(requestID, 10)
+}
+
+func (s *RedisStorage) cookieKey(u *url.URL) string {
+	return s.prefix + "cookie:" + u.Host
+}
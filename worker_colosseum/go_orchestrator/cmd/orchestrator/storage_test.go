@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeRedis serves the handful of commands RedisStorage uses over RESP2,
+// with a clock the test advances to expire keys
+type fakeRedis struct {
+	mu      sync.Mutex
+	now     time.Time
+	values  map[string]string
+	expires map[string]time.Time
+}
+
+func newTestStorage(t *testing.T, visitedTTL time.Duration) (*RedisStorage, *fakeRedis) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &fakeRedis{
+		now:     time.Now(),
+		values:  make(map[string]string),
+		expires: make(map[string]time.Time),
+	}
+	go srv.serve(ln)
+
+	client := redis.NewClient(&redis.Options{Addr: ln.Addr().String(), Protocol: 2, DisableIndentity: true})
+	t.Cleanup(func() {
+		client.Close()
+		ln.Close()
+	})
+	return &RedisStorage{client: client, prefix: "colosseo:test:", visitedTTL: visitedTTL}, srv
+}
+
+// advance moves the server clock forward by d
+func (f *fakeRedis) advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+func (f *fakeRedis) serve(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		if _, err := io.WriteString(conn, f.exec(args)); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeRedis) exec(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "SET":
+		key := args[1]
+		f.values[key] = args[2]
+		delete(f.expires, key)
+		if len(args) == 5 {
+			n, _ := strconv.Atoi(args[4])
+			unit := time.Second
+			if strings.EqualFold(args[3], "px") {
+				unit = time.Millisecond
+			}
+			f.expires[key] = f.now.Add(time.Duration(n) * unit)
+		}
+		return "+OK\r\n"
+	case "GET":
+		v, ok := f.get(args[1])
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+	case "EXISTS":
+		n := 0
+		for _, key := range args[1:] {
+			if _, ok := f.get(key); ok {
+				n++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", n)
+	default:
+		return "-ERR unknown command '" + args[0] + "'\r\n"
+	}
+}
+
+// get returns key's value unless it has expired. Callers hold f.mu.
+func (f *fakeRedis) get(key string) (string, bool) {
+	if at, ok := f.expires[key]; ok && !f.now.Before(at) {
+		delete(f.values, key)
+		delete(f.expires, key)
+	}
+	v, ok := f.values[key]
+	return v, ok
+}
+
+// readCommand reads one RESP array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("bad command header %q", line)
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, fmt.Errorf("bad bulk header %q", line)
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func TestRedisStorageVisitedExpires(t *testing.T) {
+	s, srv := newTestStorage(t, time.Minute)
+	if err := s.Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	visited, err := s.IsVisited(42)
+	if err != nil || visited {
+		t.Fatalf("IsVisited before Visited = %v, %v; want false", visited, err)
+	}
+	if err := s.Visited(42); err != nil {
+		t.Fatalf("Visited: %v", err)
+	}
+	if visited, err := s.IsVisited(42); err != nil || !visited {
+		t.Fatalf("IsVisited after Visited = %v, %v; want true", visited, err)
+	}
+	if visited, _ := s.IsVisited(43); visited {
+		t.Error("IsVisited(43) = true, want only 42 visited")
+	}
+
+	srv.advance(59 * time.Second)
+	if visited, _ := s.IsVisited(42); !visited {
+		t.Error("IsVisited within the TTL = false, want true")
+	}
+	srv.advance(time.Second)
+	if visited, _ := s.IsVisited(42); visited {
+		t.Error("IsVisited after the TTL = true, want false")
+	}
+}
+
+func TestRedisStorageWithoutTTLRecordsNothing(t *testing.T) {
+	s, srv := newTestStorage(t, 0)
+
+	if err := s.Visited(42); err != nil {
+		t.Fatalf("Visited: %v", err)
+	}
+	if visited, err := s.IsVisited(42); err != nil || visited {
+		t.Errorf("IsVisited = %v, %v; want false", visited, err)
+	}
+	if len(srv.values) != 0 {
+		t.Errorf("stored %v, want nothing", srv.values)
+	}
+}
+
+func TestRedisStorageCookies(t *testing.T) {
+	s, srv := newTestStorage(t, time.Minute)
+	u, _ := url.Parse("https://ticketing.colosseo.it/en/tickets")
+	other, _ := url.Parse("https://example.com/")
+
+	if got := s.Cookies(u); got != "" {
+		t.Errorf("Cookies before SetCookies = %q, want empty", got)
+	}
+	s.SetCookies(u, "session=abc; lang=en")
+	if got := s.Cookies(u); got != "session=abc; lang=en" {
+		t.Errorf("Cookies = %q, want the stored cookies", got)
+	}
+	if got := s.Cookies(other); got != "" {
+		t.Errorf("Cookies for another host = %q, want empty", got)
+	}
+
+	// Cookies outlive visited markers
+	srv.advance(24 * time.Hour)
+	if got := s.Cookies(u); got != "session=abc; lang=en" {
+		t.Errorf("Cookies a day later = %q, want the stored cookies", got)
+	}
+	if _, ok := srv.values["colosseo:test:cookie:ticketing.colosseo.it"]; !ok {
+		t.Errorf("keys = %v, want colosseo:test:cookie:ticketing.colosseo.it", srv.values)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReloadConfig bounds how often config reloads may recreate monitors
+type ReloadConfig struct {
+	// Coalesce waits for edits to settle before restarting (default 2s)
+	Coalesce time.Duration `mapstructure:"coalesce"`
+	// MaxRestarts per target within Window (default 3); later restarts
+	// are deferred until the window allows them
+	MaxRestarts int           `mapstructure:"max_restarts"`
+	Window      time.Duration `mapstructure:"window"` // default 10m
+}
+
+// monitorSupervisor owns the running monitors and reconciles them
+// against reloaded targets: new targets start, removed ones stop and
+// changed ones restart, rate limited per target
+type monitorSupervisor struct {
+	ctx    context.Context
+	wg     *sync.WaitGroup
+	limits ReloadConfig
+	run    func(ctx context.Context, target Target)
+
+	mu       sync.Mutex
+	running  map[string]*monitorHandle
+	pending  map[string]*pendingRestart
+	restarts map[string][]time.Time
+}
+
+type monitorHandle struct {
+	target Target
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type pendingRestart struct {
+	target Target
+	timer  *time.Timer
+	forced bool // by RestartAll: due even if the target is unchanged
+}
+
+func newMonitorSupervisor(ctx context.Context, wg *sync.WaitGroup, limits ReloadConfig, run func(context.Context, Target)) *monitorSupervisor {
+	if limits.Coalesce <= 0 {
+		limits.Coalesce = 2 * time.Second
+	}
+	if limits.MaxRestarts <= 0 {
+		limits.MaxRestarts = 3
+	}
+	if limits.Window <= 0 {
+		limits.Window = 10 * time.Minute
+	}
+	return &monitorSupervisor{
+		ctx:      ctx,
+		wg:       wg,
+		limits:   limits,
+		run:      run,
+		running:  make(map[string]*monitorHandle),
+		pending:  make(map[string]*pendingRestart),
+		restarts: make(map[string][]time.Time),
+	}
+}
+
+// Reconcile brings the running monitors in line with targets
+func (s *monitorSupervisor) Reconcile(targets []Target) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		seen[t.Name] = true
+		h, ok := s.running[t.Name]
+		switch {
+		case !ok:
+			s.startLocked(t)
+		case !sameTarget(h.target, t):
+			s.scheduleLocked(t)
+		default:
+			// Edited and reverted before the restart fired
+			if p, ok := s.pending[t.Name]; ok && !p.forced {
+				p.timer.Stop()
+				delete(s.pending, t.Name)
+			}
+		}
+	}
+
+	for name, h := range s.running {
+		if seen[name] {
+			continue
+		}
+		if p, ok := s.pending[name]; ok {
+			p.timer.Stop()
+			delete(s.pending, name)
+		}
+		h.cancel()
+		delete(s.running, name)
+		slog.Info("Target removed from config, monitor stopped", "target", name)
+	}
+}
+
+// RestartAll schedules every monitor for a restart, as after a reload
+// changed a setting all collectors are built from. Call it after
+// Reconcile, so restarts already pending keep their reloaded target.
+func (s *monitorSupervisor) RestartAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, h := range s.running {
+		target := h.target
+		if p, ok := s.pending[name]; ok {
+			target = p.target
+		}
+		s.scheduleLocked(target)
+		s.pending[name].forced = true
+	}
+}
+
+// Running returns the targets whose monitor has not yet returned, in
+// name order
+func (s *monitorSupervisor) Running() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var names []string
+	for name, h := range s.running {
+		select {
+		case <-h.done:
+		default:
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// startLocked launches a monitor for t. Callers hold s.mu.
+func (s *monitorSupervisor) startLocked(t Target) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	done := make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer close(done)
+		s.run(ctx, t)
+	}()
+	s.running[t.Name] = &monitorHandle{target: t, cancel: cancel, done: done}
+}
+
+// scheduleLocked coalesces restarts: further edits within the coalesce
+// delay replace the pending target and push the restart back
+func (s *monitorSupervisor) scheduleLocked(t Target) {
+	if p, ok := s.pending[t.Name]; ok {
+		p.target = t
+		p.timer.Reset(s.limits.Coalesce)
+		return
+	}
+	name := t.Name
+	s.pending[name] = &pendingRestart{
+		target: t,
+		timer:  time.AfterFunc(s.limits.Coalesce, func() { s.restart(name) }),
+	}
+}
+
+// restart recreates a target's monitor, or defers it while the target is
+// over its restart budget
+func (s *monitorSupervisor) restart(name string) {
+	s.mu.Lock()
+	p, ok := s.pending[name]
+	if !ok || s.ctx.Err() != nil {
+		s.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	recent := s.restarts[name]
+	for len(recent) > 0 && !recent[0].After(now.Add(-s.limits.Window)) {
+		recent = recent[1:]
+	}
+	s.restarts[name] = recent
+
+	if len(recent) >= s.limits.MaxRestarts {
+		wait := recent[0].Add(s.limits.Window).Sub(now)
+		p.timer.Reset(wait)
+		s.mu.Unlock()
+		monitorRestarts.WithLabelValues(name, "deferred").Inc()
+		slog.Warn("Monitor restart deferred", "target", name, "wait", wait.Round(time.Second), "restarts", len(recent), "window", s.limits.Window)
+		return
+	}
+
+	delete(s.pending, name)
+	s.restarts[name] = append(recent, now)
+	old := s.running[name]
+	delete(s.running, name)
+	s.mu.Unlock()
+
+	// Let the old monitor finish its in-flight poll before replacing it
+	if old != nil {
+		old.cancel()
+		<-old.done
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.running[name]; exists || s.ctx.Err() != nil {
+		return
+	}
+	s.startLocked(p.target)
+	monitorRestarts.WithLabelValues(name, "restarted").Inc()
+	slog.Info("Monitor restarted with reloaded config", "target", name)
+}
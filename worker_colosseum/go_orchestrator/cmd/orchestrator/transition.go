@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"colosseo-orchestrator/internal/notify"
+)
+
+// notifiedKey holds the last availability announced for a target, so a
+// restart doesn't re-announce it
+const notifiedKey = "colosseo:notified:%s"
+
+// Notified is the last availability status alerted for a target
+type Notified struct {
+	Status notify.AvailabilityStatus
+	Level  notify.AlertLevel
+	At     time.Time
+}
+
+// loadNotified reads the target's last announced status; zero if none
+func loadNotified(ctx context.Context, client *redis.Client, target string) (Notified, error) {
+	fields, err := client.HGetAll(ctx, fmt.Sprintf(notifiedKey, target)).Result()
+	if err != nil || len(fields) == 0 {
+		return Notified{}, err
+	}
+	level, _ := strconv.Atoi(fields["level"])
+	at, _ := strconv.ParseInt(fields["at"], 10, 64)
+	return Notified{
+		Status: notify.AvailabilityStatus(fields["status"]),
+		Level:  notify.AlertLevel(level),
+		At:     time.UnixMilli(at),
+	}, nil
+}
+
+// saveNotified records the target's last announced status
+func saveNotified(ctx context.Context, client *redis.Client, target string, n Notified) error {
+	return client.HSet(ctx, fmt.Sprintf(notifiedKey, target),
+		"status", string(n.Status),
+		"level", int(n.Level),
+		"at", n.At.UnixMilli(),
+	).Err()
+}
+
+// restoreNotified seeds the target's state with its persisted status
+func restoreNotified(client *redis.Client, target Target, state *TargetState) {
+	if client == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	n, err := loadNotified(ctx, client, target.Name)
+	if err != nil {
+		slog.Warn("Failed to load last notified status", "target", target.Name, "err", err)
+		return
+	}
+	if n.Status != "" {
+		state.SetNotified(n)
+	}
+}
+
+// dispatchTransition sends alert for a transition claimed with
+// ClaimAlert and persists the new status. A failed send restores prev,
+// so the next poll tries again.
+func dispatchTransition(target Target, state *TargetState, redisClient *redis.Client, dispatcher *notify.Dispatcher, prev Notified, alert notify.Alert) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := dispatcher.Dispatch(ctx, alert); err != nil {
+		slog.Error("Failed to send alert", "target", target.Name, "availability", alert.Availability, "err", err)
+		state.SetNotified(prev)
+		return
+	}
+	if redisClient == nil {
+		return
+	}
+	if err := saveNotified(ctx, redisClient, target.Name, state.Notified()); err != nil {
+		slog.Warn("Failed to persist notified status", "target", target.Name, "err", err)
+	}
+}
+
+// announceReversal alerts when a visit unambiguously shows the opposite
+// of the goal last announced, e.g. tickets sold out again after an
+// availability alert, so the next detection counts as a new transition
+func announceReversal(target Target, state *TargetState, redisClient *redis.Client, dispatcher *notify.Dispatcher, m VisitMatches) {
+	var observed notify.AvailabilityStatus
+	switch {
+	case m.Available && !m.SoldOut:
+		observed = notify.Available
+	case m.SoldOut && !m.Available:
+		observed = notify.SoldOut
+	default:
+		return
+	}
+	goal := goalStatus(target.goal())
+	if dispatcher == nil || observed == goal || state.Notified().Status != goal {
+		return
+	}
+
+	prev, ok := state.ClaimAlert(observed, notify.Warning, target.NotifyCooldown, time.Now())
+	if !ok {
+		return
+	}
+	slog.Info("Status changed", "target", target.Name, "from", goal, "to", observed)
+	dispatchTransition(target, state, redisClient, dispatcher, prev, notify.Alert{
+		Level:        notify.Warning,
+		Timestamp:    time.Now(),
+		Target:       target.Name,
+		Availability: observed,
+		Message:      fmt.Sprintf("%s is no longer %s (now %s)", target.Name, goal, observed),
+	})
+}
@@ -3,6 +3,7 @@ package config
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -13,9 +14,11 @@ import (
 // Manager handles dynamic configuration with hot-reload
 type Manager struct {
 	viper     *viper.Viper
+	overlays  []string // merged over the base file, in order
 	current   *Config
 	mu        sync.RWMutex
-	watchers  []func(*Config)
+	watchers  []*watcher     // asynchronous, each serialized
+	syncFns   []func(*Config) // run in order on the reloading goroutine
 }
 
 // Config represents the application configuration
@@ -36,8 +39,9 @@ type Target struct {
 	Timeout     time.Duration     `mapstructure:"timeout"`
 }
 
-// NewManager creates a new configuration manager
-func NewManager(configPath string) (*Manager, error) {
+// NewManager creates a new configuration manager. Overlay files are
+// merged over configPath in order, their targets added to its own.
+func NewManager(configPath string, overlays ...string) (*Manager, error) {
 	v := viper.New()
 	v.SetConfigFile(configPath)
 	v.SetConfigType("yaml")
@@ -52,7 +56,8 @@ func NewManager(configPath string) (*Manager, error) {
 	}
 
 	m := &Manager{
-		viper: v,
+		viper:    v,
+		overlays: overlays,
 	}
 
 	if err := m.load(); err != nil {
@@ -74,10 +79,22 @@ func NewManager(configPath string) (*Manager, error) {
 
 // load reads and validates configuration
 func (m *Manager) load() error {
+	// A reload re-reads only the base file
+	if err := m.mergeOverlays(); err != nil {
+		return err
+	}
+
 	var cfg Config
 	if err := m.viper.Unmarshal(&cfg); err != nil {
 		return fmt.Errorf("unmarshal: %w", err)
 	}
+	if len(m.overlays) > 0 {
+		targets, err := m.mergedTargets()
+		if err != nil {
+			return fmt.Errorf("merge: %w", err)
+		}
+		cfg.Targets = targets
+	}
 
 	if err := validate(&cfg); err != nil {
 		return fmt.Errorf("validation: %w", err)
@@ -92,23 +109,46 @@ func (m *Manager) load() error {
 	return nil
 }
 
-// Get returns the current configuration
+// Get returns the current configuration. It is shared: callers that
+// modify it should work on a Clone.
 func (m *Manager) Get() *Config {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	return m.current
 }
 
-// OnChange registers a callback for configuration changes
+// OnChange registers a callback for configuration changes. It runs on
+// its own goroutine, never concurrently with itself: reloads arriving
+// while it runs are coalesced and it is next called with the latest.
+// Each call gets its own copy of the config.
 func (m *Manager) OnChange(fn func(*Config)) {
-	m.watchers = append(m.watchers, fn)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.watchers = append(m.watchers, &watcher{fn: fn})
+}
+
+// OnChangeSync registers a callback run on the reloading goroutine, in
+// registration order, before the next reload is read. It sees every
+// reload but holds up later ones, so it should be quick.
+func (m *Manager) OnChangeSync(fn func(*Config)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.syncFns = append(m.syncFns, fn)
 }
 
-// notifyWatchers calls all registered callbacks
+// notifyWatchers hands each callback a snapshot of the current config
 func (m *Manager) notifyWatchers() {
-	cfg := m.Get()
-	for _, fn := range m.watchers {
-		go fn(cfg) // Async notification
+	m.mu.RLock()
+	cfg := m.current
+	syncFns := m.syncFns
+	watchers := m.watchers
+	m.mu.RUnlock()
+
+	for _, fn := range syncFns {
+		fn(cfg.Clone())
+	}
+	for _, w := range watchers {
+		w.deliver(cfg.Clone())
 	}
 }
 
@@ -143,6 +183,13 @@ func validate(cfg *Config) error {
 		if _, ok := t.Selectors["sold_out"]; !ok {
 			return fmt.Errorf("target %s: missing 'sold_out' selector", t.Name)
 		}
+		if err := ValidateSelectors(t.Selectors); err != nil {
+			return fmt.Errorf("target %s: %w", t.Name, err)
+		}
+
+		if err := ValidateURL(t.URL); err != nil {
+			return fmt.Errorf("target %s: %w", t.Name, err)
+		}
 	}
 
 	return nil
@@ -150,28 +197,27 @@ func validate(cfg *Config) error {
 
 // GetTarget returns a target by name
 func (c *Config) GetTarget(name string) (*Target, error) {
-	for _, t := range c.Targets {
-		if t.Name == name {
-			return &t, nil
+	for i := range c.Targets {
+		if c.Targets[i].Name == name {
+			return &c.Targets[i], nil
 		}
 	}
 	return nil, fmt.Errorf("target not found: %s", name)
 }
 
-// GetTargetsByPriority returns targets sorted by priority
+// GetTargetsByPriority returns targets sorted by priority, highest
+// first, then by name, so the order is the same across reloads
 func (c *Config) GetTargetsByPriority() []Target {
 	// Copy to avoid modifying original
 	result := make([]Target, len(c.Targets))
 	copy(result, c.Targets)
 
-	// Simple bubble sort by priority (descending)
-	for i := 0; i < len(result); i++ {
-		for j := i + 1; j < len(result); j++ {
-			if result[j].Priority > result[i].Priority {
-				result[i], result[j] = result[j], result[i]
-			}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Priority != result[j].Priority {
+			return result[i].Priority > result[j].Priority
 		}
-	}
+		return result[i].Name < result[j].Name
+	})
 
 	return result
 }
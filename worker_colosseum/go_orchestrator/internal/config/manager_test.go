@@ -0,0 +1,41 @@
+package config
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestGetTargetsByPriorityDeterministic(t *testing.T) {
+	targets := []Target{
+		{Name: "tickets-full", Priority: 1},
+		{Name: "arena", Priority: 5},
+		{Name: "underground", Priority: 5},
+		{Name: "forum", Priority: 1},
+		{Name: "colosseum", Priority: 5},
+		{Name: "night-tour", Priority: 0},
+	}
+	want := []string{"arena", "colosseum", "underground", "forum", "tickets-full", "night-tour"}
+
+	// The order is the same however the config happened to list them
+	for round := 0; round < 20; round++ {
+		shuffled := append([]Target(nil), targets...)
+		rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		cfg := &Config{Targets: shuffled}
+
+		got := cfg.GetTargetsByPriority()
+		for i := range want {
+			if got[i].Name != want[i] {
+				names := make([]string, len(got))
+				for j, target := range got {
+					names[j] = target.Name
+				}
+				t.Fatalf("order = %v, want %v", names, want)
+			}
+		}
+		for i := range shuffled {
+			if cfg.Targets[i].Name != shuffled[i].Name {
+				t.Fatal("GetTargetsByPriority reordered the config's own targets")
+			}
+		}
+	}
+}
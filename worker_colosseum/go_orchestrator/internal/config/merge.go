@@ -0,0 +1,66 @@
+// internal/config/merge.go - Multi-file configuration merging
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+// mergeOverlays merges the overlay files over the base config, in order.
+// Settings merge key by key with later files winning, except targets,
+// which mergedTargets concatenates.
+func (m *Manager) mergeOverlays() error {
+	for _, path := range m.overlays {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("overlay %s: %w", path, err)
+		}
+		err = m.viper.MergeConfig(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("overlay %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// mergedTargets collects the targets of every config file. A name
+// defined by two files is an error naming both, rather than the generic
+// duplicate-name failure viper's merge would otherwise surface.
+func (m *Manager) mergedTargets() ([]Target, error) {
+	var targets []Target
+	sources := make(map[string]string) // target name -> file defining it
+	for _, path := range append([]string{m.viper.ConfigFileUsed()}, m.overlays...) {
+		fileTargets, err := readTargets(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range fileTargets {
+			if prev, ok := sources[t.Name]; ok && prev != path && t.Name != "" {
+				return nil, fmt.Errorf("target %q defined in both %s and %s", t.Name, prev, path)
+			}
+			sources[t.Name] = path
+		}
+		targets = append(targets, fileTargets...)
+	}
+	return targets, nil
+}
+
+// readTargets reads the targets defined by a single config file
+func readTargets(path string) ([]Target, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	var file struct {
+		Targets []Target `mapstructure:"targets"`
+	}
+	if err := v.Unmarshal(&file); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", path, err)
+	}
+	return file.Targets, nil
+}
@@ -0,0 +1,56 @@
+// internal/config/target.go - Target URL and selector validation
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+)
+
+// AllowedDomains are the hosts collectors may visit; a target elsewhere
+// would be filtered out by colly and never polled
+var AllowedDomains = []string{"ticketing.colosseo.it", "www.colosseo.it"}
+
+// ValidateURL checks that raw is an absolute http(s) URL on one of the
+// AllowedDomains
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("URL %q: scheme must be http or https", raw)
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range AllowedDomains {
+		if host == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("URL %q: host %q is not one of the allowed domains (%s)", raw, host, strings.Join(AllowedDomains, ", "))
+}
+
+// ValidateSelectors compiles each non-empty selector with cascadia, the
+// CSS engine colly's goquery uses, which would otherwise silently match
+// nothing for a malformed selector
+func ValidateSelectors(selectors map[string]string) error {
+	keys := make([]string, 0, len(selectors))
+	for k := range selectors {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		sel := selectors[k]
+		if sel == "" {
+			continue
+		}
+		if _, err := cascadia.Compile(sel); err != nil {
+			return fmt.Errorf("selector %s %q: %w", k, sel, err)
+		}
+	}
+	return nil
+}
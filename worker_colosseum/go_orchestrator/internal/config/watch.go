@@ -0,0 +1,67 @@
+// internal/config/watch.go - Ordered delivery of reloaded configs to watchers
+package config
+
+import "sync"
+
+// watcher delivers configs to one callback, one at a time and in order.
+// A callback still running when more reloads land sees only the latest
+// of them next, never an older config after a newer one.
+type watcher struct {
+	fn func(*Config)
+
+	mu      sync.Mutex
+	pending *Config
+	running bool
+}
+
+// deliver queues cfg, starting the delivery goroutine if it is idle
+func (w *watcher) deliver(cfg *Config) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = cfg
+	if !w.running {
+		w.running = true
+		go w.drain()
+	}
+}
+
+// drain calls fn for each pending config until none is left
+func (w *watcher) drain() {
+	for {
+		w.mu.Lock()
+		cfg := w.pending
+		w.pending = nil
+		if cfg == nil {
+			w.running = false
+			w.mu.Unlock()
+			return
+		}
+		w.mu.Unlock()
+
+		w.fn(cfg)
+	}
+}
+
+// Clone returns a deep copy of c, so a holder can read or modify it
+// while later reloads proceed
+func (c *Config) Clone() *Config {
+	clone := *c
+	clone.Targets = make([]Target, len(c.Targets))
+	for i, t := range c.Targets {
+		t.Selectors = cloneMap(t.Selectors)
+		t.Headers = cloneMap(t.Headers)
+		clone.Targets[i] = t
+	}
+	return &clone
+}
+
+func cloneMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[string]string, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
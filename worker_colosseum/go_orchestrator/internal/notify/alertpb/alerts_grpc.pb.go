@@ -0,0 +1,138 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: alerts.proto
+
+package alertpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	AlertStream_SubscribeAlerts_FullMethodName = "/colosseo.alerts.AlertStream/SubscribeAlerts"
+)
+
+// AlertStreamClient is the client API for AlertStream service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AlertStreamClient interface {
+	// Stream matching alerts until the client disconnects
+	SubscribeAlerts(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (AlertStream_SubscribeAlertsClient, error)
+}
+
+type alertStreamClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAlertStreamClient(cc grpc.ClientConnInterface) AlertStreamClient {
+	return &alertStreamClient{cc}
+}
+
+func (c *alertStreamClient) SubscribeAlerts(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (AlertStream_SubscribeAlertsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &AlertStream_ServiceDesc.Streams[0], AlertStream_SubscribeAlerts_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &alertStreamSubscribeAlertsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AlertStream_SubscribeAlertsClient interface {
+	Recv() (*Alert, error)
+	grpc.ClientStream
+}
+
+type alertStreamSubscribeAlertsClient struct {
+	grpc.ClientStream
+}
+
+func (x *alertStreamSubscribeAlertsClient) Recv() (*Alert, error) {
+	m := new(Alert)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AlertStreamServer is the server API for AlertStream service.
+// All implementations must embed UnimplementedAlertStreamServer
+// for forward compatibility
+type AlertStreamServer interface {
+	// Stream matching alerts until the client disconnects
+	SubscribeAlerts(*SubscribeRequest, AlertStream_SubscribeAlertsServer) error
+	mustEmbedUnimplementedAlertStreamServer()
+}
+
+// UnimplementedAlertStreamServer must be embedded to have forward compatible implementations.
+type UnimplementedAlertStreamServer struct {
+}
+
+func (UnimplementedAlertStreamServer) SubscribeAlerts(*SubscribeRequest, AlertStream_SubscribeAlertsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeAlerts not implemented")
+}
+func (UnimplementedAlertStreamServer) mustEmbedUnimplementedAlertStreamServer() {}
+
+// UnsafeAlertStreamServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AlertStreamServer will
+// result in compilation errors.
+type UnsafeAlertStreamServer interface {
+	mustEmbedUnimplementedAlertStreamServer()
+}
+
+func RegisterAlertStreamServer(s grpc.ServiceRegistrar, srv AlertStreamServer) {
+	s.RegisterService(&AlertStream_ServiceDesc, srv)
+}
+
+func _AlertStream_SubscribeAlerts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AlertStreamServer).SubscribeAlerts(m, &alertStreamSubscribeAlertsServer{stream})
+}
+
+type AlertStream_SubscribeAlertsServer interface {
+	Send(*Alert) error
+	grpc.ServerStream
+}
+
+type alertStreamSubscribeAlertsServer struct {
+	grpc.ServerStream
+}
+
+func (x *alertStreamSubscribeAlertsServer) Send(m *Alert) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// AlertStream_ServiceDesc is the grpc.ServiceDesc for AlertStream service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AlertStream_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "colosseo.alerts.AlertStream",
+	HandlerType: (*AlertStreamServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeAlerts",
+			Handler:       _AlertStream_SubscribeAlerts_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "alerts.proto",
+}
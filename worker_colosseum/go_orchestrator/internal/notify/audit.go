@@ -0,0 +1,296 @@
+// internal/notify/audit.go - Durable per-alert audit log
+package notify
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// Audit outcomes
+const (
+	AuditDelivered     = "delivered"     // at least one route delivered
+	AuditFailed        = "failed"        // every route failed
+	AuditSuppressed    = "suppressed"    // muted after an acquisition
+	AuditRateLimited   = "rate_limited"  // over the target's alert cap
+	AuditQuietHours    = "quiet_hours"   // held or dropped outside active hours
+	AuditUndeliverable = "undeliverable" // no channel receives the level
+)
+
+// auditQueueSize bounds entries waiting to be written; beyond it entries
+// are dropped rather than delaying dispatch
+const auditQueueSize = 256
+
+// AuditDropped counts audit entries lost to a full queue. The caller
+// registers it alongside its own metrics.
+var AuditDropped = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "colosseo_alert_audit_dropped_total",
+	Help: "Alert audit entries dropped because the writer fell behind",
+})
+
+// AuditEntry is the forensic record of one alert passing through the
+// dispatcher, whether or not it reached anyone
+type AuditEntry struct {
+	ID           string             `json:"id"`
+	Timestamp    time.Time          `json:"timestamp"`
+	Target       string             `json:"target"`
+	Level        string             `json:"level"`
+	Availability AvailabilityStatus `json:"availability,omitempty"`
+	Confidence   float32            `json:"confidence"`
+	Headline     string             `json:"headline,omitempty"`
+	Message      string             `json:"message,omitempty"`
+	Outcome      string             `json:"outcome"`
+	Delivered    []string           `json:"delivered,omitempty"` // channels that accepted the alert
+	Failed       map[string]string  `json:"failed,omitempty"`    // channel -> error
+}
+
+// AuditQuery filters audit entries. Results are newest first.
+type AuditQuery struct {
+	Target string    // empty = every target
+	Since  time.Time // zero = no lower bound
+	Limit  int
+}
+
+// AuditStore persists audit entries and answers queries over them
+type AuditStore interface {
+	Append(ctx context.Context, entry AuditEntry) error
+	Query(ctx context.Context, q AuditQuery) ([]AuditEntry, error)
+}
+
+// auditor writes entries to its stores in the background so a slow or
+// failing store never blocks or fails a dispatch
+type auditor struct {
+	stores []AuditStore
+	queue  chan AuditEntry
+	done   chan struct{}
+}
+
+// SetAudit records every alert to the stores. The first store answers
+// QueryAudit. Call before dispatching.
+func (d *Dispatcher) SetAudit(stores ...AuditStore) {
+	if len(stores) == 0 {
+		return
+	}
+	a := &auditor{
+		stores: stores,
+		queue:  make(chan AuditEntry, auditQueueSize),
+		done:   make(chan struct{}),
+	}
+	go a.run()
+	d.mu.Lock()
+	d.audit = a
+	d.mu.Unlock()
+}
+
+// QueryAudit returns audit entries matching q
+func (d *Dispatcher) QueryAudit(ctx context.Context, q AuditQuery) ([]AuditEntry, error) {
+	d.mu.RLock()
+	a := d.audit
+	d.mu.RUnlock()
+	if a == nil {
+		return nil, fmt.Errorf("alert audit not configured")
+	}
+	return a.stores[0].Query(ctx, q)
+}
+
+// record queues the audit entry for alert without blocking
+func (d *Dispatcher) record(alert Alert, outcome string, delivered []string, failed map[string]string) {
+	d.mu.RLock()
+	a := d.audit
+	d.mu.RUnlock()
+	if a == nil {
+		return
+	}
+
+	entry := AuditEntry{
+		ID:           alert.ID,
+		Timestamp:    alert.Timestamp,
+		Target:       alert.Target,
+		Level:        alert.Level.String(),
+		Availability: alert.Availability,
+		Confidence:   alert.Confidence,
+		Headline:     alert.Headline,
+		Message:      alert.Message,
+		Outcome:      outcome,
+		Delivered:    delivered,
+		Failed:       failed,
+	}
+	select {
+	case a.queue <- entry:
+	default:
+		AuditDropped.Inc()
+	}
+}
+
+func (a *auditor) run() {
+	defer close(a.done)
+	for entry := range a.queue {
+		for _, s := range a.stores {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := s.Append(ctx, entry); err != nil {
+				slog.Warn("Alert audit write failed", "alert_id", entry.ID, "err", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// close writes the queued entries and stops the writer
+func (a *auditor) close() {
+	close(a.queue)
+	<-a.done
+}
+
+// identified gives the alert a correlation ID, unless it has one
+func identified(alert Alert) Alert {
+	if alert.ID == "" {
+		b := make([]byte, 8)
+		rand.Read(b)
+		alert.ID = hex.EncodeToString(b)
+	}
+	return alert
+}
+
+// RedisAudit keeps audit entries in a capped Redis stream
+type RedisAudit struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisAudit stores entries in stream, trimmed to about maxLen entries
+func NewRedisAudit(client *redis.Client, stream string, maxLen int64) *RedisAudit {
+	return &RedisAudit{client: client, stream: stream, maxLen: maxLen}
+}
+
+func (r *RedisAudit) Append(ctx context.Context, entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: r.stream,
+		MaxLen: r.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{"target": entry.Target, "entry": data},
+	}).Err()
+}
+
+// Query pages backwards through the stream from the newest entry,
+// stopping at q.Since, which bounds the scan by stream ID
+func (r *RedisAudit) Query(ctx context.Context, q AuditQuery) ([]AuditEntry, error) {
+	const page = 500
+	start := "-"
+	if !q.Since.IsZero() {
+		start = fmt.Sprintf("%d", q.Since.UnixMilli())
+	}
+
+	var entries []AuditEntry
+	end := "+"
+	for {
+		msgs, err := r.client.XRevRangeN(ctx, r.stream, end, start, page).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range msgs {
+			if q.Target != "" && msg.Values["target"] != q.Target {
+				continue
+			}
+			raw, _ := msg.Values["entry"].(string)
+			var entry AuditEntry
+			if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+				continue
+			}
+			entries = append(entries, entry)
+			if q.Limit > 0 && len(entries) >= q.Limit {
+				return entries, nil
+			}
+		}
+		if len(msgs) < page {
+			return entries, nil
+		}
+		end = "(" + msgs[len(msgs)-1].ID
+	}
+}
+
+// FileAudit appends audit entries to a local file as JSON lines
+type FileAudit struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+}
+
+// OpenFileAudit opens path for appending, creating it if needed
+func OpenFileAudit(path string) (*FileAudit, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open alert audit file: %w", err)
+	}
+	return &FileAudit{path: path, f: f}, nil
+}
+
+func (a *FileAudit) Append(ctx context.Context, entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, err = a.f.Write(append(data, '\n'))
+	return err
+}
+
+// Query scans the whole file; it suits post-mortems, not hot paths
+func (a *FileAudit) Query(ctx context.Context, q AuditQuery) ([]AuditEntry, error) {
+	f, err := os.Open(a.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if q.Target != "" && entry.Target != q.Target {
+			continue
+		}
+		if !q.Since.IsZero() && entry.Timestamp.Before(q.Since) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Newest first, as from the stream
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if q.Limit > 0 && len(entries) > q.Limit {
+		entries = entries[:q.Limit]
+	}
+	return entries, nil
+}
+
+// Close closes the file
+func (a *FileAudit) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.f.Close()
+}
@@ -0,0 +1,114 @@
+// internal/notify/channel.go - Pluggable notification channels and routing
+package notify
+
+import (
+	"context"
+	"fmt"
+)
+
+// Channel delivers alerts to one destination. New destinations implement
+// it and are added with Register, without touching Dispatch.
+type Channel interface {
+	// Name identifies the channel in routes and error messages
+	Name() string
+	Send(ctx context.Context, alert Alert) error
+	// Accepts is the channel's default level filter, used for levels
+	// with no explicit route
+	Accepts(level AlertLevel) bool
+}
+
+// Register adds a channel, replacing any channel with the same name
+func (d *Dispatcher) Register(ch Channel) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, existing := range d.channels {
+		if existing.Name() == ch.Name() {
+			d.channels[i] = ch
+			return
+		}
+	}
+	d.channels = append(d.channels, ch)
+}
+
+// Channel returns the registered channel with the given name
+func (d *Dispatcher) Channel(name string) (Channel, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, ch := range d.channels {
+		if ch.Name() == name {
+			return ch, true
+		}
+	}
+	return nil, false
+}
+
+// Route is a failover group: channel names tried in order until one
+// succeeds. A single-name route is an independent channel.
+type Route []string
+
+// SendTo delivers alert to the named channel only, bypassing routes,
+// suppression and the alert cap
+func (d *Dispatcher) SendTo(ctx context.Context, name string, alert Alert) error {
+	ch, ok := d.Channel(name)
+	if !ok {
+		return fmt.Errorf("unknown notification channel %q", name)
+	}
+	alert = identified(d.styled(alert))
+	if err := ch.Send(ctx, alert); err != nil {
+		d.record(alert, AuditFailed, nil, map[string]string{name: err.Error()})
+		return err
+	}
+	d.record(alert, AuditDelivered, []string{name}, nil)
+	return nil
+}
+
+// SetRoutes maps alert levels to the routes that receive them. Every
+// route of a level is delivered to, each stopping at its first
+// successful channel. A level with routes goes only to the named
+// channels; levels without one fall back to each channel's Accepts.
+func (d *Dispatcher) SetRoutes(routes map[AlertLevel][]Route) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.routes = routes
+}
+
+// routedGroups returns the failover groups that should receive level.
+// Unknown channel names are skipped; a group left empty is dropped.
+func (d *Dispatcher) routedGroups(level AlertLevel) [][]Channel {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	routes, routed := d.routes[level]
+	var result [][]Channel
+	if !routed {
+		for _, ch := range d.channels {
+			if ch.Accepts(level) {
+				result = append(result, []Channel{ch})
+			}
+		}
+		return result
+	}
+
+	for _, route := range routes {
+		var group []Channel
+		for _, name := range route {
+			for _, ch := range d.channels {
+				if ch.Name() == name {
+					group = append(group, ch)
+					break
+				}
+			}
+		}
+		if len(group) > 0 {
+			result = append(result, group)
+		}
+	}
+	return result
+}
+
+// snapshot returns the registered channels
+func (d *Dispatcher) snapshot() []Channel {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return append([]Channel(nil), d.channels...)
+}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func failoverDispatcher(telegramErr, emailErr, webhookErr error) (*Dispatcher, *fakeChannel, *fakeChannel, *fakeChannel) {
+	d := NewDispatcher(nil, 0, 0, "")
+	telegram := &fakeChannel{name: "telegram", err: telegramErr}
+	email := &fakeChannel{name: "email", err: emailErr}
+	webhook := &fakeChannel{name: "webhook", err: webhookErr}
+	d.Register(telegram)
+	d.Register(email)
+	d.Register(webhook)
+	// Email only as Telegram's failover; the webhook fans out on its own
+	d.SetRoutes(map[AlertLevel][]Route{
+		Warning: {{"telegram", "email"}, {"webhook"}},
+	})
+	return d, telegram, email, webhook
+}
+
+func TestFailoverFirstSuccess(t *testing.T) {
+	d, telegram, email, webhook := failoverDispatcher(nil, nil, nil)
+
+	if err := d.Dispatch(context.Background(), testAlert()); err != nil {
+		t.Fatalf("Dispatch() = %v, want nil", err)
+	}
+	if len(telegram.sent) != 1 || len(email.sent) != 0 {
+		t.Errorf("sends = telegram %d, email %d; want the failover untouched", len(telegram.sent), len(email.sent))
+	}
+	if len(webhook.sent) != 1 {
+		t.Errorf("webhook got %d sends, want 1", len(webhook.sent))
+	}
+}
+
+func TestFailoverAfterPrimaryFails(t *testing.T) {
+	d, telegram, email, webhook := failoverDispatcher(errors.New("bad gateway"), nil, nil)
+
+	if err := d.Dispatch(context.Background(), testAlert()); err != nil {
+		t.Fatalf("Dispatch() = %v, want nil", err)
+	}
+	if len(telegram.sent) != 1 || len(email.sent) != 1 || len(webhook.sent) != 1 {
+		t.Errorf("sends = telegram %d, email %d, webhook %d; want 1 each",
+			len(telegram.sent), len(email.sent), len(webhook.sent))
+	}
+}
+
+func TestFailoverAllFail(t *testing.T) {
+	d, telegram, email, webhook := failoverDispatcher(
+		errors.New("bad gateway"), errors.New("smtp: 554"), errors.New("timeout"))
+
+	err := d.Dispatch(context.Background(), testAlert())
+	if !errors.Is(err, errAllChannelsFailed) {
+		t.Fatalf("Dispatch() = %v, want %v", err, errAllChannelsFailed)
+	}
+	if len(telegram.sent) != 1 || len(email.sent) != 1 || len(webhook.sent) != 1 {
+		t.Errorf("sends = telegram %d, email %d, webhook %d; want every channel tried once",
+			len(telegram.sent), len(email.sent), len(webhook.sent))
+	}
+}
@@ -0,0 +1,182 @@
+// internal/notify/discord.go - Discord webhook channel with embeds
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"time"
+)
+
+// discordColors key an embed's side bar to the alert level
+var discordColors = map[AlertLevel]int{
+	Critical: 0xE74C3C, // red
+	Warning:  0xF39C12, // orange
+	Info:     0x3498DB, // blue
+}
+
+// discordChannel posts warning and critical alerts to a Discord webhook
+// as embeds, retried like the webhook channel
+type discordChannel struct {
+	url    string
+	client *http.Client
+}
+
+// SetDiscord enables the Discord channel, posting to the webhook URL of
+// a Discord channel (Server Settings > Integrations > Webhooks)
+func (d *Dispatcher) SetDiscord(webhookURL string) {
+	d.Register(&discordChannel{url: webhookURL, client: newHTTPClient(d.tlsConfig())})
+}
+
+func (c *discordChannel) Name() string { return "discord" }
+
+func (c *discordChannel) Accepts(level AlertLevel) bool { return level >= Warning }
+
+// discordMessage is the body of a Discord webhook execution
+type discordMessage struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	URL         string              `json:"url,omitempty"`
+	Color       int                 `json:"color"`
+	Timestamp   string              `json:"timestamp,omitempty"`
+	Fields      []discordEmbedField `json:"fields,omitempty"`
+	Image       *discordEmbedImage  `json:"image,omitempty"`
+}
+
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordEmbedImage struct {
+	URL string `json:"url"`
+}
+
+// Send posts alert as an embed, with the screenshot attached when the
+// alert is critical and the field is selected
+func (c *discordChannel) Send(ctx context.Context, alert Alert) error {
+	if c.url == "" {
+		return fmt.Errorf("discord webhook URL not configured")
+	}
+
+	screenshot := alert.Level == Critical && len(alert.Screenshot) > 0 && alertFields(alert)["screenshot"]
+	embed := discordEmbedFor(alert, screenshot)
+	payload, err := json.Marshal(discordMessage{Embeds: []discordEmbed{embed}})
+	if err != nil {
+		return err
+	}
+
+	body, contentType := payload, "application/json"
+	if screenshot {
+		if body, contentType, err = discordMultipart(payload, alert.Screenshot); err != nil {
+			return err
+		}
+	}
+
+	return DefaultWebhookRetry.retry(ctx, func(ctx context.Context) error {
+		return c.post(ctx, body, contentType)
+	})
+}
+
+// discordEmbedFor renders alert as an embed colored by its level
+func discordEmbedFor(alert Alert, screenshot bool) discordEmbed {
+	style := alertStyle(alert)
+	def := "Tickets Available"
+	if alert.Level < Critical {
+		def = "Possible Availability"
+	}
+
+	embed := discordEmbed{
+		Title: style.Prefix() + ": " + headline(alert, def),
+		Color: discordColors[alert.Level],
+	}
+	if alert.Message != "" {
+		embed.Title = style.Prefix()
+		embed.Description = alert.Message
+	}
+	if !alert.Timestamp.IsZero() {
+		embed.Timestamp = alert.Timestamp.UTC().Format(time.RFC3339)
+	}
+	if alert.Link != "" && alertFields(alert)["link"] {
+		embed.URL = alert.Link
+	}
+
+	if alert.Target != "" {
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: "Target", Value: alert.Target, Inline: true})
+	}
+	if alert.Message == "" {
+		embed.Fields = append(embed.Fields,
+			discordEmbedField{Name: "Confidence", Value: fmt.Sprintf("%.0f%%", alert.Confidence*100), Inline: true},
+			discordEmbedField{Name: "Status", Value: string(alert.Availability), Inline: true},
+		)
+	}
+	if alert.Price != nil {
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: "Price", Value: alert.Price.String(), Inline: true})
+	}
+	if screenshot {
+		embed.Image = &discordEmbedImage{URL: "attachment://" + screenshotFilename}
+	}
+	return embed
+}
+
+// discordMultipart packs the JSON payload and the screenshot into the
+// multipart form Discord expects for attachments
+func discordMultipart(payload, png []byte) ([]byte, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="payload_json"`)
+	header.Set("Content-Type", "application/json")
+	part, err := w.CreatePart(header)
+	if err != nil {
+		return nil, "", err
+	}
+	part.Write(payload)
+
+	header = make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="files[0]"; filename="%s"`, screenshotFilename))
+	header.Set("Content-Type", "image/png")
+	if part, err = w.CreatePart(header); err != nil {
+		return nil, "", err
+	}
+	part.Write(png)
+
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
+
+// post makes one delivery attempt. Rate limiting (429) and 5xx are
+// retried; other 4xx responses are permanent.
+func (c *discordChannel) post(ctx context.Context, body []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewReader(body))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("discord returned %d", resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return permanentError{fmt.Errorf("discord returned %d", resp.StatusCode)}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// discordRequest is what the test server received
+type discordRequest struct {
+	contentType string
+	body        []byte
+}
+
+func discordServer(t *testing.T, status int) (*discordChannel, <-chan discordRequest, *atomic.Int32) {
+	t.Helper()
+	requests := make(chan discordRequest, 4)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		requests <- discordRequest{contentType: r.Header.Get("Content-Type"), body: body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return &discordChannel{url: srv.URL, client: srv.Client()}, requests, &calls
+}
+
+func TestDiscordEmbed(t *testing.T) {
+	ch, requests, _ := discordServer(t, http.StatusNoContent)
+	alert := Alert{
+		Level:        Warning,
+		Timestamp:    time.Date(2026, 4, 1, 8, 30, 0, 0, time.UTC),
+		Target:       "colosseum-full",
+		Availability: Available,
+		Confidence:   0.75,
+		Link:         "https://ticketing.colosseo.it/en/eventi/24h",
+		Screenshot:   []byte("not attached below critical"),
+	}
+	if err := ch.Send(context.Background(), alert); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	req := <-requests
+	if req.contentType != "application/json" {
+		t.Fatalf("Content-Type = %q, want application/json", req.contentType)
+	}
+	var msg discordMessage
+	if err := json.Unmarshal(req.body, &msg); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	if len(msg.Embeds) != 1 {
+		t.Fatalf("got %d embeds, want 1", len(msg.Embeds))
+	}
+	embed := msg.Embeds[0]
+	if embed.Color != discordColors[Warning] {
+		t.Errorf("Color = %#x, want %#x", embed.Color, discordColors[Warning])
+	}
+	if embed.URL != alert.Link {
+		t.Errorf("URL = %q, want %q", embed.URL, alert.Link)
+	}
+	if embed.Timestamp != "2026-04-01T08:30:00Z" {
+		t.Errorf("Timestamp = %q, want 2026-04-01T08:30:00Z", embed.Timestamp)
+	}
+	if embed.Image != nil {
+		t.Errorf("Image = %+v, want no screenshot on a warning", embed.Image)
+	}
+	fields := make(map[string]string)
+	for _, f := range embed.Fields {
+		fields[f.Name] = f.Value
+	}
+	if fields["Target"] != "colosseum-full" || fields["Confidence"] != "75%" || fields["Status"] != "available" {
+		t.Errorf("Fields = %v, want target, confidence and status", fields)
+	}
+}
+
+func TestDiscordScreenshotAttachment(t *testing.T) {
+	ch, requests, _ := discordServer(t, http.StatusOK)
+	png := []byte("\x89PNG\r\n\x1a\nfake")
+	alert := Alert{Level: Critical, Timestamp: time.Now(), Target: "colosseum-full", Availability: Available, Confidence: 1, Screenshot: png}
+	if err := ch.Send(context.Background(), alert); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	req := <-requests
+	mediaType, params, err := mime.ParseMediaType(req.contentType)
+	if err != nil || mediaType != "multipart/form-data" {
+		t.Fatalf("Content-Type = %q, want multipart/form-data", req.contentType)
+	}
+	parts := make(map[string][]byte)
+	filenames := make(map[string]string)
+	r := multipart.NewReader(bytes.NewReader(req.body), params["boundary"])
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[part.FormName()], _ = io.ReadAll(part)
+		filenames[part.FormName()] = part.FileName()
+	}
+
+	var msg discordMessage
+	if err := json.Unmarshal(parts["payload_json"], &msg); err != nil {
+		t.Fatalf("payload_json: %v", err)
+	}
+	if len(msg.Embeds) != 1 || msg.Embeds[0].Image == nil || msg.Embeds[0].Image.URL != "attachment://"+screenshotFilename {
+		t.Errorf("embeds = %+v, want the image pointing at the attachment", msg.Embeds)
+	}
+	if msg.Embeds[0].Color != discordColors[Critical] {
+		t.Errorf("Color = %#x, want %#x", msg.Embeds[0].Color, discordColors[Critical])
+	}
+	if !bytes.Equal(parts["files[0]"], png) || filenames["files[0]"] != screenshotFilename {
+		t.Errorf("files[0] = %q named %q, want the screenshot as %s", parts["files[0]"], filenames["files[0]"], screenshotFilename)
+	}
+}
+
+func TestDiscordClientErrorIsNotRetried(t *testing.T) {
+	ch, _, calls := discordServer(t, http.StatusBadRequest)
+	if err := ch.Send(context.Background(), Alert{Level: Warning, Target: "colosseum-full"}); err == nil {
+		t.Fatal("Send() = nil, want an error for a 400")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server saw %d attempts, want 1", got)
+	}
+}
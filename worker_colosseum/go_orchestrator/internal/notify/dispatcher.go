@@ -4,26 +4,51 @@ package notify
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	"github.com/gorilla/websocket"
 )
 
 // Dispatcher handles multi-channel notifications
 type Dispatcher struct {
-	telegram   *tgbotapi.BotAPI
-	chatID     int64
-	webSocket  *websocket.Conn
-	webhookURL string
+	mu         sync.RWMutex
+	channels   []Channel
+	routes     map[AlertLevel][]Route // level -> failover groups; unset levels use Accepts
+	suppressed map[string]time.Time   // target -> end of availability suppression
+	limiter    *rateLimiter
+	quiet      *quietHours // nil unless SetQuietHours enabled it
+	redelivery *redeliverer // nil unless SetRedelivery enabled it
+	styles     map[AlertLevel]LevelStyle // overrides of defaultLevelStyles
+	audit      *auditor                  // nil unless SetAudit enabled it
+	tls        *tls.Config               // outbound sender TLS; nil = secure default
+	recipients map[string]Recipients     // target -> its own chats and webhooks
 	fallbackCh chan<- Alert
 }
 
+// WebhookOptions controls what goes into webhook payloads
+type WebhookOptions struct {
+	// ExcludeScreenshot drops the screenshot from every webhook payload
+	ExcludeScreenshot bool
+	// MaxPayloadBytes strips large fields when the JSON would exceed it (0 = unlimited)
+	MaxPayloadBytes int
+	// Retry re-sends after network errors and 5xx responses
+	Retry RetryPolicy
+	// Secret signs each delivery with X-Timestamp and X-Signature
+	// headers (see SignWebhook); empty sends unsigned
+	Secret string
+}
+
 // Alert represents a notification alert
 type Alert struct {
+	ID           string                 `json:"id,omitempty"` // correlation ID, assigned on dispatch
 	Level        AlertLevel             `json:"level"`
 	Timestamp    time.Time              `json:"timestamp"`
 	Target       string                 `json:"target"`
@@ -31,6 +56,39 @@ type Alert struct {
 	Confidence   float32                `json:"confidence"`
 	Screenshot   []byte                 `json:"screenshot,omitempty"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	Message      string                 `json:"message,omitempty"`   // free-form text for non-availability alerts
+	Headline     string                 `json:"headline,omitempty"`  // overrides the default availability wording
+	Truncated    bool                   `json:"truncated,omitempty"` // large fields were stripped to fit the payload limit
+	Price        *Price                 `json:"price,omitempty"`
+	Link         string                 `json:"link,omitempty"` // booking deep link, rendered as a button
+	Style        *LevelStyle            `json:"style,omitempty"` // severity decoration, stamped on delivery
+	Fields       AlertFields            `json:"-"`               // details the message includes; nil = level default
+	Recipients   *Recipients            `json:"-"`               // target's own chats and webhooks; nil = global
+	// Channels limits a persisted alert's redelivery to the channels
+	// that failed it; empty retries every routed channel. Like Fields it
+	// is kept in the redelivery record, not the alert's JSON.
+	Channels []string `json:"-"`
+	// RetryChats limits a persisted alert's Telegram redelivery to the
+	// chats that missed it; empty sends to every chat
+	RetryChats []int64 `json:"-"`
+}
+
+// screenshotFilename names an alert's screenshot wherever it is attached
+const screenshotFilename = "confirmation.png"
+
+// Price is a ticket price in minor units of an ISO currency
+type Price struct {
+	Cents    int64  `json:"cents"`
+	Currency string `json:"currency"`
+}
+
+// Amount returns the price in major units
+func (p Price) Amount() float64 {
+	return float64(p.Cents) / 100
+}
+
+func (p Price) String() string {
+	return fmt.Sprintf("%s %d.%02d", p.Currency, p.Cents/100, p.Cents%100)
 }
 
 // AlertLevel represents severity level
@@ -52,22 +110,37 @@ const (
 	Uncertain       AvailabilityStatus = "uncertain"
 )
 
-// NewDispatcher creates a new notification dispatcher
+// NewDispatcher creates a new notification dispatcher. Telegram sends
+// are paced to telegramRate messages per second (<= 0 uses
+// DefaultTelegramRate).
 func NewDispatcher(
 	telegramBot *tgbotapi.BotAPI,
 	chatID int64,
+	telegramRate float64,
 	webhookURL string,
 ) *Dispatcher {
-	return &Dispatcher{
-		telegram:   telegramBot,
-		chatID:     chatID,
-		webhookURL: webhookURL,
+	d := &Dispatcher{}
+	if telegramBot != nil {
+		if telegramRate <= 0 {
+			telegramRate = DefaultTelegramRate
+		}
+		d.Register(&telegramChannel{
+			bot:    telegramBot,
+			chatID: chatID,
+			pace:   newTokenBucket(telegramRate, telegramBurst),
+		})
+	}
+	if webhookURL != "" {
+		d.Register(&webhookChannel{url: webhookURL})
 	}
+	return d
 }
 
-// SetWebSocket sets the WebSocket connection for real-time updates
-func (d *Dispatcher) SetWebSocket(ws *websocket.Conn) {
-	d.webSocket = ws
+// SetWebhookOptions sets payload options for the webhook channel
+func (d *Dispatcher) SetWebhookOptions(opts WebhookOptions) {
+	if ch, ok := d.Channel("webhook"); ok {
+		ch.(*webhookChannel).opts = opts
+	}
 }
 
 // SetFallbackChannel sets the fallback channel for failed notifications
@@ -75,166 +148,404 @@ func (d *Dispatcher) SetFallbackChannel(ch chan<- Alert) {
 	d.fallbackCh = ch
 }
 
-// Dispatch sends alert through all configured channels
+// Dispatch sends alert through all configured channels, unless the
+// target is suppressed or over its alert cap
 func (d *Dispatcher) Dispatch(ctx context.Context, alert Alert) error {
-	var errs []error
+	alert = identified(alert)
+	if d.suppress(alert) {
+		d.record(alert, AuditSuppressed, nil, nil)
+		return nil
+	}
+	if d.quiet != nil && d.quiet.mute(alert) {
+		d.record(alert, AuditQuietHours, nil, nil)
+		return nil
+	}
+	if d.limiter != nil && !d.limiter.allow(alert, time.Now()) {
+		d.record(alert, AuditRateLimited, nil, nil)
+		return nil
+	}
+	return d.deliver(ctx, alert)
+}
 
-	// Primary: Telegram for critical and warning alerts
-	if alert.Level >= Warning {
-		if err := d.sendTelegram(alert); err != nil {
-			errs = append(errs, fmt.Errorf("telegram: %w", err))
+// deliver sends alert, persisting it for redelivery if every channel
+// failed and redelivery is enabled
+func (d *Dispatcher) deliver(ctx context.Context, alert Alert) error {
+	alert = identified(alert)
+	err := d.send(ctx, alert)
+	if errors.Is(err, errAllChannelsFailed) {
+		d.mu.RLock()
+		r := d.redelivery
+		d.mu.RUnlock()
+		if r != nil {
+			alert.RetryChats = missedChats(err)
+			r.persist(alert)
 		}
 	}
+	return err
+}
+
+// send sends alert through every failover group routed for its level.
+// Groups fan out; within a group channels are tried in order until one
+// succeeds.
+func (d *Dispatcher) send(ctx context.Context, alert Alert) error {
+	alert = identified(d.addressed(d.styled(alert)))
+	var errs []error
+	attempted, failed := 0, 0
+	var delivered, retry []string
+	channelErrs := make(map[string]string)
+
+	groups := d.routedGroups(alert.Level)
+	if len(groups) == 0 && d.nowhere(alert) {
+		return d.undeliverable(alert)
+	}
 
-	// Secondary: WebSocket for real-time dashboard
-	if d.webSocket != nil {
-		if err := d.sendWebSocket(alert); err != nil {
-			errs = append(errs, fmt.Errorf("websocket: %w", err))
+	for _, group := range groups {
+		var groupErrs []error
+		tried, sent := 0, false
+		primary := ""
+		for _, ch := range group {
+			err := ch.Send(ctx, alert)
+			if errors.Is(err, errNoRecipient) {
+				continue
+			}
+			if tried == 0 {
+				primary = ch.Name()
+			}
+			tried++
+			if err == nil {
+				delivered = append(delivered, ch.Name())
+				sent = true
+				break
+			}
+			channelErrs[ch.Name()] = err.Error()
+			groupErrs = append(groupErrs, fmt.Errorf("%s: %w", ch.Name(), err))
 		}
+		if tried == 0 {
+			continue // no channel of the group had a recipient
+		}
+		attempted++
+		if !sent {
+			failed++
+			errs = append(errs, groupErrs...)
+			retry = append(retry, primary)
+		}
+	}
+	if attempted == 0 && len(groups) > 0 && d.nowhere(alert) {
+		return d.undeliverable(alert)
 	}
 
-	// Tertiary: Webhook for external integration
-	if d.webhookURL != "" {
-		if err := d.sendWebhook(alert); err != nil {
-			errs = append(errs, fmt.Errorf("webhook: %w", err))
+	// Groups that failed, e.g. Telegram after its retries, while others
+	// delivered are redelivered on their own; deliver persists alerts
+	// every group failed
+	if failed > 0 && failed < attempted {
+		d.mu.RLock()
+		r := d.redelivery
+		d.mu.RUnlock()
+		if r != nil {
+			partial := alert
+			partial.Channels = retry
+			partial.RetryChats = missedChats(errors.Join(errs...))
+			r.persist(partial)
 		}
 	}
 
 	// Fallback: channel-based for internal handling
-	if len(errs) > 0 && d.fallbackCh != nil {
+	if failed > 0 && d.fallbackCh != nil {
 		select {
 		case d.fallbackCh <- alert:
 		default: // Non-blocking
 		}
 	}
 
-	if len(errs) == 3 { // All channels failed
-		return fmt.Errorf("all notification channels failed: %v", errs)
+	if attempted > 0 && failed == attempted { // All groups failed
+		d.record(alert, AuditFailed, delivered, channelErrs)
+		return fmt.Errorf("%w: %w", errAllChannelsFailed, errors.Join(errs...))
 	}
 
+	d.record(alert, AuditDelivered, delivered, channelErrs)
+	return nil
+}
+
+// Close flushes channels with pending asynchronous deliveries and the
+// alert audit. Alerts held over quiet hours are dropped.
+func (d *Dispatcher) Close() error {
+	var errs []error
+	if d.quiet != nil {
+		d.quiet.stop()
+	}
+	d.mu.Lock()
+	r := d.redelivery
+	d.redelivery = nil
+	d.mu.Unlock()
+	if r != nil {
+		r.stop()
+	}
+	d.mu.Lock()
+	a := d.audit
+	d.audit = nil
+	d.mu.Unlock()
+	if a != nil {
+		a.close()
+	}
+	for _, ch := range d.snapshot() {
+		if c, ok := ch.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", ch.Name(), err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("close channels: %v", errs)
+	}
 	return nil
 }
 
-// sendTelegram sends alert via Telegram Bot API
-func (d *Dispatcher) sendTelegram(alert Alert) error {
-	if d.telegram == nil {
+// telegramChannel sends warning and critical alerts via the Telegram Bot API
+type telegramChannel struct {
+	bot    *tgbotapi.BotAPI
+	chatID int64
+	pace   *tokenBucket
+}
+
+func (t *telegramChannel) Name() string { return "telegram" }
+
+func (t *telegramChannel) Accepts(level AlertLevel) bool { return level >= Warning }
+
+// Send sends alert via Telegram Bot API
+func (t *telegramChannel) Send(ctx context.Context, alert Alert) error {
+	if t.bot == nil {
 		return fmt.Errorf("telegram bot not configured")
 	}
 
+	style := alertStyle(alert)
 	var msg string
-	switch alert.Level {
-	case Critical:
-		msg = fmt.Sprintf(
-			"🚨 *CRITICAL: Tickets Available*\n\n"+
-				"📍 Target: %s\n"+
-				"⏰ Time: %s\n"+
-				"🎯 Confidence: %.0f%%\n"+
-				"📊 Status: %s",
-			escapeMarkdown(alert.Target),
-			alert.Timestamp.Format("15:04:05.000"),
-			alert.Confidence*100,
-			alert.Availability,
-		)
+	switch {
+	case alert.Message != "":
+		msg = fmt.Sprintf("%s %s", levelPrefix(alert.Level, style), escapeMarkdown(alert.Message))
+
+	case alert.Level == Critical:
+		msg = availabilityMessage(alert, style, "Tickets Available")
 
-	case Warning:
-		msg = fmt.Sprintf(
-			"⚠️ *WARNING: Possible Availability*\n\n"+
-				"📍 Target: %s\n"+
-				"🎯 Confidence: %.0f%%",
-			escapeMarkdown(alert.Target),
-			alert.Confidence*100,
-		)
+	case alert.Level == Warning:
+		msg = availabilityMessage(alert, style, "Possible Availability")
 
 	default:
-		msg = fmt.Sprintf(
-			"ℹ️ Info: %s - %s",
+		msg = escapeMarkdown(fmt.Sprintf(
+			"%s: %s - %s",
+			style.Prefix(),
 			alert.Target,
 			alert.Availability,
-		)
+		))
 	}
 
-	// Include screenshot if available and critical
-	if alert.Level == Critical && len(alert.Screenshot) > 0 {
-		photo := tgbotapi.NewPhoto(d.chatID, tgbotapi.FileBytes{
-			Name: "confirmation.png",
+	chats := alert.chatIDs(t.chatID)
+	if len(chats) == 0 {
+		return errNoRecipient
+	}
+	// Every chat is sent to; the alert fails if any chat missed it
+	var errs []error
+	var missed []int64
+	for _, chatID := range chats {
+		if err := t.sendPaced(ctx, telegramMessage(alert, chatID, msg)); err != nil {
+			errs = append(errs, fmt.Errorf("chat %d: %w", chatID, err))
+			missed = append(missed, chatID)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &chatsError{chats: missed, err: errors.Join(errs...)}
+}
+
+// telegramMessage addresses the rendered msg to chatID, as a photo
+// caption when the screenshot is included
+func telegramMessage(alert Alert, chatID int64, msg string) tgbotapi.Chattable {
+	// Include screenshot if available, critical and selected
+	if alert.Level == Critical && len(alert.Screenshot) > 0 && alertFields(alert)["screenshot"] {
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{
+			Name: screenshotFilename,
 			Bytes: alert.Screenshot,
 		})
 		photo.Caption = msg
-		photo.ParseMode = "Markdown"
-		_, err := d.telegram.Send(photo)
-		return err
+		photo.ParseMode = tgbotapi.ModeMarkdownV2
+		if markup, ok := bookingButton(alert); ok {
+			photo.ReplyMarkup = markup
+		}
+		return photo
 	}
 
-	tgMsg := tgbotapi.NewMessage(d.chatID, msg)
-	tgMsg.ParseMode = "Markdown"
+	tgMsg := tgbotapi.NewMessage(chatID, msg)
+	tgMsg.ParseMode = tgbotapi.ModeMarkdownV2
 	tgMsg.DisableWebPagePreview = true
+	if markup, ok := bookingButton(alert); ok {
+		tgMsg.ReplyMarkup = markup
+	}
+	return tgMsg
+}
 
-	_, err := d.telegram.Send(tgMsg)
-	return err
+// availabilityMessage renders a bold headline followed by the alert's
+// selected fields, one per line
+func availabilityMessage(alert Alert, style LevelStyle, def string) string {
+	msg := fmt.Sprintf("%s*%s: %s*",
+		emojiPrefix(style),
+		escapeMarkdown(style.Label),
+		escapeMarkdown(headline(alert, def)),
+	)
+	if lines := formatFields(alert, alertFields(alert)); len(lines) > 0 {
+		msg += "\n\n" + strings.Join(lines, "\n")
+	}
+	return msg
 }
 
-// sendWebSocket sends alert via WebSocket
-func (d *Dispatcher) sendWebSocket(alert Alert) error {
-	if d.webSocket == nil {
-		return fmt.Errorf("websocket not connected")
+// bookingButton renders the alert's deep link as a one-tap inline button
+// when the link field is selected
+func bookingButton(alert Alert) (tgbotapi.InlineKeyboardMarkup, bool) {
+	if alert.Link == "" || alert.Level < Warning || !alertFields(alert)["link"] {
+		return tgbotapi.InlineKeyboardMarkup{}, false
 	}
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonURL("🎟️ Book now", alert.Link)),
+	), true
+}
 
-	data, err := json.Marshal(alert)
-	if err != nil {
-		return err
+// headline returns the alert's headline, or def when none is set
+func headline(alert Alert, def string) string {
+	if alert.Headline != "" {
+		return alert.Headline
 	}
+	return def
+}
 
-	return d.webSocket.WriteMessage(websocket.TextMessage, data)
+// levelPrefix returns the emoji and label heading a free-form message,
+// bold for Warning and above
+func levelPrefix(level AlertLevel, style LevelStyle) string {
+	if level >= Warning {
+		return fmt.Sprintf("%s*%s:*", emojiPrefix(style), escapeMarkdown(style.Label))
+	}
+	return fmt.Sprintf("%s%s:", emojiPrefix(style), escapeMarkdown(style.Label))
 }
 
-// sendWebhook sends alert via HTTP webhook
-func (d *Dispatcher) sendWebhook(alert Alert) error {
-	if d.webhookURL == "" {
-		return fmt.Errorf("webhook URL not configured")
+// emojiPrefix returns the style's emoji, escaped since it is
+// configurable, followed by a space, if it has one
+func emojiPrefix(style LevelStyle) string {
+	if style.Emoji == "" {
+		return ""
 	}
+	return escapeMarkdown(style.Emoji) + " "
+}
 
-	data, err := json.Marshal(alert)
+// webhookChannel posts every alert to an external integration
+type webhookChannel struct {
+	url    string // global URL; empty = only targets' own webhooks
+	opts   WebhookOptions
+	client *http.Client // nil = http.DefaultClient
+}
+
+func (w *webhookChannel) Name() string { return "webhook" }
+
+func (w *webhookChannel) Accepts(AlertLevel) bool { return true }
+
+// Send sends alert via HTTP webhook
+func (w *webhookChannel) Send(ctx context.Context, alert Alert) error {
+	urls := alert.webhookURLs(w.url)
+	if len(urls) == 0 {
+		return errNoRecipient
+	}
+
+	data, err := webhookPayload(alert, w.opts)
 	if err != nil {
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	var errs []error
+	for _, url := range urls {
+		err := w.opts.Retry.retry(ctx, func(ctx context.Context) error {
+			return w.post(ctx, url, data)
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", d.webhookURL, bytes.NewReader(data))
+// post makes one delivery attempt. 4xx responses are permanent: the
+// receiver rejected the alert and will again.
+func (w *webhookChannel) post(ctx context.Context, url string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
 	if err != nil {
-		return err
+		return permanentError{err}
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if w.opts.Secret != "" {
+		signRequest(req, w.opts.Secret, data, time.Now())
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	client := w.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
+	switch {
+	case resp.StatusCode >= 500:
 		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return permanentError{fmt.Errorf("webhook returned %d", resp.StatusCode)}
 	}
 
 	return nil
 }
 
-// escapeMarkdown escapes Markdown special characters
+// webhookPayload marshals the alert for a webhook, stripping the
+// screenshot and then metadata if the payload would exceed the limit.
+// Telegram still receives the full alert.
+func webhookPayload(alert Alert, opts WebhookOptions) ([]byte, error) {
+	if opts.ExcludeScreenshot {
+		alert.Screenshot = nil
+	}
+
+	data, err := json.Marshal(alert)
+	if err != nil || opts.MaxPayloadBytes <= 0 || len(data) <= opts.MaxPayloadBytes {
+		return data, err
+	}
+
+	alert.Truncated = true
+	for _, strip := range []func(*Alert){
+		func(a *Alert) { a.Screenshot = nil },
+		func(a *Alert) { a.Metadata = nil },
+	} {
+		strip(&alert)
+		if data, err = json.Marshal(alert); err != nil {
+			return nil, err
+		}
+		if len(data) <= opts.MaxPayloadBytes {
+			return data, nil
+		}
+	}
+
+	return nil, fmt.Errorf("payload of %d bytes exceeds limit of %d", len(data), opts.MaxPayloadBytes)
+}
+
+// markdownV2Specials are the characters Telegram's MarkdownV2 requires
+// escaped outside entities, the escaping backslash included
+const markdownV2Specials = "\\_*[]()~`>#+-=|{}.!"
+
+// escapeMarkdown escapes text for Telegram's MarkdownV2, prefixing each
+// special character with a backslash in a single pass
 func escapeMarkdown(text string) string {
-	chars := []rune{'_', '*', '[', ']', '(', ')', '~', '`', '>', '#', '+', '-', '=', '|', '{', '}', '.', '!'}
-	result := []rune(text)
-	
-	for i := 0; i < len(result); i++ {
-		for _, char := range chars {
-			if result[i] == char {
-				result = append(result[:i], append([]rune{'\\', char}, result[i+1:]...)...)
-				i++
-				break
-			}
+	var b strings.Builder
+	b.Grow(len(text))
+	for _, r := range text {
+		if strings.ContainsRune(markdownV2Specials, r) {
+			b.WriteByte('\\')
 		}
+		b.WriteRune(r)
 	}
-	
-	return string(result)
+	return b.String()
 }
@@ -0,0 +1,180 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakeChannel records the alerts sent to it and fails with err
+type fakeChannel struct {
+	name string
+	err  error
+	sent []Alert
+}
+
+func (c *fakeChannel) Name() string                  { return c.name }
+func (c *fakeChannel) Accepts(level AlertLevel) bool { return true }
+
+func (c *fakeChannel) Send(ctx context.Context, alert Alert) error {
+	c.sent = append(c.sent, alert)
+	return c.err
+}
+
+func testAlert() Alert {
+	return Alert{Level: Warning, Timestamp: time.Now(), Target: "colosseo", Message: "test"}
+}
+
+func TestDispatchSingleChannelFailed(t *testing.T) {
+	d := NewDispatcher(nil, 0, 0, "")
+	telegram := &fakeChannel{name: "telegram", err: errors.New("bad gateway")}
+	d.Register(telegram)
+
+	err := d.Dispatch(context.Background(), testAlert())
+	if !errors.Is(err, errAllChannelsFailed) {
+		t.Fatalf("Dispatch() = %v, want %v", err, errAllChannelsFailed)
+	}
+	if len(telegram.sent) != 1 {
+		t.Errorf("telegram got %d sends, want 1", len(telegram.sent))
+	}
+}
+
+func TestDispatchTwoChannels(t *testing.T) {
+	tests := []struct {
+		name       string
+		telegram   error
+		webhook    error
+		wantFailed bool
+	}{
+		{"both failed", errors.New("bad gateway"), errors.New("timeout"), true},
+		{"one failed", errors.New("bad gateway"), nil, false},
+		{"none failed", nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDispatcher(nil, 0, 0, "")
+			telegram := &fakeChannel{name: "telegram", err: tt.telegram}
+			webhook := &fakeChannel{name: "webhook", err: tt.webhook}
+			d.Register(telegram)
+			d.Register(webhook)
+
+			err := d.Dispatch(context.Background(), testAlert())
+			if got := errors.Is(err, errAllChannelsFailed); got != tt.wantFailed {
+				t.Fatalf("Dispatch() = %v, want all-failed %v", err, tt.wantFailed)
+			}
+			if !tt.wantFailed && err != nil {
+				t.Fatalf("Dispatch() = %v, want nil", err)
+			}
+			if len(telegram.sent) != 1 || len(webhook.sent) != 1 {
+				t.Errorf("sends = telegram %d, webhook %d, want 1 each", len(telegram.sent), len(webhook.sent))
+			}
+		})
+	}
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", ""},
+		{"plain", "Colosseum tickets", "Colosseum tickets"},
+		{"price", "EUR 24.00", `EUR 24\.00`},
+		{"consecutive specials", "**__", `\*\*\_\_`},
+		{"backslash", `a\b`, `a\\b`},
+		{"every special", "\\_*[]()~`>#+-=|{}.!", "\\\\\\_\\*\\[\\]\\(\\)\\~\\`\\>\\#\\+\\-\\=\\|\\{\\}\\.\\!"},
+		{"link", "[book](https://ticketing.colosseo.it/?a=1)", `\[book\]\(https://ticketing\.colosseo\.it/?a\=1\)`},
+		{"unicode", "Colosseo – ingresso è già disponibile! 🎟", `Colosseo – ingresso è già disponibile\! 🎟`},
+		{"unicode with specials", "Parco—Foro_Romano.", `Parco—Foro\_Romano\.`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeMarkdown(tt.in); got != tt.want {
+				t.Errorf("escapeMarkdown(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// telegramServer fakes the Bot API, failing sends to the failing chat
+// and recording the chats each send went to
+func telegramServer(t *testing.T, failing string) (*telegramChannel, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var chats []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/getMe") {
+			fmt.Fprint(w, `{"ok":true,"result":{"id":1,"is_bot":true,"username":"colosseo_bot"}}`)
+			return
+		}
+		chat := r.FormValue("chat_id")
+		mu.Lock()
+		chats = append(chats, chat)
+		mu.Unlock()
+		if chat == failing {
+			fmt.Fprint(w, `{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"ok":true,"result":{"message_id":1,"chat":{"id":%s}}}`, chat)
+	}))
+	t.Cleanup(srv.Close)
+
+	bot, err := tgbotapi.NewBotAPIWithClient("token", srv.URL+"/bot%s/%s", srv.Client())
+	if err != nil {
+		t.Fatalf("NewBotAPIWithClient: %v", err)
+	}
+	sent := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), chats...)
+	}
+	return &telegramChannel{bot: bot, pace: newTokenBucket(100, 10)}, sent
+}
+
+// One chat of a multi-chat target failing is redelivered to that chat
+// alone, not to the chats that already got the alert
+func TestTelegramRedeliversOnlyMissedChats(t *testing.T) {
+	telegram, sent := telegramServer(t, "200")
+	alert := testAlert()
+	alert.Recipients = &Recipients{ChatIDs: []int64{100, 200, 300}}
+
+	err := telegram.Send(context.Background(), alert)
+	if err == nil {
+		t.Fatal("Send succeeded, want chat 200 to fail")
+	}
+	if got := missedChats(fmt.Errorf("telegram: %w", err)); len(got) != 1 || got[0] != 200 {
+		t.Fatalf("missedChats = %v, want [200]", got)
+	}
+	if got := strings.Join(sent(), ","); got != "100,200,300" {
+		t.Errorf("first send went to chats %s, want 100,200,300", got)
+	}
+
+	// What send persists for the failed telegram group
+	alert.Channels = []string{"telegram"}
+	alert.RetryChats = missedChats(err)
+	data, err := encodeUndelivered(alert)
+	if err != nil {
+		t.Fatalf("encodeUndelivered: %v", err)
+	}
+	persisted, err := decodeUndelivered(data)
+	if err != nil {
+		t.Fatalf("decodeUndelivered: %v", err)
+	}
+
+	d := NewDispatcher(nil, 0, 0, "")
+	d.Register(telegram)
+	d.SetRecipients(map[string]Recipients{alert.Target: {ChatIDs: []int64{100, 200, 300}}})
+	d.redeliver(context.Background(), persisted)
+	if got := strings.Join(sent()[3:], ","); got != "200" {
+		t.Errorf("redelivery went to chats %s, want only 200", got)
+	}
+}
@@ -0,0 +1,228 @@
+// internal/notify/email.go - SMTP channel for critical alerts
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EmailOptions configures the SMTP channel
+type EmailOptions struct {
+	Host string
+	Port int // default 587; 465 uses implicit TLS
+	// Username and Password enable PLAIN auth, which net/smtp only sends
+	// over TLS or to localhost
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// Default SMTP submission port and the implicit-TLS one
+const (
+	defaultSMTPPort = 587
+	smtpsPort       = 465
+)
+
+// emailChannel mails critical alerts, as a last resort when chat and
+// webhook channels are down: route it as the end of a failover group,
+// e.g. "telegram>webhook>email"
+type emailChannel struct {
+	opts EmailOptions
+	tls  *tls.Config
+}
+
+// SetEmail enables the SMTP channel
+func (d *Dispatcher) SetEmail(opts EmailOptions) error {
+	if opts.Host == "" || opts.From == "" || len(opts.To) == 0 {
+		return fmt.Errorf("email: host, from and to are required")
+	}
+	if opts.Port == 0 {
+		opts.Port = defaultSMTPPort
+	}
+	cfg := d.tlsConfig()
+	cfg.ServerName = opts.Host
+	d.Register(&emailChannel{opts: opts, tls: cfg})
+	return nil
+}
+
+func (e *emailChannel) Name() string { return "email" }
+
+func (e *emailChannel) Accepts(level AlertLevel) bool { return level == Critical }
+
+// Send mails alert to every recipient in one transaction
+func (e *emailChannel) Send(ctx context.Context, alert Alert) error {
+	msg, err := emailMessage(alert, e.opts.From, e.opts.To)
+	if err != nil {
+		return err
+	}
+
+	c, err := e.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if e.opts.Port != smtpsPort {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(e.tls); err != nil {
+				return fmt.Errorf("starttls: %w", err)
+			}
+		}
+	}
+	if e.opts.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.opts.Username, e.opts.Password, e.opts.Host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+	if err := c.Mail(e.opts.From); err != nil {
+		return err
+	}
+	for _, to := range e.opts.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("recipient %s: %w", to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// dial connects to the server, bounding the whole session by ctx
+func (e *emailChannel) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(e.opts.Host, strconv.Itoa(e.opts.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if e.opts.Port == smtpsPort {
+		conn = tls.Client(conn, e.tls)
+	}
+	c, err := smtp.NewClient(conn, e.opts.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// emailMessage renders alert as a multipart/mixed email: plain-text and
+// HTML alternatives, plus the screenshot when there is one
+func emailMessage(alert Alert, from string, to []string) ([]byte, error) {
+	style := alertStyle(alert)
+	subject := style.Prefix() + ": " + headline(alert, "Tickets Available")
+	if alert.Target != "" {
+		subject += " - " + alert.Target
+	}
+
+	lines := []string{
+		"Target: " + alert.Target,
+		fmt.Sprintf("Confidence: %.0f%%", alert.Confidence*100),
+		"Status: " + string(alert.Availability),
+	}
+	if !alert.Timestamp.IsZero() {
+		lines = append(lines, "Time: "+alert.Timestamp.Format(time.RFC1123))
+	}
+	if alert.Price != nil {
+		lines = append(lines, "Price: "+alert.Price.String())
+	}
+	if alert.Link != "" {
+		lines = append(lines, "Book: "+alert.Link)
+	}
+	if alert.Message != "" {
+		lines = append([]string{alert.Message, ""}, lines...)
+	}
+
+	var buf bytes.Buffer
+	mixed := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mixed.Boundary())
+
+	// The alternatives nest in their own part of the mixed body
+	var alt bytes.Buffer
+	altWriter := multipart.NewWriter(&alt)
+	text := strings.Join(lines, "\r\n") + "\r\n"
+	if err := writePart(altWriter, "text/plain; charset=utf-8", "", []byte(text)); err != nil {
+		return nil, err
+	}
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		escaped[i] = html.EscapeString(line)
+	}
+	body := fmt.Sprintf("<h2>%s</h2>\r\n<p>%s</p>\r\n", html.EscapeString(subject), strings.Join(escaped, "<br>\r\n"))
+	if err := writePart(altWriter, "text/html; charset=utf-8", "", []byte(body)); err != nil {
+		return nil, err
+	}
+	if err := altWriter.Close(); err != nil {
+		return nil, err
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", "multipart/alternative; boundary="+altWriter.Boundary())
+	part, err := mixed.CreatePart(header)
+	if err != nil {
+		return nil, err
+	}
+	part.Write(alt.Bytes())
+
+	if len(alert.Screenshot) > 0 {
+		if err := writePart(mixed, "image/png", screenshotFilename, alert.Screenshot); err != nil {
+			return nil, err
+		}
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writePart adds a base64 part to w, as an attachment when filename is set
+func writePart(w *multipart.Writer, contentType, filename string, data []byte) error {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "base64")
+	if filename != "" {
+		header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	}
+	part, err := w.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	// 76-column lines, as RFC 2045 requires
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		fmt.Fprintf(part, "%s\r\n", encoded[:76])
+		encoded = encoded[76:]
+	}
+	_, err = fmt.Fprintf(part, "%s\r\n", encoded)
+	return err
+}
@@ -0,0 +1,194 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpSession is what the mock server was sent
+type smtpSession struct {
+	from string
+	to   []string
+	data []byte
+}
+
+// mockSMTP accepts one session, without STARTTLS or AUTH, rejecting
+// recipients in reject
+func mockSMTP(t *testing.T, reject string) (EmailOptions, <-chan smtpSession) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	sessions := make(chan smtpSession, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		var s smtpSession
+		tp.PrintfLine("220 mock ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch {
+			case cmd == "EHLO" || cmd == "HELO":
+				tp.PrintfLine("250 mock")
+			case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+				s.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				tp.PrintfLine("250 OK")
+			case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+				to := strings.Trim(line[len("RCPT TO:"):], "<>")
+				if to == reject {
+					tp.PrintfLine("550 no such user")
+					continue
+				}
+				s.to = append(s.to, to)
+				tp.PrintfLine("250 OK")
+			case cmd == "DATA":
+				tp.PrintfLine("354 go ahead")
+				s.data, err = tp.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				tp.PrintfLine("250 queued")
+			case cmd == "QUIT":
+				tp.PrintfLine("221 bye")
+				sessions <- s
+				return
+			default:
+				tp.PrintfLine("502 unsupported")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	return EmailOptions{
+		Host: host,
+		Port: portNum,
+		From: "colosseo@example.com",
+		To:   []string{"ops@example.com", "oncall@example.com"},
+	}, sessions
+}
+
+func TestEmailSend(t *testing.T) {
+	opts, sessions := mockSMTP(t, "")
+	ch := &emailChannel{opts: opts}
+	png := []byte("\x89PNG\r\n\x1a\nfake")
+	alert := Alert{
+		Level:        Critical,
+		Timestamp:    time.Date(2026, 4, 1, 8, 30, 0, 0, time.UTC),
+		Target:       "colosseum-full",
+		Availability: Available,
+		Confidence:   0.9,
+		Price:        &Price{Cents: 2400, Currency: "EUR"},
+		Link:         "https://ticketing.colosseo.it/en/eventi/24h",
+		Screenshot:   png,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := ch.Send(ctx, alert); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	s := <-sessions
+
+	if s.from != opts.From {
+		t.Errorf("MAIL FROM = %q, want %q", s.from, opts.From)
+	}
+	if strings.Join(s.to, ",") != "ops@example.com,oncall@example.com" {
+		t.Errorf("RCPT TO = %v, want both recipients", s.to)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(s.data))
+	if err != nil {
+		t.Fatalf("message: %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if !strings.Contains(subject, "colosseum-full") {
+		t.Errorf("Subject = %q, want the target", subject)
+	}
+	if msg.Header.Get("To") != "ops@example.com, oncall@example.com" {
+		t.Errorf("To = %q, want both recipients", msg.Header.Get("To"))
+	}
+
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mixed := multipart.NewReader(msg.Body, params["boundary"])
+
+	// First the text and HTML alternatives
+	part, err := mixed.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, altParams, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+	alt := multipart.NewReader(part, altParams["boundary"])
+	text, err := alt.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := decodePart(t, text)
+	for _, want := range []string{"Target: colosseum-full", "Confidence: 90%", "Price: EUR 24.00", "Book: " + alert.Link} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("text body lacks %q:\n%s", want, plain)
+		}
+	}
+	if htmlPart, err := alt.NextPart(); err != nil || !strings.HasPrefix(htmlPart.Header.Get("Content-Type"), "text/html") {
+		t.Errorf("second alternative = %v, %v; want text/html", htmlPart, err)
+	}
+
+	// Then the screenshot
+	part, err = mixed.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if part.FileName() != screenshotFilename {
+		t.Errorf("attachment filename = %q, want %q", part.FileName(), screenshotFilename)
+	}
+	if got := decodePart(t, part); got != string(png) {
+		t.Errorf("attachment = %q, want the screenshot", got)
+	}
+}
+
+func TestEmailRejectedRecipient(t *testing.T) {
+	opts, _ := mockSMTP(t, "oncall@example.com")
+	ch := &emailChannel{opts: opts}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := ch.Send(ctx, Alert{Level: Critical, Target: "colosseum-full"})
+	if err == nil || !strings.Contains(err.Error(), "oncall@example.com") {
+		t.Errorf("Send() = %v, want the rejected recipient named", err)
+	}
+}
+
+// decodePart reads a base64 part
+func decodePart(t *testing.T, part *multipart.Part) string {
+	t.Helper()
+	data, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+	if err != nil {
+		t.Fatalf("decode %s: %v", part.Header.Get("Content-Type"), err)
+	}
+	return string(data)
+}
@@ -0,0 +1,97 @@
+// internal/notify/fields.go - Per-alert detail selection
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AlertFields selects which details an availability alert's message
+// carries, by Alert JSON field name. The headline is always included.
+type AlertFields map[string]bool
+
+// alertFieldNames are the Alert fields a formatter can include, in the
+// order they are rendered
+var alertFieldNames = []string{
+	"target", "timestamp", "confidence", "availability", "price", "metadata", "link", "screenshot",
+}
+
+// Formatter defaults, matching the original fixed message layouts
+var (
+	defaultCriticalFields = AlertFields{
+		"target": true, "timestamp": true, "confidence": true, "availability": true,
+		"price": true, "link": true, "screenshot": true,
+	}
+	defaultWarningFields = AlertFields{"target": true, "confidence": true, "link": true}
+)
+
+// ParseAlertFields validates field names against the Alert fields. An
+// empty list selects the headline only.
+func ParseAlertFields(names []string) (AlertFields, error) {
+	fields := make(AlertFields, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !knownAlertField(name) {
+			return nil, fmt.Errorf("unknown alert field %q (want one of %s)", name, strings.Join(alertFieldNames, ", "))
+		}
+		fields[name] = true
+	}
+	return fields, nil
+}
+
+func knownAlertField(name string) bool {
+	for _, f := range alertFieldNames {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// alertFields returns the fields selected for alert, or its level's
+// default layout when none were set
+func alertFields(alert Alert) AlertFields {
+	if alert.Fields != nil {
+		return alert.Fields
+	}
+	if alert.Level == Critical {
+		return defaultCriticalFields
+	}
+	return defaultWarningFields
+}
+
+// formatFields renders the selected text fields one per line, escaped
+// for MarkdownV2
+func formatFields(alert Alert, fields AlertFields) []string {
+	var lines []string
+	for _, name := range alertFieldNames {
+		if !fields[name] {
+			continue
+		}
+		switch name {
+		case "target":
+			lines = append(lines, "📍 Target: "+escapeMarkdown(alert.Target))
+		case "timestamp":
+			lines = append(lines, "⏰ Time: "+escapeMarkdown(alert.Timestamp.Format("15:04:05.000")))
+		case "confidence":
+			lines = append(lines, fmt.Sprintf("🎯 Confidence: %.0f%%", alert.Confidence*100))
+		case "availability":
+			lines = append(lines, "📊 Status: "+escapeMarkdown(string(alert.Availability)))
+		case "price":
+			if alert.Price != nil {
+				lines = append(lines, "💶 Price: "+escapeMarkdown(alert.Price.String()))
+			}
+		case "metadata":
+			keys := make([]string, 0, len(alert.Metadata))
+			for k := range alert.Metadata {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				lines = append(lines, escapeMarkdown(fmt.Sprintf("• %s: %v", k, alert.Metadata[k])))
+			}
+		}
+	}
+	return lines
+}
@@ -0,0 +1,215 @@
+// internal/notify/grpc.go - gRPC alert stream for typed subscribers
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"colosseo-orchestrator/internal/notify/alertpb"
+)
+
+// DefaultStreamBuffer is how many alerts queue per subscriber before
+// newer ones are dropped for it
+const DefaultStreamBuffer = 64
+
+// streamStopGrace bounds how long shutdown waits for subscribers to
+// drain before connections are cut
+const streamStopGrace = 5 * time.Second
+
+// StreamDropped counts alerts dropped for gRPC subscribers that fell
+// behind. The caller registers it alongside its own metrics.
+var StreamDropped = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "colosseo_alert_stream_dropped_total",
+	Help: "Alerts dropped for gRPC subscribers whose queue was full",
+})
+
+// GRPCOptions configures the alert stream server
+type GRPCOptions struct {
+	Address string // host:port to listen on
+	Buffer  int    // alerts queued per subscriber (default 64)
+}
+
+// streamChannel fans dispatched alerts out to SubscribeAlerts callers.
+// Each subscriber has its own bounded queue, so one that reads slowly
+// loses alerts (reported in the next one it receives) instead of
+// blocking Dispatch or the other subscribers.
+type streamChannel struct {
+	alertpb.UnimplementedAlertStreamServer
+
+	buffer int
+	mu     sync.Mutex
+	subs   map[*subscriber]struct{}
+	closed chan struct{}
+}
+
+// subscriber is one open SubscribeAlerts stream and its filters
+type subscriber struct {
+	targets map[string]bool     // empty = every target
+	levels  map[AlertLevel]bool // empty = every level
+	queue   chan *alertpb.Alert
+	dropped uint64 // since the last queued alert; guarded by streamChannel.mu
+}
+
+// ServeGRPC registers the "grpc" channel and serves SubscribeAlerts on
+// opts.Address until ctx is cancelled. Open streams then end with
+// Unavailable.
+func (d *Dispatcher) ServeGRPC(ctx context.Context, opts GRPCOptions) error {
+	if opts.Buffer <= 0 {
+		opts.Buffer = DefaultStreamBuffer
+	}
+	lis, err := net.Listen("tcp", opts.Address)
+	if err != nil {
+		return err
+	}
+
+	ch := &streamChannel{
+		buffer: opts.Buffer,
+		subs:   make(map[*subscriber]struct{}),
+		closed: make(chan struct{}),
+	}
+	d.Register(ch)
+
+	// Keepalive pings find subscribers that vanished without closing
+	srv := grpc.NewServer(grpc.KeepaliveParams(keepalive.ServerParameters{
+		Time:    30 * time.Second,
+		Timeout: 10 * time.Second,
+	}))
+	alertpb.RegisterAlertStreamServer(srv, ch)
+
+	go func() {
+		<-ctx.Done()
+		close(ch.closed)
+		stopped := make(chan struct{})
+		go func() {
+			srv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(streamStopGrace):
+			srv.Stop()
+		}
+	}()
+
+	return srv.Serve(lis)
+}
+
+func (s *streamChannel) Name() string { return "grpc" }
+
+func (s *streamChannel) Accepts(AlertLevel) bool { return true }
+
+// Send queues alert for every matching subscriber without waiting on any
+func (s *streamChannel) Send(ctx context.Context, alert Alert) error {
+	msg := alertProto(alert)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subs {
+		if !sub.wants(alert) {
+			continue
+		}
+		// The message is shared unless it carries this subscriber's drops
+		out := msg
+		if sub.dropped > 0 {
+			out = proto.Clone(msg).(*alertpb.Alert)
+			out.Dropped = sub.dropped
+		}
+		select {
+		case sub.queue <- out:
+			sub.dropped = 0
+		default:
+			sub.dropped++
+			StreamDropped.Inc()
+		}
+	}
+	return nil
+}
+
+// SubscribeAlerts streams matching alerts until the client goes away or
+// the server shuts down
+func (s *streamChannel) SubscribeAlerts(req *alertpb.SubscribeRequest, stream alertpb.AlertStream_SubscribeAlertsServer) error {
+	sub := &subscriber{
+		targets: make(map[string]bool, len(req.Targets)),
+		levels:  make(map[AlertLevel]bool, len(req.Levels)),
+		queue:   make(chan *alertpb.Alert, s.buffer),
+	}
+	for _, target := range req.Targets {
+		sub.targets[target] = true
+	}
+	for _, level := range req.Levels {
+		if _, ok := alertpb.AlertLevel_name[int32(level)]; !ok {
+			return status.Errorf(codes.InvalidArgument, "unknown alert level %d", level)
+		}
+		sub.levels[AlertLevel(level)] = true
+	}
+
+	s.mu.Lock()
+	s.subs[sub] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs, sub)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.closed:
+			return status.Error(codes.Unavailable, "alert stream shutting down")
+		case msg := <-sub.queue:
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// wants reports whether alert passes the subscriber's filters
+func (sub *subscriber) wants(alert Alert) bool {
+	if len(sub.targets) > 0 && !sub.targets[alert.Target] {
+		return false
+	}
+	return len(sub.levels) == 0 || sub.levels[alert.Level]
+}
+
+// alertProto converts alert to its wire form. Screenshots are left out;
+// metadata values are rendered as strings.
+func alertProto(alert Alert) *alertpb.Alert {
+	ts := alert.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	msg := &alertpb.Alert{
+		Id:           alert.ID,
+		Level:        alertpb.AlertLevel(alert.Level),
+		TimestampMs:  ts.UnixMilli(),
+		Target:       alert.Target,
+		Availability: string(alert.Availability),
+		Confidence:   alert.Confidence,
+		Message:      alert.Message,
+		Headline:     alert.Headline,
+		Link:         alert.Link,
+	}
+	if alert.Price != nil {
+		msg.Price = &alertpb.Price{Cents: alert.Price.Cents, Currency: alert.Price.Currency}
+	}
+	if len(alert.Metadata) > 0 {
+		msg.Metadata = make(map[string]string, len(alert.Metadata))
+		for k, v := range alert.Metadata {
+			msg.Metadata[k] = fmt.Sprint(v)
+		}
+	}
+	return msg
+}
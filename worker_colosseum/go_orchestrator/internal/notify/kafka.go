@@ -0,0 +1,197 @@
+// internal/notify/kafka.go - Kafka alert channel for downstream consumers
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// KafkaOptions configures the Kafka channel
+type KafkaOptions struct {
+	Brokers []string
+	Topic   string
+	// Levels selects which alert levels are published (empty = all)
+	Levels []AlertLevel
+	// Mechanism is the SASL mechanism: "plain", "scram-sha-256" or
+	// "scram-sha-512". Empty disables SASL.
+	Mechanism string
+	Username  string
+	Password  string
+	TLS       bool
+}
+
+// KafkaPublishFailures counts alerts whose Kafka batch failed. The
+// caller registers it alongside its own metrics.
+var KafkaPublishFailures = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "colosseo_kafka_failures_total",
+	Help: "Alerts that failed to publish to Kafka",
+})
+
+// kafkaChannel publishes alerts as JSON, keyed by target so every alert
+// for a target lands on the same partition in order
+type kafkaChannel struct {
+	writer   *kafka.Writer
+	levels   map[AlertLevel]bool
+	failures atomic.Int64
+}
+
+// SetKafka enables the Kafka channel. Messages are produced
+// asynchronously, so an unavailable broker never blocks Dispatch; failed
+// batches are counted and persisted for redelivery to Kafka alone, when
+// redelivery is enabled.
+func (d *Dispatcher) SetKafka(opts KafkaOptions) error {
+	if len(opts.Brokers) == 0 || opts.Topic == "" {
+		return fmt.Errorf("kafka: brokers and topic are required")
+	}
+
+	mechanism, err := kafkaMechanism(opts)
+	if err != nil {
+		return err
+	}
+	transport := &kafka.Transport{SASL: mechanism}
+	if opts.TLS {
+		transport.TLS = d.tlsConfig()
+	}
+
+	k := &kafkaChannel{levels: make(map[AlertLevel]bool, len(opts.Levels))}
+	for _, level := range opts.Levels {
+		k.levels[level] = true
+	}
+	k.writer = &kafka.Writer{
+		Addr:         kafka.TCP(opts.Brokers...),
+		Topic:        opts.Topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+		BatchTimeout: 50 * time.Millisecond,
+		Async:        true,
+		Transport:    transport,
+		Completion: func(messages []kafka.Message, err error) {
+			if err != nil {
+				d.kafkaFailed(k, messages, err)
+			}
+		},
+	}
+
+	d.Register(k)
+	return nil
+}
+
+// KafkaFailures returns how many alerts failed to reach Kafka
+func (d *Dispatcher) KafkaFailures() int64 {
+	ch, ok := d.Channel("kafka")
+	if !ok {
+		return 0
+	}
+	return ch.(*kafkaChannel).failures.Load()
+}
+
+func (k *kafkaChannel) Name() string { return "kafka" }
+
+// Accepts reports whether level is among the configured levels (all when unset)
+func (k *kafkaChannel) Accepts(level AlertLevel) bool {
+	return len(k.levels) == 0 || k.levels[level]
+}
+
+// Close flushes queued messages and closes the writer
+func (k *kafkaChannel) Close() error {
+	return k.writer.Close()
+}
+
+// Send queues the alert for publishing
+func (k *kafkaChannel) Send(ctx context.Context, alert Alert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	// Async writers return immediately; delivery errors arrive in Completion
+	return k.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(alert.Target),
+		Value: data,
+		Time:  alert.Timestamp,
+	})
+}
+
+// kafkaFailed counts a failed batch and persists its alerts for
+// redelivery to Kafka, or else requeues them on the fallback channel.
+// Send already reported them delivered, so this is the only place a
+// Kafka failure surfaces.
+func (d *Dispatcher) kafkaFailed(k *kafkaChannel, messages []kafka.Message, err error) {
+	k.failures.Add(int64(len(messages)))
+	KafkaPublishFailures.Add(float64(len(messages)))
+	slog.Error("Kafka publish failed", "alerts", len(messages), "err", err)
+
+	d.mu.RLock()
+	r := d.redelivery
+	d.mu.RUnlock()
+	if r == nil && d.fallbackCh == nil {
+		return
+	}
+	for _, m := range messages {
+		var alert Alert
+		if json.Unmarshal(m.Value, &alert) != nil {
+			continue
+		}
+		if r != nil {
+			alert.Channels = []string{k.Name()}
+			r.persist(alert)
+			continue
+		}
+		select {
+		case d.fallbackCh <- alert:
+		default: // Non-blocking
+		}
+	}
+}
+
+func kafkaMechanism(opts KafkaOptions) (sasl.Mechanism, error) {
+	switch strings.ToLower(opts.Mechanism) {
+	case "":
+		return nil, nil
+	case "plain":
+		return plain.Mechanism{Username: opts.Username, Password: opts.Password}, nil
+	case "scram-sha-256":
+		return scram.Mechanism(scram.SHA256, opts.Username, opts.Password)
+	case "scram-sha-512":
+		return scram.Mechanism(scram.SHA512, opts.Username, opts.Password)
+	default:
+		return nil, fmt.Errorf("kafka: unsupported SASL mechanism %q", opts.Mechanism)
+	}
+}
+
+// String returns the level's name as accepted by ParseLevel
+func (l AlertLevel) String() string {
+	switch l {
+	case Warning:
+		return "warning"
+	case Critical:
+		return "critical"
+	default:
+		return "info"
+	}
+}
+
+// ParseLevel converts "info", "warning" or "critical" to an AlertLevel
+func ParseLevel(s string) (AlertLevel, error) {
+	switch strings.ToLower(s) {
+	case "info":
+		return Info, nil
+	case "warning":
+		return Warning, nil
+	case "critical":
+		return Critical, nil
+	default:
+		return Info, fmt.Errorf("unknown alert level %q", s)
+	}
+}
@@ -0,0 +1,121 @@
+// internal/notify/quiet.go - Quiet hours holding back non-critical alerts
+package notify
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ActiveHours is a weekly schedule of when alerts go out normally
+type ActiveHours interface {
+	Active(t time.Time) bool
+	// NextStart returns when the next active period begins after t
+	NextStart(t time.Time) time.Time
+}
+
+// QuietHoursOptions mute non-critical alerts outside active hours.
+// Critical alerts always go out.
+type QuietHoursOptions struct {
+	Hours ActiveHours
+	// Hold keeps each target's latest muted alert and delivers it when
+	// active hours resume; without it muted alerts are dropped
+	Hold bool
+}
+
+// quietHours holds or drops alerts outside active hours
+type quietHours struct {
+	opts QuietHoursOptions
+	now  func() time.Time
+	send func(ctx context.Context, alert Alert) error
+
+	mu    sync.Mutex
+	held  map[string]Alert // target -> latest muted alert
+	order []string         // targets in the order first held
+	timer *time.Timer
+}
+
+// SetQuietHours mutes non-critical alerts outside opts.Hours. A nil
+// Hours disables quiet hours.
+func (d *Dispatcher) SetQuietHours(opts QuietHoursOptions) {
+	if d.quiet != nil {
+		d.quiet.stop()
+	}
+	if opts.Hours == nil {
+		d.quiet = nil
+		return
+	}
+	d.quiet = &quietHours{
+		opts: opts,
+		now:  time.Now,
+		send: d.Dispatch,
+		held: make(map[string]Alert),
+	}
+}
+
+// mute reports whether alert falls in quiet hours, holding it for
+// delivery when they end if configured
+func (q *quietHours) mute(alert Alert) bool {
+	now := q.now()
+	if alert.Level == Critical || q.opts.Hours.Active(now) {
+		return false
+	}
+	if !q.opts.Hold {
+		return true
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.held[alert.Target]; !ok {
+		q.order = append(q.order, alert.Target)
+	}
+	q.held[alert.Target] = alert
+	if q.timer == nil {
+		if next := q.opts.Hours.NextStart(now); !next.IsZero() {
+			q.timer = time.AfterFunc(next.Sub(now), q.flush)
+		}
+	}
+	return true
+}
+
+// flush dispatches the held alerts once active hours resume. They go
+// through Dispatch again, so suppression and the alert cap still apply.
+func (q *quietHours) flush() {
+	q.mu.Lock()
+	alerts := make([]Alert, 0, len(q.order))
+	for _, target := range q.order {
+		alerts = append(alerts, q.held[target])
+	}
+	q.held = make(map[string]Alert)
+	q.order = nil
+	q.timer = nil
+	q.mu.Unlock()
+
+	if len(alerts) == 0 {
+		return
+	}
+	slog.Info("Quiet hours over, delivering held alerts", "alerts", len(alerts))
+	for _, alert := range alerts {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := q.send(ctx, alert); err != nil {
+			slog.Error("Held alert failed", "target", alert.Target, "alert_id", alert.ID, "err", err)
+		}
+		cancel()
+	}
+}
+
+// stop cancels the pending flush; held alerts are dropped
+func (q *quietHours) stop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.timer != nil {
+		q.timer.Stop()
+		q.timer = nil
+	}
+	if len(q.held) > 0 {
+		slog.Warn("Dropping alerts held over quiet hours", "alerts", len(q.held))
+	}
+	q.held = make(map[string]Alert)
+	q.order = nil
+}
@@ -0,0 +1,105 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// dailyHours is active from start to end hour, UTC, every day
+type dailyHours struct{ start, end int }
+
+func (h dailyHours) Active(t time.Time) bool {
+	hour := t.UTC().Hour()
+	return hour >= h.start && hour < h.end
+}
+
+func (h dailyHours) NextStart(t time.Time) time.Time {
+	t = t.UTC()
+	next := time.Date(t.Year(), t.Month(), t.Day(), h.start, 0, 0, 0, time.UTC)
+	if !next.After(t) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+func quietDispatcher(t *testing.T, hold bool, now time.Time) (*Dispatcher, *fakeChannel) {
+	t.Helper()
+	d := NewDispatcher(nil, 0, 0, "")
+	ch := &fakeChannel{name: "telegram"}
+	d.Register(ch)
+	d.SetQuietHours(QuietHoursOptions{Hours: dailyHours{start: 8, end: 22}, Hold: hold})
+	d.quiet.now = func() time.Time { return now }
+	t.Cleanup(func() { d.SetQuietHours(QuietHoursOptions{}) })
+	return d, ch
+}
+
+func TestQuietHoursInsideActiveWindow(t *testing.T) {
+	d, ch := quietDispatcher(t, false, time.Date(2026, 4, 1, 14, 0, 0, 0, time.UTC))
+
+	if err := d.Dispatch(context.Background(), Alert{Level: Warning, Target: "arena", Message: "breaker opened"}); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if len(ch.sent) != 1 {
+		t.Errorf("sent %d alerts in active hours, want 1", len(ch.sent))
+	}
+}
+
+func TestQuietHoursOutsideActiveWindow(t *testing.T) {
+	d, ch := quietDispatcher(t, false, time.Date(2026, 4, 1, 23, 30, 0, 0, time.UTC))
+
+	d.Dispatch(context.Background(), Alert{Level: Warning, Target: "arena", Message: "breaker opened"})
+	d.Dispatch(context.Background(), Alert{Level: Info, Target: "arena", Message: "config reloaded"})
+	if len(ch.sent) != 0 {
+		t.Fatalf("sent %d non-critical alerts in quiet hours, want 0", len(ch.sent))
+	}
+
+	// Critical alerts always go out
+	d.Dispatch(context.Background(), Alert{Level: Critical, Target: "arena", Availability: Available})
+	if len(ch.sent) != 1 || ch.sent[0].Level != Critical {
+		t.Errorf("sent %v, want only the critical alert", ch.sent)
+	}
+}
+
+func TestQuietHoursBoundaries(t *testing.T) {
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, 4, 1, 7, 59, 59, 0, time.UTC), false},
+		{time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 4, 1, 21, 59, 59, 0, time.UTC), true},
+		{time.Date(2026, 4, 1, 22, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		d, ch := quietDispatcher(t, false, tt.at)
+		d.Dispatch(context.Background(), Alert{Level: Warning, Target: "arena", Message: "breaker opened"})
+		if got := len(ch.sent) == 1; got != tt.want {
+			t.Errorf("at %s: delivered %v, want %v", tt.at.Format("15:04:05"), got, tt.want)
+		}
+	}
+}
+
+func TestQuietHoursHoldDeliversLatestPerTarget(t *testing.T) {
+	now := time.Date(2026, 4, 1, 23, 30, 0, 0, time.UTC)
+	d, ch := quietDispatcher(t, true, now)
+
+	d.Dispatch(context.Background(), Alert{Level: Warning, Target: "arena", Message: "first"})
+	d.Dispatch(context.Background(), Alert{Level: Warning, Target: "forum", Message: "forum"})
+	d.Dispatch(context.Background(), Alert{Level: Warning, Target: "arena", Message: "latest"})
+	if len(ch.sent) != 0 {
+		t.Fatalf("sent %d alerts in quiet hours, want them held", len(ch.sent))
+	}
+
+	// Active hours resume
+	d.quiet.now = func() time.Time { return now.Add(9 * time.Hour) }
+	d.quiet.flush()
+
+	if len(ch.sent) != 2 {
+		t.Fatalf("delivered %d held alerts, want 2", len(ch.sent))
+	}
+	if ch.sent[0].Target != "arena" || ch.sent[0].Message != "latest" || ch.sent[1].Target != "forum" {
+		t.Errorf("delivered %q/%q then %q, want arena's latest then forum",
+			ch.sent[0].Target, ch.sent[0].Message, ch.sent[1].Target)
+	}
+}
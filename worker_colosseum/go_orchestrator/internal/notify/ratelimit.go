@@ -0,0 +1,120 @@
+// internal/notify/ratelimit.go - Per-target alert cap with overflow summary
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SuppressedAlerts counts alerts dropped by the per-target cap. The
+// caller registers it alongside its own metrics.
+var SuppressedAlerts = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "colosseo_alerts_suppressed_total",
+		Help: "Alerts suppressed by the per-target alert cap",
+	},
+	[]string{"target"},
+)
+
+// RateLimitOptions caps how many alerts one target may send per window
+type RateLimitOptions struct {
+	MaxAlerts int           // alerts allowed per window (0 = unlimited)
+	Window    time.Duration // sliding window length (default 1h)
+	// ExemptCritical lets Critical alerts through even when capped
+	ExemptCritical bool
+}
+
+// rateLimiter is a sliding-window alert counter per target. Once a
+// target is over its cap, further alerts are counted instead of sent and
+// a single summary goes out when the window rolls.
+type rateLimiter struct {
+	mu         sync.Mutex
+	opts       RateLimitOptions
+	sent       map[string][]time.Time
+	suppressed map[string]int
+	summarize  func(target string, suppressed int, window time.Duration)
+}
+
+// SetRateLimit enables the per-target alert cap
+func (d *Dispatcher) SetRateLimit(opts RateLimitOptions) {
+	if opts.MaxAlerts <= 0 {
+		d.limiter = nil
+		return
+	}
+	if opts.Window <= 0 {
+		opts.Window = time.Hour
+	}
+	d.limiter = &rateLimiter{
+		opts:       opts,
+		sent:       make(map[string][]time.Time),
+		suppressed: make(map[string]int),
+		summarize:  d.sendSummary,
+	}
+}
+
+// allow reports whether the alert fits under its target's cap
+func (l *rateLimiter) allow(alert Alert, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop sends that have slid out of the window
+	sent := l.sent[alert.Target]
+	cutoff := now.Add(-l.opts.Window)
+	i := 0
+	for i < len(sent) && !sent[i].After(cutoff) {
+		i++
+	}
+	sent = sent[i:]
+
+	if len(sent) < l.opts.MaxAlerts || (l.opts.ExemptCritical && alert.Level == Critical) {
+		l.sent[alert.Target] = append(sent, now)
+		return true
+	}
+	l.sent[alert.Target] = sent
+
+	// First suppression: summarize once the oldest send leaves the window
+	if l.suppressed[alert.Target] == 0 {
+		target := alert.Target
+		time.AfterFunc(sent[0].Add(l.opts.Window).Sub(now), func() {
+			l.flush(target)
+		})
+	}
+	l.suppressed[alert.Target]++
+	SuppressedAlerts.WithLabelValues(alert.Target).Inc()
+	return false
+}
+
+// flush emits the overflow summary for target
+func (l *rateLimiter) flush(target string) {
+	l.mu.Lock()
+	n := l.suppressed[target]
+	delete(l.suppressed, target)
+	l.mu.Unlock()
+
+	if n > 0 {
+		l.summarize(target, n, l.opts.Window)
+	}
+}
+
+// sendSummary delivers the overflow summary, bypassing the cap
+func (d *Dispatcher) sendSummary(target string, suppressed int, window time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	alert := Alert{
+		Level:     Warning,
+		Timestamp: time.Now(),
+		Target:    target,
+		Message: fmt.Sprintf("%s flapped %d times in the last %s; those alerts were suppressed",
+			target, suppressed, window),
+		Metadata: map[string]interface{}{"suppressed": suppressed},
+	}
+	if err := d.deliver(ctx, alert); err != nil {
+		slog.Error("Failed to send alert summary", "target", target, "err", err)
+	}
+}
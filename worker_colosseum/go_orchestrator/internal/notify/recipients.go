@@ -0,0 +1,95 @@
+// internal/notify/recipients.go - Per-target Telegram chats and webhooks
+package notify
+
+import "errors"
+
+// Recipients sends a target's alerts to its own Telegram chats and
+// webhooks instead of the global ones. An empty list falls back to the
+// global destination.
+type Recipients struct {
+	ChatIDs     []int64
+	WebhookURLs []string
+}
+
+// chatsError is a Telegram send some of the alert's chats missed. It
+// names them, so redelivery skips the chats that got the alert.
+type chatsError struct {
+	chats []int64
+	err   error
+}
+
+func (e *chatsError) Error() string { return e.err.Error() }
+func (e *chatsError) Unwrap() error { return e.err }
+
+// missedChats returns the Telegram chats err reports missing an alert,
+// or nil when Telegram wasn't what failed
+func missedChats(err error) []int64 {
+	var ce *chatsError
+	if errors.As(err, &ce) {
+		return ce.chats
+	}
+	return nil
+}
+
+// errNoRecipient is returned by a channel with nowhere to send an alert;
+// deliver skips the channel instead of counting a failure
+var errNoRecipient = errors.New("no recipient for alert")
+
+// SetRecipients sets the recipients of each target's alerts, by target
+// name, replacing any set before. Targets not in byTarget use the
+// global chat and webhook.
+func (d *Dispatcher) SetRecipients(byTarget map[string]Recipients) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.recipients = byTarget
+}
+
+// EnableWebhook registers the webhook channel without a global URL, so
+// it delivers only alerts of targets with their own webhook recipients.
+// It is a no-op when the channel exists. Call it before
+// SetWebhookOptions and SetTLS, which configure the channel.
+func (d *Dispatcher) EnableWebhook() {
+	if _, ok := d.Channel("webhook"); !ok {
+		d.Register(&webhookChannel{})
+	}
+}
+
+// addressed attaches the target's recipients, unless the alert already
+// carries some
+func (d *Dispatcher) addressed(alert Alert) Alert {
+	if alert.Recipients != nil {
+		return alert
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if r, ok := d.recipients[alert.Target]; ok {
+		alert.Recipients = &r
+	}
+	return alert
+}
+
+// chatIDs returns the Telegram chats alert goes to: on redelivery only
+// the ones that missed it
+func (alert Alert) chatIDs(global int64) []int64 {
+	if len(alert.RetryChats) > 0 {
+		return alert.RetryChats
+	}
+	if alert.Recipients != nil && len(alert.Recipients.ChatIDs) > 0 {
+		return alert.Recipients.ChatIDs
+	}
+	if global == 0 {
+		return nil
+	}
+	return []int64{global}
+}
+
+// webhookURLs returns the webhooks alert is posted to
+func (alert Alert) webhookURLs(global string) []string {
+	if alert.Recipients != nil && len(alert.Recipients.WebhookURLs) > 0 {
+		return alert.Recipients.WebhookURLs
+	}
+	if global == "" {
+		return nil
+	}
+	return []string{global}
+}
@@ -0,0 +1,244 @@
+// internal/notify/redeliver.go - Redis-backed redelivery of failed alerts
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// UndeliveredBacklog is the number of failed alerts waiting in Redis for
+// redelivery. The caller registers it alongside its own metrics.
+var UndeliveredBacklog = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "colosseo_alerts_undelivered_backlog",
+	Help: "Alerts that every channel failed, persisted for redelivery",
+})
+
+// Redelivery defaults
+const (
+	DefaultRedeliveryKey = "colosseo:alerts:undelivered"
+	redeliveryMinBackoff = 30 * time.Second
+	redeliveryMaxBackoff = 10 * time.Minute
+	redeliveryMaxAge     = 24 * time.Hour
+)
+
+// errAllChannelsFailed marks a delivery that no channel accepted, the
+// ones worth persisting for redelivery
+var errAllChannelsFailed = errors.New("all notification channels failed")
+
+// RedeliveryOptions persist alerts every channel failed to a Redis list
+// and retry them in the background until a channel accepts them
+type RedeliveryOptions struct {
+	Client *redis.Client
+	Key    string // list key (default DefaultRedeliveryKey)
+	// MinBackoff is the retry interval, doubling while deliveries keep
+	// failing up to MaxBackoff (defaults 30s and 10m)
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxAge drops alerts older than this instead of retrying them
+	// (default 24h); a day-old availability alert only misleads
+	MaxAge time.Duration
+}
+
+// redeliverer owns the Redis list of undelivered alerts. Each retry
+// rotates an entry to the tail with LMOVE and removes it only once
+// delivered, so a crash mid-retry never loses it.
+type redeliverer struct {
+	opts   RedeliveryOptions
+	send   func(ctx context.Context, alert Alert) error
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// undelivered is the persisted form of an alert: its public JSON plus
+// the delivery details that JSON leaves out. Records written before
+// Fields was persisted decode with the level's default fields.
+type undelivered struct {
+	Alert
+	Fields     AlertFields `json:"fields,omitempty"`
+	Channels   []string    `json:"channels,omitempty"`
+	RetryChats []int64     `json:"retry_chats,omitempty"`
+}
+
+func encodeUndelivered(alert Alert) ([]byte, error) {
+	return json.Marshal(undelivered{
+		Alert:      alert,
+		Fields:     alert.Fields,
+		Channels:   alert.Channels,
+		RetryChats: alert.RetryChats,
+	})
+}
+
+func decodeUndelivered(raw []byte) (Alert, error) {
+	var u undelivered
+	if err := json.Unmarshal(raw, &u); err != nil {
+		return Alert{}, err
+	}
+	alert := u.Alert
+	alert.Fields, alert.Channels, alert.RetryChats = u.Fields, u.Channels, u.RetryChats
+	return alert, nil
+}
+
+// SetRedelivery enables persisting and retrying failed alerts. The
+// worker starts at once, draining any backlog a previous run left.
+func (d *Dispatcher) SetRedelivery(opts RedeliveryOptions) {
+	if opts.Key == "" {
+		opts.Key = DefaultRedeliveryKey
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = redeliveryMinBackoff
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = max(redeliveryMaxBackoff, opts.MinBackoff)
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = redeliveryMaxAge
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &redeliverer{
+		opts:   opts,
+		send:   d.redeliver,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go r.run(ctx)
+	d.mu.Lock()
+	d.redelivery = r
+	d.mu.Unlock()
+}
+
+// redeliver retries a persisted alert through the channels it names,
+// or through every routed channel when it names none. Channels no
+// longer configured are skipped.
+func (d *Dispatcher) redeliver(ctx context.Context, alert Alert) error {
+	if len(alert.Channels) == 0 {
+		return d.send(ctx, alert)
+	}
+	names := alert.Channels
+	alert.Channels = nil
+	alert = d.addressed(d.styled(alert)) // recipients aren't persisted
+	var errs []error
+	for _, name := range names {
+		ch, ok := d.Channel(name)
+		if !ok {
+			continue
+		}
+		if err := ch.Send(ctx, alert); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// persist queues alert for redelivery
+func (r *redeliverer) persist(alert Alert) {
+	data, err := encodeUndelivered(alert)
+	if err != nil {
+		slog.Error("Cannot persist undelivered alert", "target", alert.Target, "alert_id", alert.ID, "err", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	n, err := r.opts.Client.RPush(ctx, r.opts.Key, data).Result()
+	if err != nil {
+		slog.Error("Cannot persist undelivered alert", "target", alert.Target, "alert_id", alert.ID, "err", err)
+		return
+	}
+	UndeliveredBacklog.Set(float64(n))
+	slog.Warn("Alert persisted for redelivery", "target", alert.Target, "alert_id", alert.ID, "backlog", n)
+}
+
+// run retries the backlog, backing off while deliveries keep failing
+func (r *redeliverer) run(ctx context.Context) {
+	defer close(r.done)
+	backoff := r.opts.MinBackoff
+	for {
+		if r.retry(ctx) {
+			backoff = r.opts.MinBackoff
+		} else {
+			backoff = min(backoff*2, r.opts.MaxBackoff)
+		}
+		// Jitter so several instances sharing the list spread out
+		wait := backoff + time.Duration(rand.Int63n(int64(backoff)/5+1))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// retry makes one pass over the backlog, reporting whether every
+// delivery attempted succeeded
+func (r *redeliverer) retry(ctx context.Context) bool {
+	client, key := r.opts.Client, r.opts.Key
+	n, err := client.LLen(ctx, key).Result()
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("Alert redelivery failed", "err", err)
+		}
+		return false
+	}
+	UndeliveredBacklog.Set(float64(n))
+
+	ok := true
+	for i := int64(0); i < n && ctx.Err() == nil; i++ {
+		raw, err := client.LMove(ctx, key, key, "LEFT", "RIGHT").Result()
+		if err != nil {
+			if !errors.Is(err, redis.Nil) && ctx.Err() == nil {
+				slog.Warn("Alert redelivery failed", "err", err)
+				ok = false
+			}
+			break
+		}
+
+		alert, err := decodeUndelivered([]byte(raw))
+		if err != nil {
+			slog.Warn("Dropping unreadable undelivered alert", "err", err)
+			r.remove(ctx, raw)
+			continue
+		}
+		if age := time.Since(alert.Timestamp); !alert.Timestamp.IsZero() && age > r.opts.MaxAge {
+			slog.Warn("Dropping undelivered alert past its max age", "target", alert.Target, "alert_id", alert.ID, "age", age.Round(time.Minute))
+			r.remove(ctx, raw)
+			continue
+		}
+
+		sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		err = r.send(sendCtx, alert)
+		cancel()
+		if err != nil {
+			ok = false
+			continue
+		}
+		slog.Info("Alert redelivered", "target", alert.Target, "alert_id", alert.ID)
+		r.remove(ctx, raw)
+	}
+	return ok
+}
+
+// remove deletes one entry from the list and refreshes the backlog gauge
+func (r *redeliverer) remove(ctx context.Context, raw string) {
+	pipe := r.opts.Client.TxPipeline()
+	pipe.LRem(ctx, r.opts.Key, 1, raw)
+	llen := pipe.LLen(ctx, r.opts.Key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		slog.Warn("Alert redelivery failed", "err", err)
+		return
+	}
+	UndeliveredBacklog.Set(float64(llen.Val()))
+}
+
+// stop ends the worker; the backlog stays in Redis for the next run
+func (r *redeliverer) stop() {
+	r.cancel()
+	<-r.done
+}
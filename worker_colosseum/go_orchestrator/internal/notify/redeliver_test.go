@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// A redelivered alert keeps the target's field selection and the
+// channels it is limited to
+func TestUndeliveredRoundTrip(t *testing.T) {
+	alert := testAlert()
+	alert.Fields = AlertFields{"target": true, "price": true}
+	alert.Channels = []string{"telegram"}
+
+	data, err := encodeUndelivered(alert)
+	if err != nil {
+		t.Fatalf("encodeUndelivered: %v", err)
+	}
+	got, err := decodeUndelivered(data)
+	if err != nil {
+		t.Fatalf("decodeUndelivered: %v", err)
+	}
+	if len(got.Fields) != 2 || !got.Fields["target"] || !got.Fields["price"] {
+		t.Errorf("Fields = %v, want target and price", got.Fields)
+	}
+	if len(got.Channels) != 1 || got.Channels[0] != "telegram" {
+		t.Errorf("Channels = %v, want [telegram]", got.Channels)
+	}
+	if got.Target != alert.Target || got.Message != alert.Message || !got.Timestamp.Equal(alert.Timestamp) {
+		t.Errorf("decoded %+v, want %+v", got, alert)
+	}
+
+	d := NewDispatcher(nil, 0, 0, "")
+	telegram := &fakeChannel{name: "telegram"}
+	webhook := &fakeChannel{name: "webhook"}
+	d.Register(telegram)
+	d.Register(webhook)
+	if err := d.redeliver(context.Background(), got); err != nil {
+		t.Fatalf("redeliver: %v", err)
+	}
+	if len(webhook.sent) != 0 {
+		t.Errorf("webhook got %d sends, want none", len(webhook.sent))
+	}
+	if len(telegram.sent) != 1 || len(telegram.sent[0].Fields) != 2 {
+		t.Errorf("telegram sends = %v, want one with the persisted fields", telegram.sent)
+	}
+}
+
+// Records persisted before Fields was kept still decode
+func TestDecodeUndeliveredWithoutFields(t *testing.T) {
+	got, err := decodeUndelivered([]byte(`{"level":1,"target":"colosseo","channels":["webhook"]}`))
+	if err != nil {
+		t.Fatalf("decodeUndelivered: %v", err)
+	}
+	if got.Fields != nil {
+		t.Errorf("Fields = %v, want nil for the level default", got.Fields)
+	}
+	if len(got.Channels) != 1 || got.Channels[0] != "webhook" {
+		t.Errorf("Channels = %v, want [webhook]", got.Channels)
+	}
+}
+
+// Delivery details stay out of what external consumers receive
+func TestWebhookPayloadOmitsDeliveryDetails(t *testing.T) {
+	alert := testAlert()
+	alert.Fields = AlertFields{"target": true}
+	alert.Channels = []string{"webhook"}
+
+	data, err := webhookPayload(alert, WebhookOptions{})
+	if err != nil {
+		t.Fatalf("webhookPayload: %v", err)
+	}
+	for _, key := range []string{`"channels"`, `"fields"`} {
+		if strings.Contains(string(data), key) {
+			t.Errorf("payload %s contains %s", data, key)
+		}
+	}
+}
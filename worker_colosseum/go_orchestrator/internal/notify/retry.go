@@ -0,0 +1,82 @@
+// internal/notify/retry.go - Webhook retries with exponential backoff
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy shapes webhook delivery retries: after a network error or
+// a 5xx the request is sent again, Backoff later, doubling per attempt
+// and spread by +/- 20%. 4xx responses are never retried. Zero fields
+// use DefaultWebhookRetry.
+type RetryPolicy struct {
+	Attempts       int           // total sends, first included (default 3)
+	AttemptTimeout time.Duration // bound on each send (default 5s)
+	Backoff        time.Duration // delay before the first retry (default 500ms)
+}
+
+// DefaultWebhookRetry is the webhook retry policy until options set one
+var DefaultWebhookRetry = RetryPolicy{
+	Attempts:       3,
+	AttemptTimeout: 5 * time.Second,
+	Backoff:        500 * time.Millisecond,
+}
+
+// retryJitter is the random +/- fraction applied to each backoff
+const retryJitter = 0.2
+
+// withDefaults fills unset fields from DefaultWebhookRetry
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.Attempts <= 0 {
+		p.Attempts = DefaultWebhookRetry.Attempts
+	}
+	if p.AttemptTimeout <= 0 {
+		p.AttemptTimeout = DefaultWebhookRetry.AttemptTimeout
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = DefaultWebhookRetry.Backoff
+	}
+	return p
+}
+
+// permanentError marks a failure that retrying won't fix
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+
+func (e permanentError) Unwrap() error { return e.err }
+
+// retry runs send until it succeeds, fails permanently or the attempts
+// run out. Each attempt gets its own AttemptTimeout; ctx bounds the
+// whole, so no retry is started that its deadline would cut short.
+func (p RetryPolicy) retry(ctx context.Context, send func(context.Context) error) error {
+	p = p.withDefaults()
+	backoff := p.Backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, p.AttemptTimeout)
+		err = send(attemptCtx)
+		cancel()
+
+		var permanent permanentError
+		if err == nil || errors.As(err, &permanent) || ctx.Err() != nil {
+			return err
+		}
+		if attempt >= p.Attempts {
+			return fmt.Errorf("after %d attempts: %w", attempt, err)
+		}
+
+		wait := time.Duration(float64(backoff) * (1 + retryJitter*(2*rand.Float64()-1)))
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return fmt.Errorf("after %d attempts, no time left to retry: %w", attempt, err)
+		}
+		if err := sleepCtx(ctx, wait); err != nil {
+			return err
+		}
+		backoff *= 2
+	}
+}
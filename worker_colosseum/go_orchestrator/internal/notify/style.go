@@ -0,0 +1,65 @@
+// internal/notify/style.go - Configurable severity decoration
+package notify
+
+// LevelStyle decorates an alert's severity in every channel's output
+type LevelStyle struct {
+	Emoji string `json:"emoji,omitempty"`
+	Label string `json:"label"`
+}
+
+// defaultLevelStyles match the original hardcoded wording
+var defaultLevelStyles = map[AlertLevel]LevelStyle{
+	Critical: {Emoji: "🚨", Label: "CRITICAL"},
+	Warning:  {Emoji: "⚠️", Label: "WARNING"},
+	Info:     {Emoji: "ℹ️", Label: "Info"},
+}
+
+// Prefix joins the emoji and label, e.g. "🚨 CRITICAL" or "[CRITICAL]"
+func (s LevelStyle) Prefix() string {
+	if s.Emoji == "" {
+		return s.Label
+	}
+	return s.Emoji + " " + s.Label
+}
+
+// SetLevelStyles replaces the decoration of the given levels. A style
+// without a label keeps the level's default label; levels left out keep
+// their default style.
+func (d *Dispatcher) SetLevelStyles(styles map[AlertLevel]LevelStyle) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.styles = make(map[AlertLevel]LevelStyle, len(styles))
+	for level, s := range styles {
+		if s.Label == "" {
+			s.Label = defaultLevelStyles[level].Label
+		}
+		d.styles[level] = s
+	}
+}
+
+// levelStyle returns the configured or default style for level
+func (d *Dispatcher) levelStyle(level AlertLevel) LevelStyle {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if s, ok := d.styles[level]; ok {
+		return s
+	}
+	return defaultLevelStyles[level]
+}
+
+// styled stamps the alert with its level's style, unless already set
+func (d *Dispatcher) styled(alert Alert) Alert {
+	if alert.Style == nil {
+		s := d.levelStyle(alert.Level)
+		alert.Style = &s
+	}
+	return alert
+}
+
+// alertStyle returns the style stamped on alert, or the level's default
+func alertStyle(alert Alert) LevelStyle {
+	if alert.Style != nil {
+		return *alert.Style
+	}
+	return defaultLevelStyles[alert.Level]
+}
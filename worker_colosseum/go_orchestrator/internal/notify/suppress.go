@@ -0,0 +1,57 @@
+// internal/notify/suppress.go - Post-acquisition availability suppression
+package notify
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Suppress mutes availability alerts for target for d, e.g. after a
+// ticket was acquired. Other alerts still go out, and a sold-out alert
+// for the target lifts the suppression early.
+func (d *Dispatcher) Suppress(target string, dur time.Duration) time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.suppressed == nil {
+		d.suppressed = make(map[string]time.Time)
+	}
+	until := time.Now().Add(dur)
+	d.suppressed[target] = until
+	return until
+}
+
+// Unsuppress lifts target's suppression, reporting whether one was active
+func (d *Dispatcher) Unsuppress(target string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	until, ok := d.suppressed[target]
+	delete(d.suppressed, target)
+	return ok && time.Now().Before(until)
+}
+
+// SuppressedUntil returns when target's suppression ends, if one is active
+func (d *Dispatcher) SuppressedUntil(target string) (time.Time, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	until, ok := d.suppressed[target]
+	if !ok || !time.Now().Before(until) {
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+// suppress reports whether the alert should be dropped, lifting the
+// target's suppression when it reports sold out
+func (d *Dispatcher) suppress(alert Alert) bool {
+	switch alert.Availability {
+	case SoldOut:
+		if d.Unsuppress(alert.Target) {
+			slog.Info("Sold out, lifting post-acquisition suppression", "target", alert.Target)
+		}
+	case Available:
+		if _, ok := d.SuppressedUntil(alert.Target); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+// internal/notify/throttle.go - Telegram send pacing and flood-control retries
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// DefaultTelegramRate is Telegram's sustained per-chat message limit
+const DefaultTelegramRate = 1.0 // messages per second
+
+// Telegram pacing
+const (
+	telegramBurst      = 3 // messages sent back to back before pacing applies
+	telegramMaxRetries = 3 // resends after a 429
+)
+
+// tokenBucket paces sends to rate per second, allowing burst at once
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+// wait blocks until a token is available or ctx is done
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		if !b.last.IsZero() {
+			b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		}
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		if err := sleepCtx(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// sendPaced sends c within the chat's pace, resending after Telegram's
+// flood control (429) once its retry_after has passed. It gives up when
+// the wait would outlast ctx, so the alert can fall back elsewhere.
+func (t *telegramChannel) sendPaced(ctx context.Context, c tgbotapi.Chattable) error {
+	for attempt := 0; ; attempt++ {
+		if err := t.pace.wait(ctx); err != nil {
+			return err
+		}
+		_, err := t.bot.Send(c)
+
+		var tgErr *tgbotapi.Error
+		if !errors.As(err, &tgErr) || tgErr.RetryAfter <= 0 {
+			return err
+		}
+		if attempt >= telegramMaxRetries {
+			return fmt.Errorf("still flood-limited after %d retries: %w", telegramMaxRetries, err)
+		}
+		wait := time.Duration(tgErr.RetryAfter) * time.Second
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return fmt.Errorf("flood-limited for %v, past the send deadline: %w", wait, err)
+		}
+		slog.Warn("Telegram flood control, retrying", "wait", wait)
+		if err := sleepCtx(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// sleepCtx waits for d or until ctx is done
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
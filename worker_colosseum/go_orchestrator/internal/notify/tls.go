@@ -0,0 +1,117 @@
+// internal/notify/tls.go - TLS settings for outbound notification clients
+package notify
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// TLSOptions configures the TLS of the webhook and Kafka senders, for
+// receivers with compliance requirements. The zero value is TLS 1.2+
+// with Go's default cipher suites.
+type TLSOptions struct {
+	// MinVersion is "1.2" (default) or "1.3"
+	MinVersion string
+	// CipherSuites restricts TLS 1.2 suites by IANA name, e.g.
+	// "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"; TLS 1.3 suites are fixed.
+	// Only suites Go considers secure are accepted.
+	CipherSuites []string
+	// CertFile and KeyFile present a client certificate for mTLS
+	CertFile string
+	KeyFile  string
+	// CAFile replaces the system roots for verifying receivers
+	CAFile string
+}
+
+// Config builds and validates the tls.Config described by o
+func (o TLSOptions) Config() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	switch o.MinVersion {
+	case "", "1.2":
+	case "1.3":
+		cfg.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("tls: unsupported min version %q (want 1.2 or 1.3)", o.MinVersion)
+	}
+
+	if len(o.CipherSuites) > 0 {
+		secure := make(map[string]uint16)
+		for _, s := range tls.CipherSuites() {
+			secure[s.Name] = s.ID
+		}
+		for _, name := range o.CipherSuites {
+			id, ok := secure[strings.TrimSpace(name)]
+			if !ok {
+				return nil, fmt.Errorf("tls: unknown or insecure cipher suite %q", name)
+			}
+			cfg.CipherSuites = append(cfg.CipherSuites, id)
+		}
+	}
+
+	if (o.CertFile == "") != (o.KeyFile == "") {
+		return nil, fmt.Errorf("tls: cert_file and key_file must be set together")
+	}
+	if o.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls: no certificates in CA file %s", o.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// SetTLS applies the TLS settings to the webhook and Discord channels
+// and to the Kafka channel when it is enabled with TLS afterwards
+func (d *Dispatcher) SetTLS(opts TLSOptions) error {
+	cfg, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	d.tls = cfg
+	d.mu.Unlock()
+
+	if ch, ok := d.Channel("webhook"); ok {
+		ch.(*webhookChannel).client = newHTTPClient(cfg)
+	}
+	if ch, ok := d.Channel("discord"); ok {
+		ch.(*discordChannel).client = newHTTPClient(cfg)
+	}
+	return nil
+}
+
+// tlsConfig returns a copy of the configured TLS settings, or the
+// secure default
+func (d *Dispatcher) tlsConfig() *tls.Config {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.tls == nil {
+		return &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return d.tls.Clone()
+}
+
+// newHTTPClient returns a client using cfg for TLS
+func newHTTPClient(cfg *tls.Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = cfg
+	return &http.Client{Transport: transport, Timeout: 30 * time.Second}
+}
@@ -0,0 +1,41 @@
+// internal/notify/undeliverable.go - Surfacing alerts no channel receives
+package notify
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Undeliverable counts alerts that had no channel to go to. The caller
+// registers it alongside its own metrics.
+var Undeliverable = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "colosseo_alerts_undeliverable_total",
+	Help: "Alerts dispatched with no notification channel to receive them",
+}, []string{"level"})
+
+// nowhere reports whether an alert that no route received was lost.
+// Info alerts going nowhere is normal (Telegram skips them) unless no
+// channel is registered at all.
+func (d *Dispatcher) nowhere(alert Alert) bool {
+	return alert.Level > Info || len(d.snapshot()) == 0
+}
+
+// undeliverable logs alert in full at error level, so the event survives
+// in the logs, and counts it
+func (d *Dispatcher) undeliverable(alert Alert) error {
+	Undeliverable.WithLabelValues(alert.Level.String()).Inc()
+	d.record(alert, AuditUndeliverable, nil, nil)
+
+	detail := alert.Message
+	if detail == "" {
+		detail = fmt.Sprintf("%s (confidence %.0f%%)", alert.Availability, alert.Confidence*100)
+	}
+	if alert.Link != "" {
+		detail += " " + alert.Link
+	}
+	slog.Error("Undeliverable alert, no notification channel", "level", alert.Level.String(),
+		"alert_id", alert.ID, "target", alert.Target, "detail", detail)
+	return fmt.Errorf("no notification channel for %s alerts", alert.Level)
+}
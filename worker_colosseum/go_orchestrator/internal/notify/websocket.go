@@ -0,0 +1,215 @@
+// internal/notify/websocket.go - Dashboard WebSocket channel with reconnection
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket reconnection and write bounds
+const (
+	wsMinBackoff   = time.Second
+	wsMaxBackoff   = time.Minute
+	wsWriteTimeout = 10 * time.Second
+)
+
+// errWebSocketClosed is reported by sends after Close
+var errWebSocketClosed = errors.New("websocket closed")
+
+// SetWebSocket sets the WebSocket connection for real-time updates. The
+// connection is not redialled once it drops; see SetWebSocketURL.
+func (d *Dispatcher) SetWebSocket(ws *websocket.Conn) {
+	ch := newWebSocketChannel("", nil)
+	ch.attach(ws)
+	go func() {
+		ch.detach(ws, readUntilClosed(ws))
+	}()
+	d.replaceWebSocket(ch)
+}
+
+// SetWebSocketURL streams alerts to the dashboard at url, dialling in
+// the background and redialling with capped exponential backoff
+// whenever the connection drops. Sends while it is down fail, so the
+// alert takes the fallback path.
+func (d *Dispatcher) SetWebSocketURL(url string) {
+	ch := newWebSocketChannel(url, d.tlsConfig())
+	go ch.maintain()
+	d.replaceWebSocket(ch)
+}
+
+// replaceWebSocket registers ch, closing the channel it replaces
+func (d *Dispatcher) replaceWebSocket(ch *websocketChannel) {
+	old, ok := d.Channel(ch.Name())
+	d.Register(ch)
+	if ok {
+		old.(*websocketChannel).Close()
+	}
+}
+
+// websocketChannel streams every alert to the real-time dashboard.
+// gorilla/websocket allows one writer at a time and Dispatch runs on
+// every monitor goroutine, so writes are serialized by mu.
+type websocketChannel struct {
+	url    string // empty: a fixed connection that isn't redialled
+	dialer websocket.Dialer
+
+	ctx    context.Context // cancelled by Close
+	cancel context.CancelFunc
+
+	mu   sync.Mutex
+	conn *websocket.Conn // nil while down
+	down error           // why conn is nil
+}
+
+func newWebSocketChannel(url string, tlsCfg *tls.Config) *websocketChannel {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &websocketChannel{
+		url: url,
+		dialer: websocket.Dialer{
+			HandshakeTimeout: wsWriteTimeout,
+			TLSClientConfig:  tlsCfg,
+		},
+		ctx:    ctx,
+		cancel: cancel,
+		down:   errors.New("websocket not connected"),
+	}
+}
+
+func (w *websocketChannel) Name() string { return "websocket" }
+
+func (w *websocketChannel) Accepts(AlertLevel) bool { return true }
+
+// Send sends alert via WebSocket. A failed write drops the connection,
+// which the background loop then redials.
+func (w *websocketChannel) Send(ctx context.Context, alert Alert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return w.down
+	}
+
+	deadline := time.Now().Add(wsWriteTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	w.conn.SetWriteDeadline(deadline)
+	if err := w.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		w.conn.Close()
+		w.conn = nil
+		w.down = fmt.Errorf("websocket down: %w", err)
+		return err
+	}
+	return nil
+}
+
+// Close stops reconnecting and closes the connection
+func (w *websocketChannel) Close() error {
+	w.cancel()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.down = errWebSocketClosed
+	if w.conn == nil {
+		return nil
+	}
+	w.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(time.Second))
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// maintain keeps the connection up until Close. Redials back off after
+// failed dials and after drops alike, so an endpoint that accepts and
+// immediately hangs up isn't hammered; a connection that held for
+// wsMaxBackoff resets the backoff.
+func (w *websocketChannel) maintain() {
+	backoff := wsMinBackoff
+	for {
+		conn, _, err := w.dialer.DialContext(w.ctx, w.url, nil)
+		if err == nil {
+			if !w.attach(conn) {
+				conn.Close()
+				return
+			}
+			slog.Info("WebSocket connected", "url", w.url)
+			connected := time.Now()
+
+			err = readUntilClosed(conn)
+			w.detach(conn, err)
+			if time.Since(connected) >= wsMaxBackoff {
+				backoff = wsMinBackoff
+			}
+		} else {
+			w.setDown(fmt.Errorf("websocket down: %w", err))
+		}
+		if w.ctx.Err() != nil {
+			return
+		}
+
+		wait := time.Duration(float64(backoff) * (0.8 + 0.4*rand.Float64()))
+		slog.Warn("WebSocket down, reconnecting", "url", w.url, "err", err, "backoff", wait.Round(time.Millisecond))
+		if sleepCtx(w.ctx, wait) != nil {
+			return
+		}
+		backoff = min(backoff*2, wsMaxBackoff)
+	}
+}
+
+// attach makes conn the live connection, unless the channel is closed
+func (w *websocketChannel) attach(conn *websocket.Conn) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ctx.Err() != nil {
+		return false
+	}
+	w.conn, w.down = conn, nil
+	return true
+}
+
+// detach marks conn dropped, if it is still the live connection
+func (w *websocketChannel) detach(conn *websocket.Conn, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != conn {
+		return
+	}
+	conn.Close()
+	w.conn = nil
+	if w.ctx.Err() == nil {
+		w.down = fmt.Errorf("websocket down: %w", err)
+	}
+}
+
+func (w *websocketChannel) setDown(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ctx.Err() == nil {
+		w.down = err
+	}
+}
+
+// readUntilClosed discards incoming messages, which keeps ping, pong
+// and close frames flowing, and returns once the connection fails
+func readUntilClosed(conn *websocket.Conn) error {
+	for {
+		if _, _, err := conn.NextReader(); err != nil {
+			return err
+		}
+	}
+}
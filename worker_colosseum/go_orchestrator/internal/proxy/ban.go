@@ -0,0 +1,92 @@
+// internal/proxy/ban.go - Capped exponential ban durations with jitter
+package proxy
+
+import (
+	"math/rand"
+	"time"
+)
+
+// banThreshold is how many consecutive errors a proxy survives before
+// its first ban
+const banThreshold = 5
+
+// probationScore is the health a proxy returns with when its ban ends:
+// above the selection cutoff, so it is tried again, but below a proxy in
+// good standing until successes earn the rest back
+const probationScore = 0.5
+
+// BanPolicy shapes the ban applied after each error past the threshold:
+// Base, multiplied by Multiplier for every further error, capped at Max,
+// then spread by +/- Jitter so proxies that failed together don't all
+// return at the same instant
+type BanPolicy struct {
+	Base       time.Duration // first ban (default 1m)
+	Multiplier float64       // growth per further error (default 2)
+	Max        time.Duration // cap before jitter (default 30m)
+	Jitter     float64       // random +/- fraction, 0-1 (default 0.2)
+}
+
+// DefaultBanPolicy is used until SetBanPolicy is called
+var DefaultBanPolicy = BanPolicy{
+	Base:       time.Minute,
+	Multiplier: 2,
+	Max:        30 * time.Minute,
+	Jitter:     0.2,
+}
+
+// SetBanPolicy replaces the ban policy. Unset fields keep their defaults.
+func (m *Manager) SetBanPolicy(p BanPolicy) {
+	if p.Base <= 0 {
+		p.Base = DefaultBanPolicy.Base
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = DefaultBanPolicy.Multiplier
+	}
+	if p.Max <= 0 {
+		p.Max = DefaultBanPolicy.Max
+	}
+	if p.Jitter < 0 || p.Jitter >= 1 {
+		p.Jitter = DefaultBanPolicy.Jitter
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.banPolicy = p
+}
+
+// Duration returns the ban for a proxy with the given consecutive
+// errors, or 0 while it is under the threshold. Growth stops at Max, so
+// arbitrarily high error counts cannot overflow.
+func (p BanPolicy) Duration(consecutiveErrors int) time.Duration {
+	if consecutiveErrors <= banThreshold {
+		return 0
+	}
+
+	ban := float64(p.Base)
+	for i := banThreshold + 1; i < consecutiveErrors && ban < float64(p.Max); i++ {
+		ban *= p.Multiplier
+	}
+	if ban > float64(p.Max) {
+		ban = float64(p.Max)
+	}
+	if p.Jitter > 0 {
+		ban *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(ban)
+}
+
+// releaseExpiredBans puts proxies whose ban has ended on probation: the
+// errors that banned them are forgiven and their health, by then worn
+// down below the selection cutoff, is raised to probationScore. Without
+// this an expired ban would still leave the proxy unselectable. Callers
+// hold m.mu for writing.
+func (m *Manager) releaseExpiredBans(now time.Time) {
+	for _, p := range m.proxies {
+		if p.BannedUntil.IsZero() || p.BannedUntil.After(now) {
+			continue
+		}
+		p.BannedUntil = time.Time{}
+		p.ConsecutiveErrors = 0
+		p.HealthScore = max(p.HealthScore, probationScore)
+	}
+}
@@ -0,0 +1,105 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpiredBanReturnsOnProbation(t *testing.T) {
+	m := testManager(t, "http://10.0.0.1:8080", "http://10.0.0.2:8080")
+	banned := m.proxies[0]
+	for i := 0; i <= banThreshold; i++ {
+		m.ReportResult(banned.key, false, 0)
+	}
+	if banned.BannedUntil.IsZero() {
+		t.Fatalf("no ban after %d consecutive errors", banThreshold+1)
+	}
+	if banned.HealthScore >= 0.3 {
+		t.Fatalf("HealthScore = %v, want it below the 0.3 selection cutoff", banned.HealthScore)
+	}
+	if got := proxyURLs(m.healthyCandidates()); got[banned.URL.String()] {
+		t.Fatal("banned proxy is a candidate")
+	}
+
+	// A fake clock just past the ban
+	m.mu.Lock()
+	m.releaseExpiredBans(banned.BannedUntil.Add(time.Second))
+	m.mu.Unlock()
+
+	if !banned.BannedUntil.IsZero() {
+		t.Errorf("BannedUntil = %v, want cleared", banned.BannedUntil)
+	}
+	if banned.ConsecutiveErrors != 0 {
+		t.Errorf("ConsecutiveErrors = %d, want 0", banned.ConsecutiveErrors)
+	}
+	if banned.HealthScore != probationScore {
+		t.Errorf("HealthScore = %v, want probation score %v", banned.HealthScore, probationScore)
+	}
+	if got := proxyURLs(m.healthyCandidates()); !got[banned.URL.String()] {
+		t.Error("proxy is not a candidate after its ban expired")
+	}
+}
+
+func TestUnexpiredBanIsKept(t *testing.T) {
+	m := testManager(t, "http://10.0.0.1:8080")
+	p := m.proxies[0]
+	for i := 0; i <= banThreshold; i++ {
+		m.ReportResult(p.key, false, 0)
+	}
+	until := p.BannedUntil
+
+	m.mu.Lock()
+	m.releaseExpiredBans(until.Add(-time.Second))
+	m.mu.Unlock()
+
+	if !p.BannedUntil.Equal(until) || p.ConsecutiveErrors != banThreshold+1 {
+		t.Errorf("ban = %v with %d errors, want it untouched until %v", p.BannedUntil, p.ConsecutiveErrors, until)
+	}
+}
+
+func proxyURLs(proxies []*Proxy) map[string]bool {
+	urls := make(map[string]bool, len(proxies))
+	for _, p := range proxies {
+		urls[p.URL.String()] = true
+	}
+	return urls
+}
+
+func TestBanDuration(t *testing.T) {
+	p := BanPolicy{Base: time.Minute, Multiplier: 2, Max: 30 * time.Minute}
+	tests := []struct {
+		errors int
+		want   time.Duration
+	}{
+		{0, 0},
+		{banThreshold, 0},
+		{banThreshold + 1, time.Minute},
+		{banThreshold + 2, 2 * time.Minute},
+		{banThreshold + 5, 16 * time.Minute},
+		{banThreshold + 6, 30 * time.Minute}, // 32m, capped
+		{60, 30 * time.Minute},
+		{1 << 20, 30 * time.Minute},
+		{int(^uint(0) >> 1), 30 * time.Minute}, // max int
+	}
+	for _, tt := range tests {
+		if got := p.Duration(tt.errors); got != tt.want {
+			t.Errorf("Duration(%d) = %v, want %v", tt.errors, got, tt.want)
+		}
+	}
+}
+
+func TestBanDurationJitterAtCap(t *testing.T) {
+	p := BanPolicy{Base: time.Minute, Multiplier: 2, Max: 30 * time.Minute, Jitter: 0.2}
+	lo, hi := 24*time.Minute, 36*time.Minute
+	distinct := make(map[time.Duration]bool)
+	for i := 0; i < 1000; i++ {
+		got := p.Duration(1 << 20)
+		if got < lo || got > hi {
+			t.Fatalf("Duration = %v, want within %v-%v", got, lo, hi)
+		}
+		distinct[got] = true
+	}
+	if len(distinct) < 2 {
+		t.Error("jitter did not spread ban durations at the cap")
+	}
+}
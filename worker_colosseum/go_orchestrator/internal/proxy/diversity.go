@@ -0,0 +1,90 @@
+// internal/proxy/diversity.go - Healthy pool diversity tracking
+package proxy
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Diversity counts the distinct networks and countries among healthy
+// proxies. Proxies whose ASN or country is unknown don't add to it.
+type Diversity struct {
+	Healthy int `json:"healthy"`
+	ASNs    int `json:"asns"`
+	Geos    int `json:"geos"`
+}
+
+// DiversityOptions sets the floor below which the pool is one correlated
+// ban away from an outage (0 disables a check)
+type DiversityOptions struct {
+	MinASNs int
+	MinGeos int
+	// OnLow is called when diversity first drops below a floor, and again
+	// only after it has recovered
+	OnLow func(Diversity)
+}
+
+// diversityGauges exports the latest Diversity
+type diversityGauges struct {
+	asns prometheus.Gauge
+	geos prometheus.Gauge
+}
+
+func newDiversityGauges() diversityGauges {
+	return diversityGauges{
+		asns: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "proxy_pool_distinct_asns",
+			Help: "Distinct known ASNs among healthy proxies",
+		}),
+		geos: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "proxy_pool_distinct_geos",
+			Help: "Distinct known countries among healthy proxies",
+		}),
+	}
+}
+
+// SetDiversityAlert enables the low-diversity check run after every
+// health check round
+func (m *Manager) SetDiversityAlert(opts DiversityOptions) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.diversityOpts = opts
+}
+
+// Diversity returns the current healthy pool diversity
+func (m *Manager) Diversity() Diversity {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.diversity()
+}
+
+// diversity computes Diversity over healthy proxies. Callers hold m.mu.
+func (m *Manager) diversity() Diversity {
+	healthy := m.healthyCandidates()
+	asns := make(map[string]bool)
+	geos := make(map[string]bool)
+	for _, p := range healthy {
+		if p.ASN != "" && p.ASN != "Unknown" {
+			asns[p.ASN] = true
+		}
+		if p.Geographic != "" && p.Geographic != "Unknown" {
+			geos[p.Geographic] = true
+		}
+	}
+	return Diversity{Healthy: len(healthy), ASNs: len(asns), Geos: len(geos)}
+}
+
+// checkDiversity updates the gauges and fires OnLow when the pool first
+// drops below a configured floor
+func (m *Manager) checkDiversity() {
+	m.mu.Lock()
+	d := m.diversity()
+	opts := m.diversityOpts
+	low := (opts.MinASNs > 0 && d.ASNs < opts.MinASNs) || (opts.MinGeos > 0 && d.Geos < opts.MinGeos)
+	fire := low && !m.diversityLow
+	m.diversityLow = low
+	m.mu.Unlock()
+
+	m.distinct.asns.Set(float64(d.ASNs))
+	m.distinct.geos.Set(float64(d.Geos))
+	if fire && opts.OnLow != nil {
+		opts.OnLow(d)
+	}
+}
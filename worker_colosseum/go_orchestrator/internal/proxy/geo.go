@@ -0,0 +1,66 @@
+// internal/proxy/geo.go - Region-block tracking per proxy and geography
+package proxy
+
+// GeoBlockFlagThreshold is how many region blocks for a geography flag a
+// proxy as not really exiting there
+const GeoBlockFlagThreshold = 3
+
+// ReportGeoBlock records that the proxy was served a region block while
+// used for geo. Each block halves the proxy's weight for that geography
+// and, once flagged, it loses the geographic bonus and no longer counts
+// as a geo exit.
+func (m *Manager) ReportGeoBlock(id, geo string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p := m.byKey(id)
+	if p == nil {
+		return
+	}
+	if p.geoBlocks == nil {
+		p.geoBlocks = make(map[string]int)
+	}
+	p.geoBlocks[geo]++
+	m.geoBlocks.WithLabelValues(p.URL.Host, geo).Inc()
+}
+
+// HasGeo reports whether a healthy, unflagged proxy in the filter's pools
+// exits from geo
+func (m *Manager) HasGeo(geo string, filter PoolFilter) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, p := range inPools(m.healthyCandidates(), filter.pools()) {
+		if p.Geographic == geo && !p.geoFlagged(geo) {
+			return true
+		}
+	}
+	return false
+}
+
+// GeoOf returns the country the proxy with the given ID exits from, or
+// "" for an unknown proxy
+func (m *Manager) GeoOf(id string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if p := m.byKey(id); p != nil {
+		return p.Geographic
+	}
+	return ""
+}
+
+// geoFlagged reports whether the proxy has been region-blocked too often
+// to count as a geo exit. Callers hold m.mu.
+func (p *Proxy) geoFlagged(geo string) bool {
+	return p.geoBlocks[geo] >= GeoBlockFlagThreshold
+}
+
+// geoPenalty scales a selection weight down for each region block seen
+// for geo. Callers hold m.mu.
+func (p *Proxy) geoPenalty(geo string) float64 {
+	penalty := 1.0
+	for i := 0; i < p.geoBlocks[geo]; i++ {
+		penalty /= 2
+	}
+	return penalty
+}
@@ -0,0 +1,221 @@
+// internal/proxy/geoip.go - Exit-IP country and ASN lookup from MaxMind databases
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+// DefaultGeoIPRefresh is how long a proxy's resolved exit location is
+// trusted before the health-check loop resolves it again
+const DefaultGeoIPRefresh = time.Hour
+
+// geoIP resolves where proxies actually exit: the proxy host is often
+// not the exit address, so the exit IP is asked of an IP echo through
+// the proxy and then looked up in the MaxMind databases. Results are
+// cached per proxy endpoint.
+type geoIP struct {
+	country []*geoip2.Reader // Country, City or Enterprise databases
+	asn     []*geoip2.Reader // ASN or ISP databases
+	echoURL string
+	refresh time.Duration
+
+	mu    sync.Mutex
+	cache map[string]exitLocation // by proxy key
+}
+
+// exitLocation is one resolved exit of a proxy
+type exitLocation struct {
+	IP         string
+	Geographic string // ISO country code, "Unknown" if not in the database
+	ASN        string // "AS<number>", "Unknown" if not in the database
+	Resolved   time.Time
+}
+
+// NewManagerWithGeoIP creates a proxy manager, like a non-strict
+// NewManager, whose proxies' Geographic and ASN come from MaxMind
+// GeoLite2/GeoIP2 databases instead of the proxy hostname. dbPath names
+// one .mmdb file, or several separated by commas, e.g. a Country and an
+// ASN database. Each proxy's exit IP is resolved through the proxy on the
+// first health check and again every DefaultGeoIPRefresh; until then the
+// hostname guess applies.
+func NewManagerWithGeoIP(proxyURLs []string, interval time.Duration, dbPath string) (*Manager, error) {
+	g, err := openGeoIP(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	m, err := newManager(proxyURLs, interval, false)
+	if err != nil {
+		g.close()
+		return nil, err
+	}
+	m.geoIP = g
+
+	go m.healthCheckLoop()
+
+	return m, nil
+}
+
+// openGeoIP opens the comma-separated databases in dbPath, sorting them
+// by what they can answer
+func openGeoIP(dbPath string) (*geoIP, error) {
+	g := &geoIP{
+		echoURL: DefaultIPEchoURL,
+		refresh: DefaultGeoIPRefresh,
+		cache:   make(map[string]exitLocation),
+	}
+	for _, path := range strings.Split(dbPath, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		r, err := geoip2.Open(path)
+		if err != nil {
+			g.close()
+			return nil, fmt.Errorf("open GeoIP database %s: %w", path, err)
+		}
+
+		// Looking up the zero address only fails for unsupported methods
+		var invalid geoip2.InvalidMethodError
+		used := false
+		if _, err := r.Country(net.IPv4zero); !errors.As(err, &invalid) {
+			g.country = append(g.country, r)
+			used = true
+		}
+		if _, err := r.ASN(net.IPv4zero); !errors.As(err, &invalid) {
+			g.asn = append(g.asn, r)
+			used = true
+		}
+		if !used {
+			r.Close()
+			g.close()
+			return nil, fmt.Errorf("GeoIP database %s (%s) has neither countries nor ASNs", path, r.Metadata().DatabaseType)
+		}
+	}
+	if len(g.country) == 0 && len(g.asn) == 0 {
+		return nil, errors.New("no GeoIP database given")
+	}
+	return g, nil
+}
+
+// close releases every database
+func (g *geoIP) close() {
+	closed := make(map[*geoip2.Reader]bool)
+	for _, r := range append(g.country, g.asn...) {
+		if !closed[r] {
+			r.Close()
+			closed[r] = true
+		}
+	}
+}
+
+// cached returns the resolved exit for a proxy key, if any
+func (g *geoIP) cached(key string) (exitLocation, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	loc, ok := g.cache[key]
+	return loc, ok
+}
+
+// forget drops a removed proxy's cached exit
+func (g *geoIP) forget(key string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.cache, key)
+}
+
+// stale reports whether the proxy's exit is due for resolving
+func (g *geoIP) stale(key string, now time.Time) bool {
+	loc, ok := g.cached(key)
+	return !ok || now.Sub(loc.Resolved) >= g.refresh
+}
+
+// resolve asks the IP echo, through p, for its exit IP and looks it up
+func (g *geoIP) resolve(ctx context.Context, p *Proxy) (exitLocation, error) {
+	transport := p.Transport()
+	defer transport.CloseIdleConnections()
+	client := &http.Client{Timeout: probeRequestTimeout, Transport: transport}
+
+	ip, err := exitIP(ctx, client, g.echoURL)
+	if err != nil {
+		return exitLocation{}, err
+	}
+	loc := g.lookup(net.ParseIP(ip))
+	loc.IP = ip
+	loc.Resolved = time.Now()
+
+	g.mu.Lock()
+	g.cache[p.key] = loc
+	g.mu.Unlock()
+	return loc, nil
+}
+
+// lookup finds ip's country and ASN in the first database that has them
+func (g *geoIP) lookup(ip net.IP) exitLocation {
+	loc := exitLocation{Geographic: "Unknown", ASN: "Unknown"}
+	for _, r := range g.country {
+		if rec, err := r.Country(ip); err == nil && rec.Country.IsoCode != "" {
+			loc.Geographic = rec.Country.IsoCode
+			break
+		}
+	}
+	for _, r := range g.asn {
+		if rec, err := r.ASN(ip); err == nil && rec.AutonomousSystemNumber != 0 {
+			loc.ASN = fmt.Sprintf("AS%d", rec.AutonomousSystemNumber)
+			break
+		}
+	}
+	return loc
+}
+
+// refreshGeoIP resolves the exits of proxies never resolved or resolved
+// longer than the refresh period ago. A proxy whose exit can't be
+// resolved keeps its last known location and is retried next round.
+func (m *Manager) refreshGeoIP() {
+	if m.geoIP == nil {
+		return
+	}
+
+	now := time.Now()
+	m.mu.RLock()
+	due := make([]*Proxy, 0, len(m.proxies))
+	for _, p := range m.proxies {
+		if m.geoIP.stale(p.key, now) {
+			due = append(due, p)
+		}
+	}
+	m.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultProbeTimeout)
+	defer cancel()
+
+	sem := make(chan struct{}, DefaultProbeConcurrency)
+	var wg sync.WaitGroup
+	for _, p := range due {
+		wg.Add(1)
+		go func(p *Proxy) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			loc, err := m.geoIP.resolve(ctx, p)
+			if err != nil {
+				slog.Warn("GeoIP: proxy exit not resolved", "proxy", p.URL.Redacted(), "err", err)
+				return
+			}
+			m.mu.Lock()
+			p.Geographic, p.ASN = loc.Geographic, loc.ASN
+			m.mu.Unlock()
+		}(p)
+	}
+	wg.Wait()
+}
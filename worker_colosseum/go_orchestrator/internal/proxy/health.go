@@ -0,0 +1,102 @@
+// internal/proxy/health.go - Health-check endpoints and per-proxy probes
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Health check defaults
+const (
+	DefaultHealthEndpoint    = "https://ticketing.colosseo.it/"
+	DefaultHealthConcurrency = 4
+)
+
+// healthResult is one proxy's outcome in a health-check round
+type healthResult struct {
+	success bool
+	latency time.Duration
+}
+
+// SetHealthEndpoints replaces the URLs health checks request through
+// each proxy. A proxy passes a round if any of them answers 2xx; the
+// endpoint tried first rotates every round.
+func (m *Manager) SetHealthEndpoints(endpoints []string) error {
+	if len(endpoints) == 0 {
+		return nil
+	}
+	for _, e := range endpoints {
+		u, err := url.Parse(e)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("health endpoint %q: must be an http(s) URL", e)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.testEndpoints = append([]string(nil), endpoints...)
+	m.endpointRound = 0
+	return nil
+}
+
+// SetHealthCheckConcurrency caps how many proxies are health-checked at
+// once (default 4)
+func (m *Manager) SetHealthCheckConcurrency(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if n > 0 {
+		m.healthConcurrency = n
+	}
+}
+
+// nextEndpoints returns the endpoints in this round's order and advances
+// the rotation. Callers hold m.mu for writing.
+func (m *Manager) nextEndpoints() []string {
+	n := len(m.testEndpoints)
+	order := make([]string, n)
+	for i := range order {
+		order[i] = m.testEndpoints[(m.endpointRound+i)%n]
+	}
+	m.endpointRound = (m.endpointRound + 1) % n
+	return order
+}
+
+// checkProxy requests the endpoints through the proxy in order until one
+// answers 2xx. Chained proxies are probed through every hop, so the
+// result reflects the whole path.
+func checkProxy(p *Proxy, endpoints []string) healthResult {
+	transport := p.Transport()
+	defer transport.CloseIdleConnections()
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: transport,
+	}
+
+	start := time.Now()
+	for _, endpoint := range endpoints {
+		attempt := time.Now()
+		resp, err := client.Get(endpoint)
+		if err != nil {
+			continue
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+		resp.Body.Close()
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return healthResult{success: true, latency: time.Since(attempt)}
+		}
+	}
+	return healthResult{latency: time.Since(start)}
+}
+
+// allFailed reports whether no proxy passed the round
+func allFailed(results []healthResult) bool {
+	for _, r := range results {
+		if r.success {
+			return false
+		}
+	}
+	return true
+}
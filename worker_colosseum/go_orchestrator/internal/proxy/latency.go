@@ -0,0 +1,48 @@
+// internal/proxy/latency.go - Latency tracking and latency-weighted selection
+package proxy
+
+import "time"
+
+// latencyAlpha is the weight of each new sample in a proxy's latency
+// EWMA; 0.3 follows a shift within a handful of requests
+const latencyAlpha = 0.3
+
+// observeLatency folds a successful request's latency into the EWMA.
+// Callers hold m.mu.
+func (p *Proxy) observeLatency(latency time.Duration) {
+	if latency <= 0 {
+		return
+	}
+	if p.Latency == 0 {
+		p.Latency = latency
+		return
+	}
+	p.Latency = time.Duration(latencyAlpha*float64(latency) + (1-latencyAlpha)*float64(p.Latency))
+}
+
+// pickLatencyWeighted selects like pickWeighted, dividing each weight by
+// the proxy's latency relative to the candidates' mean, so a proxy twice
+// as slow as average is half as likely to win. Proxies with no latency
+// yet count as average. Callers hold m.mu.
+func (m *Manager) pickLatencyWeighted(candidates []*Proxy, preferredGeo string) *Proxy {
+	var sum time.Duration
+	var known int
+	for _, p := range candidates {
+		if p.Latency > 0 {
+			sum += p.Latency
+			known++
+		}
+	}
+
+	weights := make([]float64, len(candidates))
+	var totalWeight float64
+	for i, p := range candidates {
+		weights[i] = m.selectionWeight(p, preferredGeo)
+		if known > 0 && p.Latency > 0 {
+			mean := float64(sum) / float64(known)
+			weights[i] /= float64(p.Latency) / mean
+		}
+		totalWeight += weights[i]
+	}
+	return weightedChoice(candidates, weights, totalWeight)
+}
@@ -0,0 +1,79 @@
+// internal/proxy/limit.go - Per-proxy concurrent connection cap
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SetMaxConnsPerProxy caps how many acquired connections a single proxy
+// may carry at once (0 = unlimited)
+func (m *Manager) SetMaxConnsPerProxy(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if n >= 0 {
+		m.maxConnsPerProxy = n
+	}
+}
+
+// Acquire reserves a connection slot on a healthy proxy in the filter's
+// pools, preferring preferredGeo. Proxies at their cap are skipped; if
+// every candidate is full it blocks until a slot is released or ctx is
+// done. It returns the proxy's ID, which names it to Release,
+// ReportResult and RoundTripper; every successful Acquire must be paired
+// with a Release.
+func (m *Manager) Acquire(ctx context.Context, preferredGeo string, filter PoolFilter) (string, error) {
+	for {
+		m.mu.Lock()
+		m.releaseExpiredBans(time.Now())
+		// Least-bad proxies per the fallback policy when none are healthy
+		candidates, _ := m.poolCandidates(filter)
+		if len(candidates) == 0 {
+			m.mu.Unlock()
+			return "", fmt.Errorf("no proxy available in pools %v", filter.pools())
+		}
+		if p := m.pick(m.withCapacity(candidates), preferredGeo, filter); p != nil {
+			p.inUse++
+			p.LastUsed = time.Now()
+			m.mu.Unlock()
+			return p.key, nil
+		}
+		released := m.released
+		m.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// Release returns a connection slot taken by Acquire
+func (m *Manager) Release(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if p := m.byKey(id); p != nil && p.inUse > 0 {
+		p.inUse--
+	}
+
+	// Wake every waiter to retry selection
+	close(m.released)
+	m.released = make(chan struct{})
+}
+
+// withCapacity filters out proxies at their connection cap. Callers hold m.mu.
+func (m *Manager) withCapacity(candidates []*Proxy) []*Proxy {
+	if m.maxConnsPerProxy <= 0 {
+		return candidates
+	}
+	result := make([]*Proxy, 0, len(candidates))
+	for _, p := range candidates {
+		if p.inUse < m.maxConnsPerProxy {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -2,11 +2,14 @@
 package proxy
 
 import (
-	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,12 +20,20 @@ import (
 type Proxy struct {
 	URL               *url.URL
 	HealthScore       float64 // 0-1, based on success rate
-	LastUsed          time.Time
+	LastUsed          time.Time // written on selection, so under Manager.mu's write lock
 	LastError         error
 	ConsecutiveErrors int
 	BannedUntil       time.Time
 	Geographic        string // "IT", "DE", "FR", etc.
 	ASN               string // ISP identifier
+	Latency           time.Duration // EWMA of successful request latency, 0 until one
+	Chain             []*url.URL     // hops tunneled through before URL, in order
+	Pools             []string       // pool labels; unlabeled proxies are in DefaultPool
+	inUse             int            // connections currently acquired
+	liveMu            sync.Mutex
+	live              *http.Transport // shared by live requests outside a warm pool
+	geoBlocks         map[string]int // region blocks seen, by geography
+	key               string         // normalized chain and exit: the proxy's ID
 }
 
 // Manager handles proxy pool with health checking
@@ -31,11 +42,55 @@ type Manager struct {
 	mu                  sync.RWMutex
 	healthCheckInterval time.Duration
 	metrics             *prometheus.CounterVec
-	testEndpoint        string
+	testEndpoints       []string // probed by health checks, rotating per round
+	endpointRound       int
+	healthConcurrency   int // proxies health-checked at once
+	rejected            []string
+	strict              bool
+	geoBonus            float64 // weight multiplier for the preferred geography
+	weightJitter        float64 // random +/- fraction applied to each weight
+	maxConnsPerProxy    int           // 0 = unlimited
+	released            chan struct{} // closed and replaced on every Release
+	warm                *warmPool     // nil unless SetWarmPool enabled it
+	warmStart           sync.Once
+	connReuse           *prometheus.CounterVec
+	geoBlocks           *prometheus.CounterVec
+	poolChanges         *prometheus.CounterVec
+	banPolicy           BanPolicy
+	rotation            RotationPolicy            // for pools without their own
+	poolPolicies        map[string]RotationPolicy // per-pool overrides
+	affinity            map[string]*Proxy         // sticky session -> proxy
+	lastPicked          map[string]*Proxy         // round-robin pools -> last pick
+	diversityOpts       DiversityOptions
+	diversityLow        bool
+	distinct            diversityGauges
+	geoIP               *geoIP // nil unless built by NewManagerWithGeoIP
 }
 
-// NewManager creates a new proxy manager
-func NewManager(proxyURLs []string, checkInterval time.Duration) (*Manager, error) {
+// Default selection weighting
+const (
+	DefaultGeoBonus     = 2.0
+	DefaultWeightJitter = 0.0
+)
+
+// NewManager creates a new proxy manager. Malformed proxy URLs are
+// skipped with a warning and reported by Rejected, unless strict is set,
+// in which case the first one aborts construction. Either way it fails
+// if no valid proxy remains.
+func NewManager(proxyURLs []string, checkInterval time.Duration, strict bool) (*Manager, error) {
+	m, err := newManager(proxyURLs, checkInterval, strict)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start health check loop
+	go m.healthCheckLoop()
+
+	return m, nil
+}
+
+// newManager builds a manager without starting its health check loop
+func newManager(proxyURLs []string, checkInterval time.Duration, strict bool) (*Manager, error) {
 	m := &Manager{
 		proxies:             make([]*Proxy, 0, len(proxyURLs)),
 		healthCheckInterval: checkInterval,
@@ -43,35 +98,95 @@ func NewManager(proxyURLs []string, checkInterval time.Duration) (*Manager, erro
 			Name: "proxy_requests_total",
 			Help: "Total requests by proxy and status",
 		}, []string{"proxy", "status"}),
-		testEndpoint: "https://ticketing.colosseo.it/", // Health check endpoint
+		connReuse: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "proxy_connections_total",
+			Help: "Connections used through each proxy, by whether a warm connection was reused",
+		}, []string{"proxy", "reused"}),
+		geoBlocks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "proxy_geo_blocks_total",
+			Help: "Region-block pages served through each proxy, by the geography it was used for",
+		}, []string{"proxy", "geo"}),
+		poolChanges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "proxy_pool_changes_total",
+			Help: "Proxies added to or removed from the pool at runtime",
+		}, []string{"action"}),
+		testEndpoints:     []string{DefaultHealthEndpoint},
+		healthConcurrency: DefaultHealthConcurrency,
+		geoBonus:     DefaultGeoBonus,
+		weightJitter: DefaultWeightJitter,
+		released:     make(chan struct{}),
+		strict:       strict,
+		banPolicy:    DefaultBanPolicy,
+		distinct:     newDiversityGauges(),
 	}
 
-	for _, u := range proxyURLs {
-		parsed, err := url.Parse(u)
-		if err != nil {
-			return nil, fmt.Errorf("invalid proxy URL %s: %w", u, err)
+	for i, u := range proxyURLs {
+		if err := m.add(u, DefaultPool); err != nil {
+			if strict {
+				return nil, fmt.Errorf("invalid proxy URL #%d: %w", i, err)
+			}
+			slog.Warn("Skipping invalid proxy URL", "index", i, "err", err)
+			m.rejected = append(m.rejected, RedactSpec(u))
 		}
-
-		m.proxies = append(m.proxies, &Proxy{
-			URL:         parsed,
-			HealthScore: 1.0,
-			Geographic:  extractGeographic(parsed),
-			ASN:         extractASN(parsed),
-		})
 	}
 
-	// Start health check loop
-	go m.healthCheckLoop()
+	if len(m.proxies) == 0 && len(proxyURLs) > 0 {
+		return nil, fmt.Errorf("no valid proxy URLs (%d rejected)", len(m.rejected))
+	}
 
 	return m, nil
 }
 
-// GetProxy returns a healthy proxy with geographic preference
-func (m *Manager) GetProxy(preferredGeo string) *url.URL {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+// SetSelectionWeights configures the geographic preference multiplier
+// and the random jitter (e.g. 0.2 = +/-20%) added to selection weights
+// so usage spreads among comparable proxies
+func (m *Manager) SetSelectionWeights(geoBonus, jitter float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if geoBonus > 0 {
+		m.geoBonus = geoBonus
+	}
+	if jitter >= 0 && jitter < 1 {
+		m.weightJitter = jitter
+	}
+}
 
-	// Filter healthy, non-banned proxies
+// Rejected returns the proxy URLs skipped as malformed, with their
+// credentials masked
+func (m *Manager) Rejected() []string {
+	return append([]string(nil), m.rejected...)
+}
+
+// GetProxy returns a healthy proxy from the filter's pools with
+// geographic preference, chosen by the pool's rotation policy and
+// passing over proxies at their connection cap while others have room.
+// When the pools have no healthy proxy the filter's fallback policy
+// applies. Selection records LastUsed and rotation state, so it holds
+// the write lock rather than the read lock.
+func (m *Manager) GetProxy(preferredGeo string, filter PoolFilter) *url.URL {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.releaseExpiredBans(time.Now())
+	candidates, healthy := m.poolCandidates(filter)
+	if !healthy {
+		// Fallback: return least recently used regardless of health
+		return fallbackProxy(candidates)
+	}
+	if withRoom := m.withCapacity(candidates); len(withRoom) > 0 {
+		candidates = withRoom
+	}
+
+	if p := m.pick(candidates, preferredGeo, filter); p != nil {
+		p.LastUsed = time.Now()
+		return p.URL
+	}
+
+	return candidates[0].URL
+}
+
+// healthyCandidates returns healthy, non-banned proxies. Callers hold m.mu.
+func (m *Manager) healthyCandidates() []*Proxy {
 	candidates := make([]*Proxy, 0)
 	for _, p := range m.proxies {
 		if p.BannedUntil.After(time.Now()) {
@@ -82,115 +197,142 @@ func (m *Manager) GetProxy(preferredGeo string) *url.URL {
 		}
 		candidates = append(candidates, p)
 	}
+	return candidates
+}
 
-	if len(candidates) == 0 {
-		// Fallback: return least recently used regardless of health
-		return m.fallbackProxy()
-	}
-
+// pickWeighted selects a candidate at random, weighted by health and
+// geographic preference. Callers hold m.mu.
+func (m *Manager) pickWeighted(candidates []*Proxy, preferredGeo string) *Proxy {
 	// Weighted selection by health score and geographic preference
+	weights := make([]float64, len(candidates))
 	var totalWeight float64
-	for _, p := range candidates {
-		weight := p.HealthScore
-		if p.Geographic == preferredGeo {
-			weight *= 2.0 // Geographic preference bonus
-		}
-		totalWeight += weight
+	for i, p := range candidates {
+		weights[i] = m.selectionWeight(p, preferredGeo)
+		totalWeight += weights[i]
 	}
 
+	return weightedChoice(candidates, weights, totalWeight)
+}
+
+// weightedChoice picks a candidate at random in proportion to its weight
+func weightedChoice(candidates []*Proxy, weights []float64, totalWeight float64) *Proxy {
 	r := rand.Float64() * totalWeight
-	for _, p := range candidates {
-		weight := p.HealthScore
-		if p.Geographic == preferredGeo {
-			weight *= 2.0
-		}
-		r -= weight
+	for i, p := range candidates {
+		r -= weights[i]
 		if r <= 0 {
-			p.LastUsed = time.Now()
-			return p.URL
+			return p
 		}
 	}
 
-	return candidates[0].URL
+	if len(candidates) > 0 {
+		return candidates[0]
+	}
+	return nil
 }
 
-// ReportResult updates proxy health based on request result
-func (m *Manager) ReportResult(proxyURL *url.URL, success bool, latency time.Duration) {
+// selectionWeight scores a candidate by health, geographic preference
+// and jitter
+func (m *Manager) selectionWeight(p *Proxy, preferredGeo string) float64 {
+	weight := p.HealthScore * p.geoPenalty(preferredGeo)
+	if p.Geographic == preferredGeo && !p.geoFlagged(preferredGeo) {
+		weight *= m.geoBonus // Geographic preference bonus
+	}
+	if m.weightJitter > 0 {
+		weight *= 1 + m.weightJitter*(2*rand.Float64()-1)
+	}
+	return weight
+}
+
+// ReportResult updates proxy health based on request result. The proxy
+// is named by its ID, as returned by Acquire: the same exit URL can sit
+// behind different chains.
+func (m *Manager) ReportResult(id string, success bool, latency time.Duration) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	for _, p := range m.proxies {
-		if p.URL.String() == proxyURL.String() {
-			if success {
-				p.ConsecutiveErrors = 0
-				p.HealthScore = min(1.0, p.HealthScore*1.1+0.05)
-				m.metrics.WithLabelValues(p.URL.Host, "success").Inc()
-			} else {
-				p.ConsecutiveErrors++
-				p.HealthScore *= 0.8
-				if p.ConsecutiveErrors > 5 {
-					// Exponential ban time
-					banDuration := time.Duration(p.ConsecutiveErrors) * time.Minute
-					p.BannedUntil = time.Now().Add(banDuration)
-				}
-				m.metrics.WithLabelValues(p.URL.Host, "error").Inc()
-			}
-			break
+	p := m.byKey(id)
+	if p == nil {
+		return
+	}
+	if success {
+		p.ConsecutiveErrors = 0
+		p.HealthScore = min(1.0, p.HealthScore*1.1+0.05)
+		p.observeLatency(latency)
+		m.metrics.WithLabelValues(p.URL.Host, "success").Inc()
+	} else {
+		p.ConsecutiveErrors++
+		p.HealthScore *= 0.8
+		if ban := m.banPolicy.Duration(p.ConsecutiveErrors); ban > 0 {
+			// Capped exponential ban time with jitter
+			p.BannedUntil = time.Now().Add(ban)
 		}
+		m.metrics.WithLabelValues(p.URL.Host, "error").Inc()
 	}
 }
 
-// healthCheckLoop runs periodic health checks
+// healthCheckLoop runs periodic health checks, resolving GeoIP exits
+// up front and whenever they go stale
 func (m *Manager) healthCheckLoop() {
+	m.refreshGeoIP()
+
 	ticker := time.NewTicker(m.healthCheckInterval)
 	defer ticker.Stop()
 
 	for range ticker.C {
 		m.runHealthChecks()
+		m.refreshGeoIP()
+		m.checkDiversity()
 	}
 }
 
-// runHealthChecks tests all proxies
+// runHealthChecks tests all proxies against the health endpoints. When
+// every proxy fails at once the endpoints, not the proxies, are presumed
+// down and no result is recorded, so an endpoint that starts refusing
+// everyone can't poison the whole pool.
 func (m *Manager) runHealthChecks() {
 	var wg sync.WaitGroup
 	
-	m.mu.RLock()
+	m.mu.Lock()
+	m.releaseExpiredBans(time.Now())
 	proxies := make([]*Proxy, len(m.proxies))
 	copy(proxies, m.proxies)
-	m.mu.RUnlock()
-
-	for _, p := range proxies {
+	endpoints := m.nextEndpoints()
+	concurrency := m.healthConcurrency
+	m.mu.Unlock()
+
+	// Checks start at random offsets across half the interval, a few at
+	// a time, so the target never sees a synchronized burst from the pool
+	spread := m.healthCheckInterval / 2
+	sem := make(chan struct{}, concurrency)
+	results := make([]healthResult, len(proxies))
+	for i, p := range proxies {
 		wg.Add(1)
-		go func(proxy *Proxy) {
+		go func(i int, proxy *Proxy) {
 			defer wg.Done()
-
-			client := &http.Client{
-				Timeout: 10 * time.Second,
-				Transport: &http.Transport{
-					Proxy: http.ProxyURL(proxy.URL),
-				},
+			if spread > 0 {
+				time.Sleep(time.Duration(rand.Int63n(int64(spread))))
 			}
-
-			start := time.Now()
-			resp, err := client.Get(m.testEndpoint)
-			latency := time.Since(start)
-
-			success := err == nil && resp != nil && resp.StatusCode == 200
-			if resp != nil {
-				resp.Body.Close()
-			}
-
-			m.ReportResult(proxy.URL, success, latency)
-		}(p)
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = checkProxy(proxy, endpoints)
+		}(i, p)
 	}
 
 	wg.Wait()
+
+	if len(proxies) > 1 && allFailed(results) {
+		slog.Warn("All proxies failed their health check, treating the endpoints as down", "proxies", len(proxies), "endpoints", endpoints)
+		return
+	}
+	for i, r := range results {
+		m.ReportResult(proxies[i].key, r.success, r.latency)
+	}
 }
 
-// fallbackProxy returns least recently used proxy
-func (m *Manager) fallbackProxy() *url.URL {
+// fallbackProxy returns the least recently used of candidates
+func fallbackProxy(candidates []*Proxy) *url.URL {
 	var oldest *Proxy
-	for _, p := range m.proxies {
+	for _, p := range candidates {
 		if oldest == nil || p.LastUsed.Before(oldest.LastUsed) {
 			oldest = p
 		}
@@ -206,13 +348,28 @@ func (m *Manager) GetHealthStats() []ProxyHealth {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	now := time.Now()
 	stats := make([]ProxyHealth, len(m.proxies))
 	for i, p := range m.proxies {
 		stats[i] = ProxyHealth{
-			URL:         p.URL.String(),
-			HealthScore: p.HealthScore,
-			Geographic:  p.Geographic,
-			Banned:      p.BannedUntil.After(time.Now()),
+			URL:               p.URL.Redacted(),
+			HealthScore:       p.HealthScore,
+			Geographic:        p.Geographic,
+			ASN:               p.ASN,
+			Banned:            p.BannedUntil.After(now),
+			ConsecutiveErrors: p.ConsecutiveErrors,
+			LatencyMS:         float64(p.Latency.Microseconds()) / 1000,
+			InUse:             p.inUse,
+			Pools:             append([]string(nil), p.Pools...),
+			GeoBlocks:         copyCounts(p.geoBlocks),
+		}
+		if p.BannedUntil.After(now) {
+			until := p.BannedUntil
+			stats[i].BannedUntil = &until
+		}
+		if !p.LastUsed.IsZero() {
+			used := p.LastUsed
+			stats[i].LastUsed = &used
 		}
 	}
 	return stats
@@ -220,16 +377,81 @@ func (m *Manager) GetHealthStats() []ProxyHealth {
 
 // ProxyHealth represents proxy health statistics
 type ProxyHealth struct {
-	URL         string  `json:"url"`
-	HealthScore float64 `json:"health_score"`
-	Geographic  string  `json:"geographic"`
-	Banned      bool    `json:"banned"`
+	URL               string     `json:"url"` // credentials redacted
+	HealthScore       float64    `json:"health_score"`
+	Geographic        string     `json:"geographic"`
+	ASN               string     `json:"asn"`
+	Banned            bool       `json:"banned"`
+	BannedUntil       *time.Time `json:"banned_until,omitempty"` // set while banned
+	ConsecutiveErrors int        `json:"consecutive_errors"`
+	LatencyMS         float64    `json:"latency_ms,omitempty"` // EWMA
+	LastUsed          *time.Time `json:"last_used,omitempty"`
+	InUse             int        `json:"in_use"`
+	Pools             []string   `json:"pools"`
+	// GeoBlocks counts region blocks by the geography the proxy was used for
+	GeoBlocks map[string]int `json:"geo_blocks,omitempty"`
+}
+
+func copyCounts(m map[string]int) map[string]int {
+	if len(m) == 0 {
+		return nil
+	}
+	c := make(map[string]int, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
 }
 
 // Helper functions
+
+// parseProxyURL parses one proxy URL. Its errors never quote the input,
+// which may carry credentials.
+func parseProxyURL(raw string) (*url.URL, error) {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	switch parsed.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", parsed.Scheme)
+	}
+	if parsed.Hostname() == "" {
+		return nil, fmt.Errorf("missing proxy host")
+	}
+	return parsed, nil
+}
+
+// proxyKey normalizes a proxy URL to scheme://host:port, ignoring
+// userinfo so rotating-session credentials map to one endpoint
+func proxyKey(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	port := u.Port()
+	if port == "" {
+		port = defaultPort(scheme)
+	}
+	return scheme + "://" + net.JoinHostPort(strings.ToLower(u.Hostname()), port)
+}
+
+func defaultPort(scheme string) string {
+	switch strings.ToLower(scheme) {
+	case "https":
+		return "443"
+	case "socks5":
+		return "1080"
+	default:
+		return "80"
+	}
+}
+
+// extractGeographic guesses a proxy's country from its hostname. Managers
+// built with NewManagerWithGeoIP replace the guess once the exit resolves.
 func extractGeographic(proxyURL *url.URL) string {
-	// TODO: Implement IP geolocation lookup
-	// For now, extract from URL if contains country code
 	host := proxyURL.Hostname()
 	
 	// Common patterns
@@ -245,8 +467,8 @@ func extractGeographic(proxyURL *url.URL) string {
 	return "Unknown"
 }
 
+// extractASN can't tell a proxy's ASN from its URL; only GeoIP can
 func extractASN(proxyURL *url.URL) string {
-	// TODO: Implement ASN lookup
 	return "Unknown"
 }
 
@@ -0,0 +1,100 @@
+package proxy
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func testManager(t *testing.T, urls ...string) *Manager {
+	t.Helper()
+	m, err := newManager(urls, time.Minute, true)
+	if err != nil {
+		t.Fatalf("newManager: %v", err)
+	}
+	return m
+}
+
+// Run with -race: selection writes LastUsed and rotation state while
+// results and stats are read and written from other goroutines
+func TestManagerConcurrentSelection(t *testing.T) {
+	m := testManager(t,
+		"http://10.0.0.1:8080",
+		"http://10.0.0.2:8080",
+		"http://10.0.0.3:8080",
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				u := m.GetProxy("IT", PoolFilter{})
+				if u == nil {
+					t.Error("GetProxy returned nil")
+					return
+				}
+				m.ReportResult(proxyKey(u), (i+j)%4 != 0, 10*time.Millisecond)
+				if j%50 == 0 {
+					m.GetHealthStats()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := len(m.GetHealthStats()); got != 3 {
+		t.Errorf("tracked %d proxies, want 3", got)
+	}
+}
+
+// With one Italian proxy among several German ones, the Italian one is
+// picked more than any other but far from every time
+func TestGeoPreferenceFavorsWithoutMonopoly(t *testing.T) {
+	m := testManager(t,
+		"http://rome.proxy.it:8080",
+		"http://berlin.proxy.de:8080",
+		"http://munich.proxy.de:8080",
+		"http://hamburg.proxy.de:8080",
+		"http://cologne.proxy.de:8080",
+	)
+	m.SetSelectionWeights(DefaultGeoBonus, 0.2)
+
+	const picks = 5000
+	counts := make(map[string]int)
+	for i := 0; i < picks; i++ {
+		counts[m.GetProxy("IT", PoolFilter{}).Hostname()]++
+	}
+
+	italian := counts["rome.proxy.it"]
+	for host, n := range counts {
+		if host != "rome.proxy.it" && n >= italian {
+			t.Errorf("%s picked %d times, want fewer than the preferred proxy's %d", host, n, italian)
+		}
+	}
+	if len(counts) != 5 {
+		t.Errorf("picked %d distinct proxies, want all 5: %v", len(counts), counts)
+	}
+	// A 2x bonus gives the Italian proxy 2/6 of the weight
+	if share := float64(italian) / picks; share < 0.25 || share > 0.45 {
+		t.Errorf("preferred proxy share = %.2f, want about 0.33", share)
+	}
+}
+
+// Jitter spreads picks among proxies of equal weight
+func TestWeightJitterSpreadsComparableProxies(t *testing.T) {
+	m := testManager(t, "http://rome.proxy.it:8080", "http://milan.proxy.it:8080")
+	m.SetSelectionWeights(DefaultGeoBonus, 0.2)
+
+	const picks = 2000
+	counts := make(map[string]int)
+	for i := 0; i < picks; i++ {
+		counts[m.GetProxy("IT", PoolFilter{}).Hostname()]++
+	}
+	for _, host := range []string{"rome.proxy.it", "milan.proxy.it"} {
+		if share := float64(counts[host]) / picks; share < 0.4 || share > 0.6 {
+			t.Errorf("%s share = %.2f, want about 0.5", host, share)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+// internal/proxy/pool.go - Labeled proxy subpools and pool-aware selection
+package proxy
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+)
+
+// DefaultPool holds the proxies listed without a pool label, and is the
+// pool drawn from by callers that don't name one
+const DefaultPool = "default"
+
+// PoolFallback decides what selection does when none of a filter's
+// pools has a healthy proxy
+type PoolFallback string
+
+const (
+	// FallbackPool stays inside the pools, using the least-bad proxy
+	// regardless of health (the default)
+	FallbackPool PoolFallback = "pool"
+	// FallbackDefault borrows healthy proxies from the default pool
+	FallbackDefault PoolFallback = "default"
+	// FallbackNone selects no proxy
+	FallbackNone PoolFallback = "none"
+)
+
+// PoolFilter restricts selection to proxies carrying one of Pools
+type PoolFilter struct {
+	Pools    []string     // empty = DefaultPool
+	Fallback PoolFallback // empty = FallbackPool
+	// Session keys proxy affinity under RotationSticky, e.g. a target name
+	Session string
+}
+
+// ParsePoolFallback validates a fallback policy name; empty means FallbackPool
+func ParsePoolFallback(s string) (PoolFallback, error) {
+	switch f := PoolFallback(s); f {
+	case "":
+		return FallbackPool, nil
+	case FallbackPool, FallbackDefault, FallbackNone:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown proxy pool fallback %q", s)
+	}
+}
+
+func (f PoolFilter) pools() []string {
+	if len(f.Pools) == 0 {
+		return []string{DefaultPool}
+	}
+	return f.Pools
+}
+
+// InPool reports whether the proxy carries any of the pool labels
+func (p *Proxy) InPool(pools []string) bool {
+	for _, want := range pools {
+		for _, have := range p.Pools {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AddPool labels proxies with a pool name. URLs not yet in the manager
+// are added; a URL already present (in any pool) gains the label and
+// keeps its health record. Malformed URLs follow the strictness the
+// manager was built with.
+func (m *Manager) AddPool(name string, proxyURLs []string) error {
+	if name == "" {
+		return fmt.Errorf("proxy pool name is required")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, raw := range proxyURLs {
+		if err := m.add(raw, name); err != nil {
+			if m.strict {
+				return fmt.Errorf("invalid proxy URL #%d in pool %q: %w", i, name, err)
+			}
+			slog.Warn("Skipping invalid proxy URL", "index", i, "pool", name, "err", err)
+			m.rejected = append(m.rejected, RedactSpec(raw))
+		}
+	}
+	return nil
+}
+
+// add parses a proxy spec and adds it to pool, merging with an existing
+// entry for the same endpoint. Callers hold m.mu.
+func (m *Manager) add(raw, pool string) error {
+	parsed, chain, err := parseProxySpec(raw)
+	if err != nil {
+		return err
+	}
+
+	// The same endpoint listed twice must share one health record
+	key := specKey(parsed, chain)
+	for _, p := range m.proxies {
+		if p.key == key {
+			if !p.InPool([]string{pool}) {
+				p.Pools = append(p.Pools, pool)
+			} else {
+				slog.Info("Collapsed duplicate proxy", "proxy", key, "pool", pool)
+			}
+			return nil
+		}
+	}
+
+	m.proxies = append(m.proxies, &Proxy{
+		URL:         parsed,
+		HealthScore: 1.0,
+		Geographic:  extractGeographic(parsed),
+		ASN:         extractASN(parsed),
+		Chain:       chain,
+		Pools:       []string{pool},
+		key:         key,
+	})
+	return nil
+}
+
+// specKey normalizes a proxy and the hops before it, for deduplication
+func specKey(exit *url.URL, chain []*url.URL) string {
+	key := proxyKey(exit)
+	for j := len(chain) - 1; j >= 0; j-- {
+		key = proxyKey(chain[j]) + chainSeparator + key
+	}
+	return key
+}
+
+// AddProxy adds a proxy to the default pool at runtime. It takes part in
+// selection right away and in health checks from the next round. A proxy
+// already in the manager is not added twice.
+func (m *Manager) AddProxy(rawURL string) error {
+	parsed, chain, err := parseProxySpec(rawURL)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p := m.byKey(specKey(parsed, chain)); p != nil {
+		return fmt.Errorf("proxy %s is already in the pool", p.URL.Redacted())
+	}
+	if err := m.add(rawURL, DefaultPool); err != nil {
+		return err
+	}
+	m.poolChanges.WithLabelValues("add").Inc()
+	slog.Info("Added proxy", "proxy", parsed.Redacted())
+	return nil
+}
+
+// RemoveProxy retires a proxy from every pool at runtime. It is no longer
+// selected or health-checked; connections already acquired through it
+// are unaffected, and releasing them is a no-op.
+func (m *Manager) RemoveProxy(rawURL string) error {
+	parsed, chain, err := parseProxySpec(rawURL)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p := m.byKey(specKey(parsed, chain))
+	if p == nil {
+		return fmt.Errorf("proxy %s is not in the pool", parsed.Redacted())
+	}
+
+	kept := m.proxies[:0]
+	for _, q := range m.proxies {
+		if q != p {
+			kept = append(kept, q)
+		}
+	}
+	for i := len(kept); i < len(m.proxies); i++ {
+		m.proxies[i] = nil
+	}
+	m.proxies = kept
+
+	// Drop everything else still pointing at it
+	for key, q := range m.affinity {
+		if q == p {
+			delete(m.affinity, key)
+		}
+	}
+	for key, q := range m.lastPicked {
+		if q == p {
+			delete(m.lastPicked, key)
+		}
+	}
+	if m.warm != nil {
+		m.warm.forget(p)
+	}
+	p.liveMu.Lock()
+	if p.live != nil {
+		p.live.CloseIdleConnections()
+	}
+	p.liveMu.Unlock()
+	if m.geoIP != nil {
+		m.geoIP.forget(p.key)
+	}
+
+	m.poolChanges.WithLabelValues("remove").Inc()
+	slog.Info("Removed proxy", "proxy", p.URL.Redacted())
+	return nil
+}
+
+// byKey finds the tracked proxy for a normalized spec. Callers hold m.mu.
+func (m *Manager) byKey(key string) *Proxy {
+	for _, p := range m.proxies {
+		if p.key == key {
+			return p
+		}
+	}
+	return nil
+}
+
+// poolCandidates returns the proxies filter may select from: the healthy
+// ones in its pools, else whatever its fallback allows. healthy is false
+// when the result is a least-bad fallback. Callers hold m.mu.
+func (m *Manager) poolCandidates(filter PoolFilter) (candidates []*Proxy, healthy bool) {
+	healthyProxies := m.healthyCandidates()
+	if c := inPools(healthyProxies, filter.pools()); len(c) > 0 {
+		return c, true
+	}
+
+	switch filter.Fallback {
+	case FallbackNone:
+		return nil, false
+	case FallbackDefault:
+		if c := inPools(healthyProxies, []string{DefaultPool}); len(c) > 0 {
+			return c, true
+		}
+	}
+	return inPools(m.proxies, filter.pools()), false
+}
+
+// inPools filters proxies to those carrying any of pools
+func inPools(proxies []*Proxy, pools []string) []*Proxy {
+	result := make([]*Proxy, 0, len(proxies))
+	for _, p := range proxies {
+		if p.InPool(pools) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,100 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDuplicateProxiesCollapse(t *testing.T) {
+	m := testManager(t,
+		"http://user-session1:pw@10.0.0.1:8080",
+		"http://10.0.0.2:8080",
+		"HTTP://user-session2:pw@10.0.0.1:8080", // same endpoint, rotated userinfo
+		"http://10.0.0.2",                       // another endpoint: port 80
+	)
+	if got := len(m.proxies); got != 3 {
+		t.Fatalf("tracked %d proxies, want 3", got)
+	}
+
+	// One health record: a ban through either spelling bans the endpoint
+	for i := 0; i <= banThreshold; i++ {
+		m.ReportResult(m.proxies[0].key, false, 0)
+	}
+	for _, p := range m.healthyCandidates() {
+		if p.key == "http://10.0.0.1:8080" {
+			t.Error("banned endpoint is still a candidate")
+		}
+	}
+
+	if err := m.AddProxy("http://other:pw@10.0.0.1:8080"); err == nil {
+		t.Error("AddProxy of a tracked endpoint succeeded, want an error")
+	}
+	if got := len(m.proxies); got != 3 {
+		t.Errorf("tracked %d proxies after AddProxy, want 3", got)
+	}
+}
+
+func TestDuplicateAcrossPoolsSharesOneEntry(t *testing.T) {
+	m := testManager(t, "http://10.0.0.1:8080")
+	if err := m.AddPool("residential", []string{"http://10.0.0.1:8080", "http://10.0.0.1:8080"}); err != nil {
+		t.Fatalf("AddPool: %v", err)
+	}
+	if got := len(m.proxies); got != 1 {
+		t.Fatalf("tracked %d proxies, want 1", got)
+	}
+	if pools := m.proxies[0].Pools; len(pools) != 2 || pools[0] != DefaultPool || pools[1] != "residential" {
+		t.Errorf("Pools = %v, want [%s residential]", pools, DefaultPool)
+	}
+}
+
+// Two chains to one exit are separate proxies: slots, health and
+// transports follow the ID, not the exit URL they share
+func TestSameExitBehindDifferentChains(t *testing.T) {
+	m := testManager(t,
+		"http://10.0.0.1:3128>http://user:pw@exit.proxy.it:8080",
+		"http://10.0.0.2:3128>http://user:pw@exit.proxy.it:8080",
+	)
+	if got := len(m.proxies); got != 2 {
+		t.Fatalf("tracked %d proxies, want 2", got)
+	}
+	first, second := m.proxies[0], m.proxies[1]
+	m.SetMaxConnsPerProxy(1)
+
+	ctx := context.Background()
+	a, err := m.Acquire(ctx, "", PoolFilter{})
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	b, err := m.Acquire(ctx, "", PoolFilter{})
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if a == b {
+		t.Fatalf("Acquire returned %q twice, want both chains", a)
+	}
+
+	m.Release(second.key)
+	if first.inUse != 1 || second.inUse != 0 {
+		t.Errorf("inUse after releasing the second chain = %d, %d; want 1, 0", first.inUse, second.inUse)
+	}
+	m.Release(first.key)
+	if first.inUse != 0 {
+		t.Errorf("inUse after releasing the first chain = %d, want 0", first.inUse)
+	}
+
+	for i := 0; i <= banThreshold; i++ {
+		m.ReportResult(second.key, false, 0)
+	}
+	if first.ConsecutiveErrors != 0 || second.ConsecutiveErrors == 0 {
+		t.Errorf("ConsecutiveErrors = %d, %d; want only the second chain's counted", first.ConsecutiveErrors, second.ConsecutiveErrors)
+	}
+	if candidates := m.healthyCandidates(); len(candidates) != 1 || candidates[0] != first {
+		t.Errorf("healthy candidates = %d, want only the first chain", len(candidates))
+	}
+
+	rtA := m.RoundTripper(first.key).(*reuseTracker)
+	rtB := m.RoundTripper(second.key).(*reuseTracker)
+	if rtA.base == rtB.base {
+		t.Error("both chains share one transport, want one per chain")
+	}
+}
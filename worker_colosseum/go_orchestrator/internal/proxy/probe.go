@@ -0,0 +1,154 @@
+// internal/proxy/probe.go - One-off checks of candidate proxies
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Probe defaults
+const (
+	DefaultProbeConcurrency = 8
+	DefaultProbeTimeout     = 30 * time.Second
+	DefaultIPEchoURL        = "https://api.ipify.org"
+	probeRequestTimeout     = 10 * time.Second
+)
+
+// ProbeOptions bounds a proxy test run
+type ProbeOptions struct {
+	Target      string        // URL requested through each proxy
+	IPEchoURL   string        // returns the caller's IP as plain text
+	Concurrency int           // proxies tested at once
+	Timeout     time.Duration // for the whole run
+}
+
+// ProbeResult is the outcome of testing one proxy spec
+type ProbeResult struct {
+	Proxy     string  `json:"proxy"` // credentials redacted
+	Success   bool    `json:"success"`
+	Status    int     `json:"status,omitempty"`
+	LatencyMS float64 `json:"latency_ms,omitempty"`
+	ExitIP    string  `json:"exit_ip,omitempty"`
+	Geo       string  `json:"geo"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Probe tests each proxy spec against the target the way the pool
+// would use it, without adding any of them to a Manager. Specs are
+// validated like configured proxies; results keep the input order.
+func Probe(ctx context.Context, specs []string, opts ProbeOptions) []ProbeResult {
+	if opts.IPEchoURL == "" {
+		opts.IPEchoURL = DefaultIPEchoURL
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultProbeConcurrency
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultProbeTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	results := make([]ProbeResult, len(specs))
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	for i, spec := range specs {
+		wg.Add(1)
+		go func(i int, spec string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				results[i] = probe(ctx, spec, opts)
+			case <-ctx.Done():
+				results[i] = ProbeResult{Proxy: RedactSpec(spec), Geo: "Unknown", Error: ctx.Err().Error()}
+			}
+		}(i, spec)
+	}
+	wg.Wait()
+	return results
+}
+
+// probe validates and tests a single proxy spec
+func probe(ctx context.Context, spec string, opts ProbeOptions) ProbeResult {
+	result := ProbeResult{Proxy: RedactSpec(spec), Geo: "Unknown"}
+	exit, chain, err := parseProxySpec(spec)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	p := &Proxy{URL: exit, Chain: chain}
+	result.Geo = extractGeographic(exit)
+
+	transport := p.Transport()
+	defer transport.CloseIdleConnections()
+	client := &http.Client{Timeout: probeRequestTimeout, Transport: transport}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, opts.Target, nil)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	result.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	resp.Body.Close()
+	result.Status = resp.StatusCode
+	result.Success = resp.StatusCode == http.StatusOK
+	if !result.Success {
+		result.Error = fmt.Sprintf("target returned %s", resp.Status)
+	}
+
+	// The exit IP is informational; failing to learn it doesn't fail the proxy
+	if ip, err := exitIP(ctx, client, opts.IPEchoURL); err == nil {
+		result.ExitIP = ip
+	}
+	return result
+}
+
+// exitIP asks an IP echo service which address the proxy exits from
+func exitIP(ctx context.Context, client *http.Client, echoURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, echoURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return "", err
+	}
+	ip := strings.TrimSpace(string(body))
+	if net.ParseIP(ip) == nil {
+		return "", fmt.Errorf("IP echo returned %q", ip)
+	}
+	return ip, nil
+}
+
+// RedactSpec masks credentials in every hop of a proxy spec, replacing
+// unparsable hops with "invalid"
+func RedactSpec(spec string) string {
+	parts := strings.Split(spec, chainSeparator)
+	for i, part := range parts {
+		if u, err := parseProxyURL(strings.TrimSpace(part)); err == nil {
+			parts[i] = u.Redacted()
+		} else {
+			parts[i] = "invalid"
+		}
+	}
+	return strings.Join(parts, chainSeparator)
+}
@@ -0,0 +1,161 @@
+// internal/proxy/rotation.go - Per-pool rotation policies
+package proxy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RotationPolicy decides which of a pool's candidates serves a request
+type RotationPolicy string
+
+const (
+	// RotationWeighted picks at random, weighted by health and geography (default)
+	RotationWeighted RotationPolicy = "weighted"
+	// RotationLatencyWeighted weights like RotationWeighted, scaled down
+	// for proxies slower than their peers
+	RotationLatencyWeighted RotationPolicy = "latency_weighted"
+	// RotationLRU picks the least recently used proxy, spreading load evenly
+	RotationLRU RotationPolicy = "lru"
+	// RotationRoundRobin cycles through the candidates in configured order
+	RotationRoundRobin RotationPolicy = "round_robin"
+	// RotationSticky keeps each session on one proxy while it stays a
+	// candidate, for targets whose cookies are tied to an exit IP
+	RotationSticky RotationPolicy = "sticky"
+)
+
+// ParseRotationPolicy validates a policy name; empty means RotationWeighted
+// and "least_recently_used" is accepted for RotationLRU
+func ParseRotationPolicy(s string) (RotationPolicy, error) {
+	switch p := RotationPolicy(strings.ToLower(s)); p {
+	case "":
+		return RotationWeighted, nil
+	case "least_recently_used":
+		return RotationLRU, nil
+	case RotationWeighted, RotationLatencyWeighted, RotationLRU, RotationRoundRobin, RotationSticky:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown rotation policy %q", s)
+	}
+}
+
+// SetRotationPolicy sets the policy for pools without their own
+func (m *Manager) SetRotationPolicy(policy RotationPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rotation = policy
+}
+
+// SetPoolPolicy assigns a rotation policy to one pool
+func (m *Manager) SetPoolPolicy(pool string, policy RotationPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.poolPolicies == nil {
+		m.poolPolicies = make(map[string]RotationPolicy)
+	}
+	m.poolPolicies[pool] = policy
+}
+
+// policyFor returns the policy of the filter's first pool. Callers hold m.mu.
+func (m *Manager) policyFor(filter PoolFilter) RotationPolicy {
+	if p, ok := m.poolPolicies[filter.pools()[0]]; ok {
+		return p
+	}
+	if m.rotation == "" {
+		return RotationWeighted
+	}
+	return m.rotation
+}
+
+// pick selects among candidates under the filter's pool policy. Callers
+// hold m.mu for writing, as sticky selection records the affinity.
+func (m *Manager) pick(candidates []*Proxy, preferredGeo string, filter PoolFilter) *Proxy {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	switch m.policyFor(filter) {
+	case RotationLatencyWeighted:
+		return m.pickLatencyWeighted(candidates, preferredGeo)
+
+	case RotationLRU:
+		return leastRecentlyUsed(preferGeo(candidates, preferredGeo))
+
+	case RotationRoundRobin:
+		key := strings.Join(filter.pools(), ",")
+		p := m.nextInOrder(preferGeo(candidates, preferredGeo), m.lastPicked[key])
+		if m.lastPicked == nil {
+			m.lastPicked = make(map[string]*Proxy)
+		}
+		m.lastPicked[key] = p
+		return p
+
+	case RotationSticky:
+		key := strings.Join(filter.pools(), ",") + "|" + filter.Session
+		if p, ok := m.affinity[key]; ok {
+			for _, c := range candidates {
+				if c == p {
+					return p
+				}
+			}
+		}
+		p := m.pickWeighted(candidates, preferredGeo)
+		if m.affinity == nil {
+			m.affinity = make(map[string]*Proxy)
+		}
+		m.affinity[key] = p
+		return p
+
+	default:
+		return m.pickWeighted(candidates, preferredGeo)
+	}
+}
+
+// preferGeo narrows candidates to those exiting in geo, if any do
+func preferGeo(candidates []*Proxy, geo string) []*Proxy {
+	var matched []*Proxy
+	for _, p := range candidates {
+		if p.Geographic == geo && !p.geoFlagged(geo) {
+			matched = append(matched, p)
+		}
+	}
+	if len(matched) == 0 {
+		return candidates
+	}
+	return matched
+}
+
+// leastRecentlyUsed returns the candidate idle the longest
+func leastRecentlyUsed(candidates []*Proxy) *Proxy {
+	var oldest *Proxy
+	for _, p := range candidates {
+		if oldest == nil || p.LastUsed.Before(oldest.LastUsed) {
+			oldest = p
+		}
+	}
+	return oldest
+}
+
+// nextInOrder returns the first candidate after last in the manager's
+// proxy order, wrapping around, so the cycle holds steady as proxies
+// drop out of and rejoin the candidates. Callers hold m.mu.
+func (m *Manager) nextInOrder(candidates []*Proxy, last *Proxy) *Proxy {
+	eligible := make(map[*Proxy]bool, len(candidates))
+	for _, p := range candidates {
+		eligible[p] = true
+	}
+
+	start := 0
+	for i, p := range m.proxies {
+		if p == last {
+			start = i + 1
+			break
+		}
+	}
+	for i := range m.proxies {
+		if p := m.proxies[(start+i)%len(m.proxies)]; eligible[p] {
+			return p
+		}
+	}
+	return candidates[0]
+}
@@ -0,0 +1,139 @@
+package proxy
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func poolManager(t *testing.T) *Manager {
+	t.Helper()
+	m := testManager(t)
+	if err := m.AddPool("json", []string{"http://10.0.1.1:8080", "http://10.0.1.2:8080", "http://10.0.1.3:8080"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AddPool("session", []string{"http://10.0.2.1:8080", "http://10.0.2.2:8080", "http://10.0.2.3:8080"}); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestPoolPoliciesSelectIndependently(t *testing.T) {
+	m := poolManager(t)
+	m.SetPoolPolicy("json", RotationLRU)
+	m.SetPoolPolicy("session", RotationSticky)
+
+	jsonPool := PoolFilter{Pools: []string{"json"}, Fallback: FallbackNone}
+	sessionA := PoolFilter{Pools: []string{"session"}, Fallback: FallbackNone, Session: "tickets-a"}
+	sessionB := PoolFilter{Pools: []string{"session"}, Fallback: FallbackNone, Session: "tickets-b"}
+
+	var jsonPicks []string
+	stickyA := m.GetProxy("", sessionA).Host
+	stickyB := m.GetProxy("", sessionB).Host
+	for i := 0; i < 6; i++ {
+		jsonPicks = append(jsonPicks, m.GetProxy("", jsonPool).Host)
+		// Interleaved session picks neither move nor are moved by LRU
+		if got := m.GetProxy("", sessionA).Host; got != stickyA {
+			t.Fatalf("session a moved from %s to %s", stickyA, got)
+		}
+		if got := m.GetProxy("", sessionB).Host; got != stickyB {
+			t.Fatalf("session b moved from %s to %s", stickyB, got)
+		}
+	}
+
+	want := []string{"10.0.1.1:8080", "10.0.1.2:8080", "10.0.1.3:8080", "10.0.1.1:8080", "10.0.1.2:8080", "10.0.1.3:8080"}
+	for i := range want {
+		if jsonPicks[i] != want[i] {
+			t.Fatalf("json pool picks = %v, want LRU order %v", jsonPicks, want)
+		}
+	}
+	for _, host := range []string{stickyA, stickyB} {
+		if !strings.HasPrefix(host, "10.0.2.") {
+			t.Errorf("session pick %s is outside the session pool", host)
+		}
+	}
+}
+
+func TestPoolWithoutPolicyUsesManagerPolicy(t *testing.T) {
+	m := poolManager(t)
+	m.SetRotationPolicy(RotationRoundRobin)
+	m.SetPoolPolicy("session", RotationSticky)
+
+	filter := PoolFilter{Pools: []string{"json"}, Fallback: FallbackNone}
+	var picks []string
+	for i := 0; i < 4; i++ {
+		picks = append(picks, m.GetProxy("", filter).Host)
+	}
+	want := []string{"10.0.1.1:8080", "10.0.1.2:8080", "10.0.1.3:8080", "10.0.1.1:8080"}
+	for i := range want {
+		if picks[i] != want[i] {
+			t.Fatalf("json pool picks = %v, want round robin %v", picks, want)
+		}
+	}
+}
+
+var rotationURLs = []string{"http://10.0.0.1:8080", "http://10.0.0.2:8080", "http://10.0.0.3:8080"}
+
+func TestRoundRobinCyclesInOrder(t *testing.T) {
+	m := testManager(t, rotationURLs...)
+	m.SetRotationPolicy(RotationRoundRobin)
+
+	var picks []string
+	for i := 0; i < 7; i++ {
+		picks = append(picks, m.GetProxy("", PoolFilter{}).Host)
+	}
+	want := []string{"10.0.0.1:8080", "10.0.0.2:8080", "10.0.0.3:8080", "10.0.0.1:8080", "10.0.0.2:8080", "10.0.0.3:8080", "10.0.0.1:8080"}
+	for i := range want {
+		if picks[i] != want[i] {
+			t.Fatalf("picks = %v, want %v", picks, want)
+		}
+	}
+
+	// An unhealthy proxy is skipped without disturbing the cycle
+	m.proxies[2].HealthScore = 0
+	for i, want := range []string{"10.0.0.2:8080", "10.0.0.1:8080", "10.0.0.2:8080"} {
+		if got := m.GetProxy("", PoolFilter{}).Host; got != want {
+			t.Errorf("pick %d with 10.0.0.3 down = %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestLeastRecentlyUsedPicksLongestIdle(t *testing.T) {
+	m := testManager(t, rotationURLs...)
+	m.SetRotationPolicy(RotationLRU)
+	now := time.Now()
+	m.proxies[0].LastUsed = now.Add(-time.Minute)
+	m.proxies[1].LastUsed = now.Add(-time.Hour)
+	m.proxies[2].LastUsed = now.Add(-10 * time.Minute)
+
+	var picks []string
+	for i := 0; i < 6; i++ {
+		picks = append(picks, m.GetProxy("", PoolFilter{}).Host)
+	}
+	want := []string{"10.0.0.2:8080", "10.0.0.3:8080", "10.0.0.1:8080", "10.0.0.2:8080", "10.0.0.3:8080", "10.0.0.1:8080"}
+	for i := range want {
+		if picks[i] != want[i] {
+			t.Fatalf("picks = %v, want %v", picks, want)
+		}
+	}
+}
+
+func TestWeightedFollowsHealth(t *testing.T) {
+	m := testManager(t, rotationURLs...)
+	m.SetRotationPolicy(RotationWeighted)
+	m.proxies[0].HealthScore = 1.0
+	m.proxies[1].HealthScore = 0.5
+	m.proxies[2].HealthScore = 0.5
+
+	const picks = 6000
+	counts := make(map[string]int)
+	for i := 0; i < picks; i++ {
+		counts[m.GetProxy("", PoolFilter{}).Host]++
+	}
+	// Weights 1 : 0.5 : 0.5
+	for host, want := range map[string]float64{"10.0.0.1:8080": 0.5, "10.0.0.2:8080": 0.25, "10.0.0.3:8080": 0.25} {
+		if share := float64(counts[host]) / picks; share < want-0.05 || share > want+0.05 {
+			t.Errorf("%s share = %.3f, want about %.2f", host, share, want)
+		}
+	}
+}
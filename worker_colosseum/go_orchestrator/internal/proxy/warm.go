@@ -0,0 +1,195 @@
+// internal/proxy/warm.go - Warm keep-alive connection pools per proxy
+package proxy
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultWarmInterval is the mean delay between keep-alive rounds
+const DefaultWarmInterval = 45 * time.Second
+
+// WarmPoolOptions keeps idle keep-alive connections to the target open
+// through every healthy proxy, so the first poll after a drop skips the
+// TCP and TLS handshakes
+type WarmPoolOptions struct {
+	Size     int           // idle connections kept per proxy (0 disables)
+	Target   string        // URL whose origin is kept warm
+	Interval time.Duration // mean delay between keep-alive rounds
+}
+
+// warmPool holds the tuned per-proxy transports and their keep-alive loop
+type warmPool struct {
+	opts       WarmPoolOptions
+	origin     string
+	mu         sync.Mutex
+	transports map[*Proxy]*http.Transport
+}
+
+// SetWarmPool enables warm connection pools. Transports returned by
+// RoundTripper then keep opts.Size idle connections per proxy, refreshed
+// by jittered HEAD requests to the target's origin.
+func (m *Manager) SetWarmPool(opts WarmPoolOptions) error {
+	if opts.Size <= 0 {
+		return nil
+	}
+	target, err := url.Parse(opts.Target)
+	if err != nil || target.Host == "" {
+		return fmt.Errorf("warm pool target %q: invalid URL", opts.Target)
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultWarmInterval
+	}
+
+	m.mu.Lock()
+	m.warm = &warmPool{
+		opts:       opts,
+		origin:     target.Scheme + "://" + target.Host + "/",
+		transports: make(map[*Proxy]*http.Transport),
+	}
+	m.mu.Unlock()
+
+	m.warmStart.Do(func() { go m.warmLoop() })
+	return nil
+}
+
+// RoundTripper returns a transport through the proxy with the given ID
+// that reuses its warm connections and records whether each request got
+// one. It returns nil for an unknown proxy.
+func (m *Manager) RoundTripper(id string) http.RoundTripper {
+	m.mu.RLock()
+	p := m.byKey(id)
+	warm := m.warm
+	m.mu.RUnlock()
+	if p == nil {
+		return nil
+	}
+
+	var base *http.Transport
+	if warm != nil {
+		base = warm.transport(p)
+	} else {
+		base = p.liveTransport()
+	}
+	return &reuseTracker{base: base, proxy: p.URL.Host, reuse: m.connReuse}
+}
+
+// liveTransport returns the proxy's shared transport, built on first
+// use, so live requests reuse its connections instead of dialing (and
+// tunneling through the whole chain) every time
+func (p *Proxy) liveTransport() *http.Transport {
+	p.liveMu.Lock()
+	defer p.liveMu.Unlock()
+	if p.live == nil {
+		p.live = p.Transport()
+	}
+	return p.live
+}
+
+// transport returns the proxy's long-lived transport, tuned to keep the
+// pool's connections idle between keep-alive rounds
+func (w *warmPool) transport(p *Proxy) *http.Transport {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if t, ok := w.transports[p]; ok {
+		return t
+	}
+	t := p.Transport()
+	t.MaxIdleConnsPerHost = w.opts.Size
+	t.MaxIdleConns = w.opts.Size * 2
+	t.IdleConnTimeout = 3 * w.opts.Interval
+	t.TLSHandshakeTimeout = 10 * time.Second
+	w.transports[p] = t
+	return t
+}
+
+// forget closes a removed proxy's idle connections and drops its transport
+func (w *warmPool) forget(p *Proxy) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if t, ok := w.transports[p]; ok {
+		t.CloseIdleConnections()
+		delete(w.transports, p)
+	}
+}
+
+// warmLoop refreshes the pools at a jittered interval. Each proxy is
+// touched at a random offset with HEAD requests to the site root, not
+// the monitored page, so keep-alives don't read as extra polls.
+func (m *Manager) warmLoop() {
+	for {
+		m.mu.RLock()
+		interval := m.warm.opts.Interval
+		m.mu.RUnlock()
+
+		jitter := 0.7 + 0.6*rand.Float64()
+		time.Sleep(time.Duration(float64(interval) * jitter))
+
+		m.mu.RLock()
+		w := m.warm
+		proxies := m.healthyCandidates()
+		m.mu.RUnlock()
+
+		for _, p := range proxies {
+			go func(p *Proxy) {
+				time.Sleep(time.Duration(rand.Int63n(int64(w.opts.Interval / 4))))
+				// Untracked, so keep-alives don't skew the reuse rate
+				client := &http.Client{
+					Timeout:   15 * time.Second,
+					Transport: w.transport(p),
+				}
+
+				// Concurrent requests open connections up to the pool size
+				var wg sync.WaitGroup
+				for i := 0; i < w.opts.Size; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						req, err := http.NewRequest(http.MethodHead, w.origin, nil)
+						if err != nil {
+							return
+						}
+						req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+						if resp, err := client.Do(req); err == nil {
+							resp.Body.Close()
+						}
+					}()
+				}
+				wg.Wait()
+			}(p)
+		}
+	}
+}
+
+// reuseTracker counts whether each request rode an existing connection
+type reuseTracker struct {
+	base  http.RoundTripper
+	proxy string
+	reuse *prometheus.CounterVec
+}
+
+func (t *reuseTracker) RoundTrip(req *http.Request) (*http.Response, error) {
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.reuse.WithLabelValues(t.proxy, strconv.FormatBool(info.Reused)).Inc()
+		},
+	}
+	ctx := httptrace.WithClientTrace(req.Context(), trace)
+	return t.base.RoundTrip(req.WithContext(ctx))
+}
+
+// Metrics returns the manager's collectors for registration
+func (m *Manager) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.metrics, m.connReuse, m.geoBlocks, m.poolChanges,
+		m.distinct.asns, m.distinct.geos,
+	}
+}